@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+func printAccountsUsage() {
+	fmt.Println("Usage: tiny-client accounts list")
+	fmt.Println("       tiny-client accounts create <name>")
+	fmt.Println("       tiny-client accounts switch <name>")
+}
+
+// accountsCommand manages the accounts derived from the mnemonic. It only
+// touches the accounts file, so it runs without connecting to the SDK.
+func accountsCommand(cfg *config.Config, args []string) {
+	if len(args) < 1 || (args[0] != "list" && len(args) < 2) {
+		printAccountsUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		listAccounts(cfg)
+	case "create":
+		account, err := wallet.CreateAccount(cfg.BreezWorkingDir, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create account: %v", err)
+		}
+		fmt.Printf("Created account %s (account number %d)\n", account.Name, *account.Number)
+		fmt.Printf("Run 'tiny-client accounts switch %s' to use it\n", account.Name)
+	case "switch":
+		if err := wallet.SwitchAccount(cfg.BreezWorkingDir, args[1]); err != nil {
+			log.Fatalf("Failed to switch account: %v", err)
+		}
+		fmt.Printf("Switched to account %s\n", args[1])
+	default:
+		printAccountsUsage()
+	}
+}
+
+// accountDir returns the data directory of the current account, for commands
+// that use its local files without connecting to the SDK
+func accountDir(cfg *config.Config) string {
+	dir, err := wallet.AccountDataDir(cfg.BreezWorkingDir)
+	if err != nil {
+		log.Fatalf("Failed to find the current account: %v", err)
+	}
+	return dir
+}
+
+func listAccounts(cfg *config.Config) {
+	accounts, err := wallet.ListAccounts(cfg.BreezWorkingDir)
+	if err != nil {
+		log.Fatalf("Failed to list accounts: %v", err)
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "\tNAME\tNUMBER\tCREATED")
+	fmt.Fprintln(tabWriter, "\t----\t------\t-------")
+	for _, account := range accounts {
+		current, number, created := "", "-", "-"
+		if account.Current {
+			current = "*"
+		}
+		if account.Number != nil {
+			number = fmt.Sprintf("%d", *account.Number)
+			created = account.CreatedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n", current, account.Name, number, created)
+	}
+	tabWriter.Flush()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// autopilot prints channel management suggestions from the channels and the
+// payment history. Nothing is changed.
+func autopilot(ctx context.Context, w *wallet.Wallet) {
+	channels, err := w.ListChannels(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list channels: %v", err)
+	}
+	transactions, err := w.GetAllTransactions(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get payment history: %v", err)
+	}
+
+	suggestions, err := wallet.GenerateAutopilotSuggestions(ctx, channels, transactions)
+	if err != nil {
+		log.Fatalf("Failed to generate suggestions: %v", err)
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("No suggestions: the channels fit the last 30 days of payments")
+		return
+	}
+
+	fmt.Println("Autopilot Suggestions:")
+	for _, suggestion := range suggestions {
+		fmt.Printf("- %s\n", suggestion)
+	}
+	fmt.Println("\nThese are suggestions only, nothing was changed.")
+}
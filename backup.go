@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// backupCommand works with wallet backup files
+func backupCommand(args []string) {
+	if len(args) < 1 {
+		printBackupUsage()
+		return
+	}
+
+	switch args[0] {
+	case "verify":
+		backupVerify(args[1:])
+	default:
+		fmt.Printf("Unknown backup command: %s\n\n", args[0])
+		printBackupUsage()
+	}
+}
+
+func printBackupUsage() {
+	fmt.Println("Usage: tiny-client backup <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  verify <backup_file> [--passphrase-file F]  Check a backup can be restored (not supported yet)")
+}
+
+// backupVerify checks a backup file without restoring it
+func backupVerify(args []string) {
+	fs := flag.NewFlagSet("backup verify", flag.ExitOnError)
+	passphraseFile := fs.String("passphrase-file", "", "Read the backup passphrase from this file")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client backup verify <backup_file> [--passphrase-file pass.txt]")
+		return
+	}
+
+	passphrase := ""
+	if *passphraseFile != "" {
+		data, err := os.ReadFile(*passphraseFile)
+		if err != nil {
+			log.Fatalf("Failed to read passphrase file: %v", err)
+		}
+		passphrase = strings.TrimSpace(string(data))
+	}
+
+	if err := wallet.VerifyBackup(args[0], passphrase); err != nil {
+		log.Fatalf("Failed to verify backup: %v", err)
+	}
+	fmt.Println("Backup OK")
+}
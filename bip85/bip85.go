@@ -0,0 +1,77 @@
+package bip85
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// bip39Application is the BIP85 application number for BIP39 mnemonics
+	bip39Application = 39
+	// englishLanguage is the BIP85 language code for the English word list
+	englishLanguage = 0
+)
+
+// entropyHMACKey is the HMAC key BIP85 uses to turn a derived key into entropy
+var entropyHMACKey = []byte("bip-entropy-from-k")
+
+// DeriveMnemonic derives the English BIP39 child mnemonic with the given number
+// of words at index from the master mnemonic, using the BIP85 path
+// m/83696968'/39'/0'/<words>'/<index>'
+func DeriveMnemonic(masterMnemonic string, words, index uint32) (string, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(masterMnemonic, "")
+	if err != nil {
+		return "", fmt.Errorf("invalid master mnemonic: %w", err)
+	}
+
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to create master key: %w", err)
+	}
+
+	return DeriveMnemonicFromKey(master, words, index)
+}
+
+// DeriveMnemonicFromKey derives a BIP39 child mnemonic from a BIP32 master key
+func DeriveMnemonicFromKey(master *hdkeychain.ExtendedKey, words, index uint32) (string, error) {
+	var entropyLen int
+	switch words {
+	case 12:
+		entropyLen = 16
+	case 18:
+		entropyLen = 24
+	case 24:
+		entropyLen = 32
+	default:
+		return "", fmt.Errorf("unsupported word count %d: must be 12, 18 or 24", words)
+	}
+	if index >= hdkeychain.HardenedKeyStart {
+		return "", fmt.Errorf("index %d is out of range", index)
+	}
+
+	path := []uint32{83696968, bip39Application, englishLanguage, words, index}
+	key := master
+	for _, child := range path {
+		var err error
+		key, err = key.Derive(hdkeychain.HardenedKeyStart + child)
+		if err != nil {
+			return "", fmt.Errorf("failed to derive child key: %w", err)
+		}
+	}
+
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get derived private key: %w", err)
+	}
+
+	mac := hmac.New(sha512.New, entropyHMACKey)
+	mac.Write(privKey.Serialize())
+	entropy := mac.Sum(nil)[:entropyLen]
+
+	return bip39.NewMnemonic(entropy)
+}
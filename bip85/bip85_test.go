@@ -0,0 +1,31 @@
+package bip85
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// TestDeriveMnemonicFromKey checks the BIP39 test vector of the BIP85 spec
+func TestDeriveMnemonicFromKey(t *testing.T) {
+	master, err := hdkeychain.NewKeyFromString("xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8kmbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb")
+	if err != nil {
+		t.Fatalf("NewKeyFromString() error = %v", err)
+	}
+
+	got, err := DeriveMnemonicFromKey(master, 12, 0)
+	if err != nil {
+		t.Fatalf("DeriveMnemonicFromKey() error = %v", err)
+	}
+	want := "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose"
+	if got != want {
+		t.Errorf("DeriveMnemonicFromKey() = %q, want %q", got, want)
+	}
+
+	if _, err := DeriveMnemonicFromKey(master, 15, 0); err == nil {
+		t.Error("DeriveMnemonicFromKey() with 15 words succeeded, want an error")
+	}
+	if _, err := DeriveMnemonicFromKey(master, 12, hdkeychain.HardenedKeyStart); err == nil {
+		t.Error("DeriveMnemonicFromKey() with a hardened index succeeded, want an error")
+	}
+}
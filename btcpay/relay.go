@@ -0,0 +1,267 @@
+package btcpay
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/breez/tiny-spark/middleware"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// tokenHeader is the header BTCPay Server sends the pairing token in
+const tokenHeader = "pairingToken"
+
+const (
+	// readHeaderTimeout and idleTimeout keep slow or idle clients from
+	// holding connections open
+	readHeaderTimeout = 10 * time.Second
+	idleTimeout       = 2 * time.Minute
+)
+
+// Relay exposes the wallet as a minimal BTCPay Server Lightning connection.
+// Amounts are millisatoshi strings, as in the BTCPay Greenfield API.
+type Relay struct {
+	wallet  *wallet.Wallet
+	network string
+	token   string
+	logger  *slog.Logger
+}
+
+type nodeInfo struct {
+	Alias               string   `json:"alias"`
+	Network             string   `json:"network"`
+	NodeURIs            []string `json:"nodeURIs"`
+	ActiveChannelsCount int      `json:"activeChannelsCount"`
+	Balance             string   `json:"balance"`
+}
+
+type createInvoiceRequest struct {
+	Amount      json.Number `json:"amount"`
+	Description string      `json:"description"`
+	Expiry      int64       `json:"expiry"`
+}
+
+type invoiceData struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	BOLT11         string `json:"BOLT11"`
+	PaidAt         *int64 `json:"paidAt"`
+	ExpiresAt      int64  `json:"expiresAt"`
+	Amount         string `json:"amount"`
+	AmountReceived string `json:"amountReceived,omitempty"`
+}
+
+type payInvoiceRequest struct {
+	BOLT11 string `json:"BOLT11"`
+}
+
+type paymentData struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	BOLT11      string `json:"BOLT11"`
+	CreatedAt   int64  `json:"createdAt"`
+	TotalAmount string `json:"totalAmount"`
+	FeeAmount   string `json:"feeAmount"`
+}
+
+// New creates a relay backed by the given wallet that only accepts requests
+// carrying token
+func New(w *wallet.Wallet, network, token string, logger *slog.Logger) *Relay {
+	return &Relay{
+		wallet:  w,
+		network: network,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// Handler returns the HTTP handler serving the relay routes
+func (rl *Relay) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/getinfo", rl.handleGetInfo)
+	mux.HandleFunc("/createinvoice", rl.handleCreateInvoice)
+	mux.HandleFunc("/getinvoice/", rl.handleGetInvoice)
+	mux.HandleFunc("/payinvoice", rl.handlePayInvoice)
+
+	return middleware.Logging(rl.logger, rl.authenticate(mux))
+}
+
+// ListenAndServe starts serving the relay on addr
+func (rl *Relay) ListenAndServe(addr string) error {
+	rl.logger.Info("starting btcpay relay", "addr", addr)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           rl.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	return srv.ListenAndServe()
+}
+
+// authenticate rejects requests without the configured pairing token
+func (rl *Relay) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(tokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(rl.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing %s header", tokenHeader))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (rl *Relay) handleGetInfo(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	balance, err := rl.wallet.GetBalance(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Spark wallets are nodeless, so there are no node URIs or channels to report
+	writeJSON(w, http.StatusOK, nodeInfo{
+		Alias:    "tiny-spark",
+		Network:  rl.network,
+		NodeURIs: []string{},
+		Balance:  msat(balance.LightningBalanceSats),
+	})
+}
+
+func (rl *Relay) handleCreateInvoice(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req createInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	amountMsat, err := strconv.ParseUint(req.Amount.String(), 10, 64)
+	if err != nil || amountMsat == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid amount: %q", req.Amount))
+		return
+	}
+	if amountMsat%1000 != 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("amount must be a whole number of satoshis"))
+		return
+	}
+
+	invoice, err := rl.wallet.CreateInvoice(r.Context(), amountMsat/1000, req.Description, time.Duration(req.Expiry)*time.Second)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, invoiceDataFrom(invoice))
+}
+
+func (rl *Relay) handleGetInvoice(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	invoiceID := strings.TrimPrefix(r.URL.Path, "/getinvoice/")
+	if invoiceID == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invoice id is required"))
+		return
+	}
+
+	invoice, err := rl.wallet.GetInvoice(r.Context(), invoiceID)
+	if errors.Is(err, wallet.ErrInvoiceNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, invoiceDataFrom(invoice))
+}
+
+func (rl *Relay) handlePayInvoice(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req payInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.BOLT11 == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("BOLT11 is required"))
+		return
+	}
+
+	response, err := rl.wallet.SendLightningInvoice(r.Context(), req.BOLT11)
+	var insufficient *wallet.ErrInsufficientFunds
+	if errors.As(err, &insufficient) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paymentData{
+		ID:          response.PaymentHash,
+		Status:      response.Status,
+		BOLT11:      req.BOLT11,
+		CreatedAt:   response.CompletedAt.Unix(),
+		TotalAmount: msat(response.AmountSats + response.FeeSats),
+		FeeAmount:   msat(response.FeeSats),
+	})
+}
+
+func invoiceDataFrom(invoice *wallet.Invoice) invoiceData {
+	data := invoiceData{
+		ID:        invoice.ID,
+		Status:    invoice.Status,
+		BOLT11:    invoice.Bolt11,
+		ExpiresAt: invoice.ExpiresAt.Unix(),
+		Amount:    msat(int64(invoice.AmountSats)),
+	}
+	if invoice.PaidAt != nil {
+		paidAt := invoice.PaidAt.Unix()
+		data.PaidAt = &paidAt
+		data.AmountReceived = msat(invoice.AmountReceivedSats)
+	}
+	return data
+}
+
+// msat formats a satoshi amount as a millisatoshi string
+func msat(sats int64) string {
+	return strconv.FormatInt(sats*1000, 10)
+}
+
+// requireMethod writes a 405 response if the request uses a different method
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
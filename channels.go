@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// channelsCommand opens and closes Lightning channels
+func channelsCommand(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		printChannelsUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		channelsList(ctx, w)
+	case "open":
+		channelsOpen(ctx, w, args[1:])
+	case "close":
+		channelsClose(ctx, w, args[1:])
+	case "rebalance":
+		channelsRebalance(ctx, w, args[1:])
+	default:
+		fmt.Printf("Unknown channels command: %s\n\n", args[0])
+		printChannelsUsage()
+	}
+}
+
+func printChannelsUsage() {
+	fmt.Println("Usage: tiny-client channels <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  list                                          List channels with their health")
+	fmt.Println("  open <peer_pubkey> <amount_sats> [--private]  Open a channel with a peer")
+	fmt.Println("  close <channel_id> [--force]                  Close a channel, unilaterally with --force")
+	fmt.Println("  rebalance --amount-sats N [--estimate-only]   Move liquidity between channels with a payment to self")
+	fmt.Println()
+	fmt.Println("Spark wallets are nodeless: channels are managed by the Spark operators")
+}
+
+// channelsList prints the channels with their local balance share and health
+func channelsList(ctx context.Context, w *wallet.Wallet) {
+	channels, err := w.ListChannels(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list channels: %v", err)
+	}
+	if len(channels) == 0 {
+		fmt.Println("No channels")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "CHANNEL ID\tPEER\tCAPACITY\tLOCAL\tLAST PAYMENT\tHEALTH")
+	fmt.Fprintln(tabWriter, "----------\t----\t--------\t-----\t------------\t------")
+	for _, ch := range channels {
+		lastPayment := "never"
+		if !ch.LastPaymentAt.IsZero() {
+			lastPayment = ch.LastPaymentAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			ch.ChannelID, truncateString(ch.PeerPubkey, 16), ch.CapacitySats, ch.LocalBalanceSats,
+			lastPayment, wallet.CheckChannelHealth(ch))
+	}
+	tabWriter.Flush()
+}
+
+func channelsOpen(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("channels open", flag.ExitOnError)
+	private := fs.Bool("private", false, "Don't announce the channel to the network")
+	args = parseArgs(fs, args)
+
+	if len(args) < 2 {
+		fmt.Println("Usage: tiny-client channels open <peer_pubkey> <amount_sats> [--private]")
+		return
+	}
+
+	amount, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid amount: %v", err)
+	}
+
+	response, err := w.OpenChannel(ctx, args[0], amount, *private)
+	if err != nil {
+		log.Fatalf("Failed to open channel: %v", err)
+	}
+
+	fmt.Println("Channel Opening:")
+	fmt.Printf("Channel ID:   %s\n", response.ChannelID)
+	fmt.Printf("Funding TXID: %s\n", response.FundingTxid)
+	fmt.Printf("Capacity:     %d sats\n", response.CapacitySats)
+}
+
+func channelsClose(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("channels close", flag.ExitOnError)
+	force := fs.Bool("force", false, "Force close the channel without the peer's cooperation")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client channels close <channel_id> [--force]")
+		return
+	}
+
+	if err := w.CloseChannel(ctx, args[0], *force); err != nil {
+		log.Fatalf("Failed to close channel: %v", err)
+	}
+	fmt.Printf("Channel %s closing\n", args[0])
+}
+
+func channelsRebalance(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("channels rebalance", flag.ExitOnError)
+	amount := fs.Int64("amount-sats", 0, "Amount of liquidity to move")
+	estimateOnly := fs.Bool("estimate-only", false, "Show the estimated fee without rebalancing")
+	parseArgs(fs, args)
+
+	if *amount <= 0 {
+		fmt.Println("Usage: tiny-client channels rebalance --amount-sats <amount> [--estimate-only]")
+		return
+	}
+
+	// The estimate and the rebalance go through the same route search, so
+	// both fail the same way until the SDK supports self-payments
+	result, err := w.RebalanceChannels(ctx, *amount)
+	if err != nil {
+		log.Fatalf("Failed to rebalance channels: %v", err)
+	}
+
+	fmt.Printf("Estimated fee: %d sats\n", result.FeeSats)
+	if *estimateOnly {
+		return
+	}
+	fmt.Println("Rebalance Complete:")
+	fmt.Printf("Amount:       %d sats\n", result.AmountSats)
+	fmt.Printf("Fee:          %d sats\n", result.FeeSats)
+	fmt.Printf("Payment Hash: %s\n", result.PaymentHash)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// bolt11Prefixes are the human readable parts of mainnet, testnet and regtest invoices
+var bolt11Prefixes = []string{"lnbc", "lntb", "lnbcrt"}
+
+// readClipboard returns the trimmed clipboard contents without a lightning: prefix
+func readClipboard() (string, error) {
+	content, err := clipboard.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %w", err)
+	}
+
+	content = strings.TrimSpace(content)
+	if len(content) > len("lightning:") && strings.EqualFold(content[:len("lightning:")], "lightning:") {
+		content = content[len("lightning:"):]
+	}
+	if content == "" {
+		return "", fmt.Errorf("clipboard is empty")
+	}
+	return content, nil
+}
+
+// readClipboardInvoice returns the BOLT11 invoice on the clipboard, refusing
+// anything that doesn't look like one
+func readClipboardInvoice() (string, error) {
+	content, err := readClipboard()
+	if err != nil {
+		return "", err
+	}
+
+	lower := strings.ToLower(content)
+	for _, prefix := range bolt11Prefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return content, nil
+		}
+	}
+	return "", fmt.Errorf("clipboard does not contain a BOLT11 invoice: %q", truncateString(content, 20))
+}
@@ -1,43 +1,263 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	// defaultWorkingDir is the working directory used when none is configured
+	defaultWorkingDir = ".tiny-spark-data"
+	// encryptedEnvFile is the SOPS encrypted .env file looked up in the working directory
+	encryptedEnvFile = ".env.enc"
+	// defaultLNGraphAPI is the Lightning graph API queried for node information
+	defaultLNGraphAPI = "https://1ml.com/node/{pubkey}/json"
+)
+
 type Config struct {
 	BreezAPIKey     string
 	BreezMnemonic   string
 	BreezNetwork    string
 	BreezWorkingDir string
+	BreezLogLevel   string
+
+	// Interval between background wallet syncs
+	BreezSyncIntervalSecs int
+
+	// Optional endpoint serving token metadata as JSON at <url>/<token_id>
+	BreezTokenMetadataURL string
+
+	// Lightning graph API queried for node information, as <url> with a
+	// {pubkey} placeholder or <url>/<pubkey>
+	BreezLNGraphAPI string
+
+	// Circuit breaker settings for repeated SDK failures
+	BreezCircuitFailureThreshold int
+	BreezCircuitResetSecs        int
+
+	// Token BTCPay Server must send to btcpay-relay
+	BreezBTCPayToken string
+
+	// Bearer token serve API clients must send to call non-GET routes
+	BreezServeToken string
+
+	// Sends above this many sats need --confirm-large
+	BreezWarnAboveSats int
+
+	// Receives below this many sats are probes, listed with type "probe" and
+	// left out of balance calculations
+	BreezMinReceiveSats int
+	// Accept probes without logging them or emitting payment events
+	BreezAutoAcceptProbing bool
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() (*Config, error) {
-	// Try to load .env file, but don't fail if it doesn't exist
-	if err := godotenv.Load(); err != nil {
-		fmt.Printf("Warning: Could not load .env file: %v\n", err)
+// LoadConfig loads configuration from environment variables. If configFile is
+// set that file is loaded, otherwise the first .env found in the search path is
+// used. Values already present in the environment are never overridden.
+func LoadConfig(configFile string) (*Config, error) {
+	if configFile != "" {
+		return LoadConfigFromFiles([]string{configFile})
+	}
+
+	values := make(map[string]string)
+	if path, ok := findEnvFile(); ok {
+		// Try to read the .env file, but don't fail if it can't be read
+		read, err := godotenv.Read(path)
+		if err != nil {
+			fmt.Printf("Warning: Could not load %s: %v\n", path, err)
+			fmt.Println("Using environment variables from system")
+		} else {
+			values = read
+		}
+	} else {
+		fmt.Println("Warning: Could not find a .env file")
 		fmt.Println("Using environment variables from system")
 	}
 
-	config := &Config{
+	setEnvValues(withEncryptedEnv(values))
+	return validate(fromEnv())
+}
+
+// LoadConfigFromFiles loads configuration from the given .env files in order,
+// with later files taking precedence over earlier ones. Values already present
+// in the environment take precedence over all files.
+func LoadConfigFromFiles(files []string) (*Config, error) {
+	if err := loadEnvFiles(files); err != nil {
+		return nil, err
+	}
+	return validate(fromEnv())
+}
+
+// loadEnvFiles merges the .env files in order and sets the variables that are
+// not already in the environment
+func loadEnvFiles(files []string) error {
+	merged := make(map[string]string)
+	for _, file := range files {
+		values, err := godotenv.Read(file)
+		if err != nil {
+			return fmt.Errorf("failed to load config file %s: %w", file, err)
+		}
+		for key, value := range values {
+			merged[key] = value
+		}
+		slog.Debug("Loaded config file", "path", file)
+	}
+
+	setEnvValues(withEncryptedEnv(merged))
+	return nil
+}
+
+// setEnvValues sets the variables that are not already in the environment
+func setEnvValues(values map[string]string) {
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); !ok {
+			os.Setenv(key, value)
+		}
+	}
+}
+
+// withEncryptedEnv returns values with the variables of the SOPS encrypted
+// .env.enc in the working directory merged over them. If the file can't be
+// decrypted a warning is logged and values are returned unchanged.
+func withEncryptedEnv(values map[string]string) map[string]string {
+	workingDir := os.Getenv("BREEZ_WORKING_DIR")
+	if workingDir == "" {
+		workingDir = values["BREEZ_WORKING_DIR"]
+	}
+	if workingDir == "" {
+		workingDir = getEnv("BREEZ_DATA_DIR", values["BREEZ_DATA_DIR"])
+	}
+	if workingDir == "" {
+		workingDir = defaultWorkingDir
+	}
+
+	path := filepath.Join(workingDir, encryptedEnvFile)
+	if _, err := os.Stat(path); err != nil {
+		return values
+	}
+
+	decrypted, err := decryptEnvFile(path)
+	if err != nil {
+		slog.Warn("Failed to decrypt config, using the plaintext config", "path", path, "error", err)
+		return values
+	}
+	slog.Debug("Loaded encrypted config file", "path", path)
+
+	merged := make(map[string]string, len(values)+len(decrypted))
+	for key, value := range values {
+		merged[key] = value
+	}
+	for key, value := range decrypted {
+		merged[key] = value
+	}
+	return merged
+}
+
+// decryptEnvFile decrypts a SOPS encrypted .env file with the sops binary from
+// SOPS_BINARY, or from $PATH if it isn't set, and parses the KEY=value pairs
+func decryptEnvFile(path string) (map[string]string, error) {
+	binary := os.Getenv("SOPS_BINARY")
+	if binary == "" {
+		var err error
+		if binary, err = exec.LookPath("sops"); err != nil {
+			return nil, fmt.Errorf("sops not found: %w", err)
+		}
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(binary, "--decrypt", "--input-type", "dotenv", "--output-type", "dotenv", path)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("sops --decrypt failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("sops --decrypt failed: %w", err)
+	}
+
+	values, err := godotenv.Unmarshal(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// validate checks the whole configuration and returns all errors found as
+// ConfigErrors. Warnings don't fail it.
+func validate(config *Config) (*Config, error) {
+	var errs ConfigErrors
+	for _, e := range ValidateConfig(config) {
+		if !e.Warning {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return config, nil
+}
+
+// fromEnv builds a Config from the environment, applying defaults
+func fromEnv() *Config {
+	return &Config{
 		BreezAPIKey:     getEnv("BREEZ_API_KEY", ""),
 		BreezMnemonic:   getEnv("BREEZ_MNEMONIC", ""),
 		BreezNetwork:    getEnv("BREEZ_NETWORK", "mainnet"),
-		BreezWorkingDir: getEnv("BREEZ_WORKING_DIR", getEnv("BREEZ_DATA_DIR", ".tiny-spark-data")),
+		BreezWorkingDir: getEnv("BREEZ_WORKING_DIR", getEnv("BREEZ_DATA_DIR", defaultWorkingDir)),
+		BreezLogLevel:   getEnv("BREEZ_LOG_LEVEL", "info"),
+
+		BreezSyncIntervalSecs: getEnvInt("BREEZ_SYNC_INTERVAL_SECS", 60),
+
+		BreezTokenMetadataURL: getEnv("BREEZ_TOKEN_METADATA_URL", ""),
+
+		BreezLNGraphAPI: getEnv("BREEZ_LN_GRAPH_API", defaultLNGraphAPI),
+
+		BreezCircuitFailureThreshold: getEnvInt("BREEZ_CIRCUIT_FAILURE_THRESHOLD", 5),
+		BreezCircuitResetSecs:        getEnvInt("BREEZ_CIRCUIT_RESET_SECS", 30),
+
+		BreezBTCPayToken: getEnv("BREEZ_BTCPAY_TOKEN", ""),
+		BreezServeToken:  getEnv("BREEZ_SERVE_TOKEN", ""),
+
+		BreezWarnAboveSats: getEnvInt("BREEZ_WARN_ABOVE_SATS", 1_000_000),
+
+		BreezMinReceiveSats:    getEnvInt("BREEZ_MIN_RECEIVE_SATS", 1),
+		BreezAutoAcceptProbing: getEnvBool("BREEZ_AUTO_ACCEPT_PROBING", false),
 	}
+}
 
-	// Validate only required fields
-	if config.BreezAPIKey == "" {
-		return nil, fmt.Errorf("BREEZ_API_KEY is required")
+// envSearchPaths returns the .env locations in precedence order
+func envSearchPaths() []string {
+	paths := []string{".env"}
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		paths = append(paths, filepath.Join(xdgConfigHome, "tiny-spark", ".env"))
 	}
-	if config.BreezMnemonic == "" {
-		return nil, fmt.Errorf("BREEZ_MNEMONIC is required")
+	if home, err := os.UserHomeDir(); err == nil {
+		path := filepath.Join(home, ".config", "tiny-spark", ".env")
+		if path != paths[len(paths)-1] {
+			paths = append(paths, path)
+		}
 	}
+	return append(paths, filepath.Join("/etc", "tiny-spark", ".env"))
+}
 
-	return config, nil
+// findEnvFile returns the first .env file that exists in the search path
+func findEnvFile() (string, bool) {
+	for _, path := range envSearchPaths() {
+		if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+			slog.Debug("Found config file", "path", path)
+			return path, true
+		}
+		slog.Debug("Config file not found", "path", path)
+	}
+	return "", false
 }
 
 // getEnv gets an environment variable with a default value
@@ -47,3 +267,19 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt gets an integer environment variable with a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool gets a boolean environment variable with a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownSetting is returned for keys that are not configuration settings
+var ErrUnknownSetting = errors.New("unknown setting")
+
+// setting describes a configuration key that can be read and written with
+// Get and Set
+type setting struct {
+	key       string
+	sensitive bool
+	// required settings must be set, optional ones may be left empty;
+	// ValidateConfig only checks optional settings when they are set
+	required bool
+	optional bool
+	validate func(value string) error
+	value    func(cfg *Config) string
+}
+
+var settings = []setting{
+	{
+		key:       "BREEZ_API_KEY",
+		sensitive: true,
+		required:  true,
+		validate:  validateNonEmpty,
+		value:     func(cfg *Config) string { return cfg.BreezAPIKey },
+	},
+	{
+		key:       "BREEZ_MNEMONIC",
+		sensitive: true,
+		required:  true,
+		validate:  validateNonEmpty,
+		value:     func(cfg *Config) string { return cfg.BreezMnemonic },
+	},
+	{
+		key:      "BREEZ_NETWORK",
+		validate: validateOneOf("mainnet", "testnet", "regtest"),
+		value:    func(cfg *Config) string { return cfg.BreezNetwork },
+	},
+	{
+		key:      "BREEZ_WORKING_DIR",
+		validate: validateNonEmpty,
+		value:    func(cfg *Config) string { return cfg.BreezWorkingDir },
+	},
+	{
+		key:      "BREEZ_LOG_LEVEL",
+		validate: validateOneOf("trace", "debug", "info", "warn", "error"),
+		value:    func(cfg *Config) string { return cfg.BreezLogLevel },
+	},
+	{
+		key:      "BREEZ_SYNC_INTERVAL_SECS",
+		validate: validateIntRange(5, 3600),
+		value:    func(cfg *Config) string { return strconv.Itoa(cfg.BreezSyncIntervalSecs) },
+	},
+	{
+		key:      "BREEZ_TOKEN_METADATA_URL",
+		validate: validateOptionalURL,
+		value:    func(cfg *Config) string { return cfg.BreezTokenMetadataURL },
+	},
+	{
+		key:      "BREEZ_LN_GRAPH_API",
+		validate: validateOptionalURL,
+		value:    func(cfg *Config) string { return cfg.BreezLNGraphAPI },
+	},
+	{
+		key:      "BREEZ_CIRCUIT_FAILURE_THRESHOLD",
+		validate: validateIntRange(1, 1000),
+		value:    func(cfg *Config) string { return strconv.Itoa(cfg.BreezCircuitFailureThreshold) },
+	},
+	{
+		key:      "BREEZ_CIRCUIT_RESET_SECS",
+		validate: validateIntRange(1, 3600),
+		value:    func(cfg *Config) string { return strconv.Itoa(cfg.BreezCircuitResetSecs) },
+	},
+	{
+		key:      "BREEZ_WARN_ABOVE_SATS",
+		validate: validateIntRange(1, 2_100_000_000_000_000),
+		value:    func(cfg *Config) string { return strconv.Itoa(cfg.BreezWarnAboveSats) },
+	},
+	{
+		key:      "BREEZ_MIN_RECEIVE_SATS",
+		validate: validateIntRange(0, 2_100_000_000_000_000),
+		value:    func(cfg *Config) string { return strconv.Itoa(cfg.BreezMinReceiveSats) },
+	},
+	{
+		key:      "BREEZ_AUTO_ACCEPT_PROBING",
+		validate: validateOneOf("true", "false"),
+		value:    func(cfg *Config) string { return strconv.FormatBool(cfg.BreezAutoAcceptProbing) },
+	},
+	{
+		key:       "BREEZ_BTCPAY_TOKEN",
+		sensitive: true,
+		optional:  true,
+		validate:  validateNonEmpty,
+		value:     func(cfg *Config) string { return cfg.BreezBTCPayToken },
+	},
+	{
+		key:       "BREEZ_SERVE_TOKEN",
+		sensitive: true,
+		optional:  true,
+		validate:  validateNonEmpty,
+		value:     func(cfg *Config) string { return cfg.BreezServeToken },
+	},
+}
+
+// SettingKeys returns the keys accepted by Get and Set
+func SettingKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// IsSensitive reports whether a setting holds a secret that must not be printed
+func IsSensitive(key string) bool {
+	s, err := lookupSetting(key)
+	return err == nil && s.sensitive
+}
+
+// EnvFilePath returns the .env file Set writes to: configFile if given,
+// otherwise the first .env in the search path, or ./.env if there is none
+func EnvFilePath(configFile string) string {
+	if configFile != "" {
+		return configFile
+	}
+	if path, ok := findEnvFile(); ok {
+		return path
+	}
+	return ".env"
+}
+
+// Get returns the effective value of a setting, merged from the environment,
+// the .env files and the defaults. Without files the .env file from the search
+// path is used.
+func Get(files []string, key string) (string, error) {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(files) == 0 {
+		if path, ok := findEnvFile(); ok {
+			files = []string{path}
+		}
+	}
+	if err := loadEnvFiles(files); err != nil {
+		return "", err
+	}
+
+	return s.value(fromEnv()), nil
+}
+
+// Validate checks a value for a setting without writing it
+func Validate(key, value string) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", s.key, err)
+	}
+	return nil
+}
+
+// Set validates value and writes it to the .env file, replacing an existing
+// assignment of the key or appending one. It returns the path written to.
+func Set(configFile, key, value string) (string, error) {
+	if err := Validate(key, value); err != nil {
+		return "", err
+	}
+	s, _ := lookupSetting(key)
+
+	path := EnvFilePath(configFile)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	line := s.key + "=" + quoteEnvValue(value)
+	assignment := regexp.MustCompile(`^\s*(export\s+)?` + regexp.QuoteMeta(s.key) + `\s*=`)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	replaced := false
+	for i, l := range lines {
+		if assignment.MatchString(l) {
+			lines[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+func lookupSetting(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+}
+
+// quoteEnvValue quotes values that would otherwise not survive a round trip
+// through the .env parser
+func quoteEnvValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\"'#\\$=") {
+		return value
+	}
+	// Double quoted values are expanded, single quoted ones are taken literally
+	if strings.Contains(value, "$") && !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'"
+	}
+	return strconv.Quote(value)
+}
+
+func validateNonEmpty(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+func validateOneOf(allowed ...string) func(string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func validateIntRange(min, max int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		if n < min || n > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+func validateOptionalURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ConfigError is a problem with one configuration field
+type ConfigError struct {
+	// Field is the environment variable of the field, such as BREEZ_NETWORK
+	Field   string
+	Message string
+	// Warning marks problems that don't stop the wallet from starting
+	Warning bool
+}
+
+func (e ConfigError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ConfigErrors is every error found in a configuration. LoadConfig and
+// LoadConfigFromFiles return it when validation fails.
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// ValidateConfig checks every field of cfg and returns all problems at once
+// instead of stopping at the first, so they can be fixed in one edit. A
+// relative working directory other than the default is only a warning.
+func ValidateConfig(cfg *Config) []ConfigError {
+	var errs []ConfigError
+	for _, s := range settings {
+		value := s.value(cfg)
+		empty := strings.TrimSpace(value) == ""
+		switch {
+		case s.optional && empty:
+		case s.required && empty:
+			errs = append(errs, ConfigError{Field: s.key, Message: "is required"})
+		default:
+			if err := s.validate(value); err != nil {
+				errs = append(errs, ConfigError{Field: s.key, Message: err.Error()})
+			}
+		}
+	}
+
+	// The default is relative on purpose, so it works from any checkout
+	if dir := cfg.BreezWorkingDir; dir != "" && dir != defaultWorkingDir && !filepath.IsAbs(dir) {
+		errs = append(errs, ConfigError{
+			Field:   "BREEZ_WORKING_DIR",
+			Message: "is a relative path, so the wallet data depends on the directory tiny-spark runs in",
+			Warning: true,
+		})
+	}
+	return errs
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+func contactsCommand(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		printContactsUsage()
+		return
+	}
+
+	switch args[0] {
+	case "export":
+		exportContacts(ctx, w, args[1:])
+	case "import":
+		importContacts(ctx, w, args[1:])
+	default:
+		fmt.Printf("Unknown contacts command: %s\n\n", args[0])
+		printContactsUsage()
+	}
+}
+
+func printContactsUsage() {
+	fmt.Println("Usage: tiny-client contacts <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  export [--format json|csv] [--output F]       Export all contacts")
+	fmt.Println("  import --input F [--format json|csv] [--merge|--replace]")
+	fmt.Println("                                                Import contacts (--merge keeps existing ones)")
+}
+
+func exportContacts(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("contacts export", flag.ExitOnError)
+	format := fs.String("format", "json", "Export format: json or csv")
+	output := fs.String("output", "", "File to write the contacts to instead of stdout")
+	parseArgs(fs, args)
+
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("Unknown format %q: must be json or csv", *format)
+	}
+
+	export, err := w.ExportContacts(ctx)
+	if err != nil {
+		log.Fatalf("Failed to export contacts: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if *format == "csv" {
+		err = writeContactsCSV(out, export.Contacts)
+	} else {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(export)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write contacts: %v", err)
+	}
+
+	if *output != "" {
+		fmt.Printf("Exported %d contacts to %s\n", len(export.Contacts), *output)
+	}
+}
+
+func importContacts(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("contacts import", flag.ExitOnError)
+	input := fs.String("input", "", "Contacts file to import")
+	format := fs.String("format", "", "Import format: json or csv (default from the file extension)")
+	merge := fs.Bool("merge", false, "Add new contacts and keep existing ones (default)")
+	replace := fs.Bool("replace", false, "Delete all existing contacts before importing")
+	parseArgs(fs, args)
+
+	if *input == "" {
+		log.Fatalf("--input is required")
+	}
+	if *merge && *replace {
+		log.Fatalf("--merge and --replace cannot be used together")
+	}
+	if *format == "" {
+		*format = "json"
+		if strings.EqualFold(filepath.Ext(*input), ".csv") {
+			*format = "csv"
+		}
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *input, err)
+	}
+	defer file.Close()
+
+	var export wallet.ContactsExport
+	switch *format {
+	case "json":
+		if err := json.NewDecoder(file).Decode(&export); err != nil {
+			log.Fatalf("Failed to parse %s: %v", *input, err)
+		}
+	case "csv":
+		export.Version = wallet.ContactsExportVersion
+		if export.Contacts, err = readContactsCSV(file); err != nil {
+			log.Fatalf("Failed to parse %s: %v", *input, err)
+		}
+	default:
+		log.Fatalf("Unknown format %q: must be json or csv", *format)
+	}
+
+	for i, contact := range export.Contacts {
+		if contact.Name == "" || contact.Address == "" {
+			log.Fatalf("Contact %d in %s has no name or address", i+1, *input)
+		}
+	}
+
+	added, skipped, err := w.ImportContacts(ctx, &export, *replace)
+	if err != nil {
+		log.Fatalf("Failed to import contacts: %v", err)
+	}
+	fmt.Printf("Imported %d contacts, skipped %d already in the contact book\n", added, skipped)
+}
+
+func writeContactsCSV(out io.Writer, contacts []wallet.ContactExport) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"name", "address", "type"})
+	for _, contact := range contacts {
+		writer.Write([]string{contact.Name, contact.Address, contact.Type})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func readContactsCSV(in io.Reader) ([]wallet.ContactExport, error) {
+	records, err := csv.NewReader(in).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var contacts []wallet.ContactExport
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(record[0], "name") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected name and address", i+1)
+		}
+		contact := wallet.ContactExport{Name: record[0], Address: record[1]}
+		if len(record) > 2 {
+			contact.Type = record[2]
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, nil
+}
@@ -0,0 +1,81 @@
+package detect
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// InputTypeHint is the kind of payment destination a string looks like
+type InputTypeHint int
+
+const (
+	Unknown InputTypeHint = iota
+	Bolt11
+	Bolt12
+	BitcoinAddress
+	SparkAddress
+	LightningAddress
+	LNURL
+	BIP21
+)
+
+func (h InputTypeHint) String() string {
+	switch h {
+	case Bolt11:
+		return "BOLT11 invoice"
+	case Bolt12:
+		return "BOLT12 offer"
+	case BitcoinAddress:
+		return "Bitcoin address"
+	case SparkAddress:
+		return "Spark address"
+	case LightningAddress:
+		return "Lightning address"
+	case LNURL:
+		return "LNURL"
+	case BIP21:
+		return "BIP21 URI"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrUnknownInput is returned by Detect for strings no matcher recognizes
+var ErrUnknownInput = errors.New("unrecognized payment destination")
+
+// bech32Chars is the bech32 data character set, without the separator
+const bech32Chars = `[02-9ac-hj-np-z]`
+
+type matcher struct {
+	hint    InputTypeHint
+	pattern *regexp.Regexp
+}
+
+// matchers are tried in order, so prefixes that are also valid in a later
+// matcher's alphabet come first. Bech32 input is case-insensitive; legacy
+// base58 addresses are not.
+var matchers = []matcher{
+	{BIP21, regexp.MustCompile(`(?i)^bitcoin:`)},
+	{LNURL, regexp.MustCompile(`(?i)^(lightning:)?lnurl1` + bech32Chars + `+$`)},
+	{LNURL, regexp.MustCompile(`(?i)^(lnurlp|lnurlw|lnurlc|keyauth)://`)},
+	{Bolt12, regexp.MustCompile(`(?i)^(lightning:)?lno1` + bech32Chars + `+$`)},
+	{Bolt11, regexp.MustCompile(`(?i)^(lightning:)?ln(bcrt|bc|tbs|tb|sb)[0-9]*[munp]?1` + bech32Chars + `+$`)},
+	{SparkAddress, regexp.MustCompile(`(?i)^(spark|sparkrt|sparkt|sparks|sp)1` + bech32Chars + `+$`)},
+	{BitcoinAddress, regexp.MustCompile(`(?i)^(bc|tb|bcrt)1` + bech32Chars + `{8,87}$`)},
+	{BitcoinAddress, regexp.MustCompile(`^[13mn2][1-9A-HJ-NP-Za-km-z]{25,34}$`)},
+	{LightningAddress, regexp.MustCompile(`(?i)^[a-z0-9._%+-]+@[a-z0-9-]+(\.[a-z0-9-]+)*\.[a-z]{2,}$`)},
+}
+
+// Detect classifies a payment destination by its format alone. It returns
+// ErrUnknownInput when no format matches; callers can then ask the SDK to
+// parse it.
+func Detect(input string) (InputTypeHint, error) {
+	input = strings.TrimSpace(input)
+	for _, m := range matchers {
+		if m.pattern.MatchString(input) {
+			return m.hint, nil
+		}
+	}
+	return Unknown, ErrUnknownInput
+}
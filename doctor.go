@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// doctor checks the configuration step by step, connecting to the SDK last,
+// and exits with status 1 if any check fails
+func doctor(configFiles []string, network string) {
+	ok := true
+	check := func(name string, err error) bool {
+		if err != nil {
+			fmt.Printf("[FAIL] %s: %v\n", name, err)
+			ok = false
+			return false
+		}
+		fmt.Printf("[ok]   %s\n", name)
+		return true
+	}
+
+	cfg, err := loadConfig(configFiles)
+	if err == nil && network != "" {
+		if err = config.Validate("BREEZ_NETWORK", network); err == nil {
+			cfg.BreezNetwork = network
+		}
+	}
+	if !check("Configuration loads", err) {
+		os.Exit(1)
+	}
+
+	check("Mnemonic is valid", wallet.ValidateMnemonic(cfg.BreezMnemonic))
+	check("Working directory is writable", checkWritable(cfg.BreezWorkingDir))
+	if !ok {
+		os.Exit(1)
+	}
+
+	fmt.Printf("       Connecting to %s...\n", cfg.BreezNetwork)
+	w, err := wallet.NewWallet(cfg)
+	if !check("SDK starts", err) {
+		os.Exit(1)
+	}
+	defer w.Close()
+
+	if !check("SDK connected", w.Ping(context.Background())) {
+		w.Close()
+		os.Exit(1)
+	}
+
+	balance, err := w.GetBalance(context.Background())
+	if check("Balance is readable", err) {
+		fmt.Printf("       Balance: %d sats\n", balance.LightningBalanceSats)
+	}
+
+	if !ok {
+		w.Close()
+		os.Exit(1)
+	}
+	fmt.Println()
+	fmt.Println("All checks passed")
+}
+
+// checkWritable creates dir if needed and checks a file can be written in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(filepath.Clean(file.Name()))
+}
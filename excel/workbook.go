@@ -0,0 +1,243 @@
+package excel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/breez/tiny-spark/wallet"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	transactionsSheet = "Transactions"
+	summarySheet      = "Summary"
+	feeHistorySheet   = "Fee History"
+
+	satsFormat = `#,##0 "sats"`
+	dateFormat = "yyyy-mm-dd hh:mm:ss"
+
+	// Fill colors of received and sent transaction rows
+	receivedColor = "C6EFCE"
+	sentColor     = "FFC7CE"
+)
+
+// styles holds the cell style IDs of a workbook
+type styles struct {
+	header int
+	sats   int
+	date   int
+	month  int
+	number int
+}
+
+// WriteWorkbook writes transactions and the monthly fee history to an .xlsx
+// file with a Transactions, a Summary and a Fee History sheet. Amounts are
+// numeric cells and timestamps date cells so they can be used in formulas.
+func WriteWorkbook(path string, transactions []*wallet.Transaction, feeHistory []wallet.FeeHistoryBucket) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	s, err := newStyles(f)
+	if err != nil {
+		return err
+	}
+
+	if err := f.SetSheetName("Sheet1", transactionsSheet); err != nil {
+		return err
+	}
+	if err := writeTransactions(f, s, transactions); err != nil {
+		return fmt.Errorf("failed to write %s sheet: %w", transactionsSheet, err)
+	}
+
+	if _, err := f.NewSheet(summarySheet); err != nil {
+		return err
+	}
+	if err := writeSummary(f, s, transactions); err != nil {
+		return fmt.Errorf("failed to write %s sheet: %w", summarySheet, err)
+	}
+
+	if _, err := f.NewSheet(feeHistorySheet); err != nil {
+		return err
+	}
+	if err := writeFeeHistory(f, s, feeHistory); err != nil {
+		return fmt.Errorf("failed to write %s sheet: %w", feeHistorySheet, err)
+	}
+
+	f.SetActiveSheet(0)
+	return f.SaveAs(path)
+}
+
+func newStyles(f *excelize.File) (*styles, error) {
+	satsFmt, dateFmt, monthFmt := satsFormat, dateFormat, "yyyy-mm"
+	definitions := []*excelize.Style{
+		{Font: &excelize.Font{Bold: true}, Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9D9D9"}}},
+		{CustomNumFmt: &satsFmt},
+		{CustomNumFmt: &dateFmt},
+		{CustomNumFmt: &monthFmt},
+		{NumFmt: 2},
+	}
+
+	ids := make([]int, len(definitions))
+	for i, definition := range definitions {
+		id, err := f.NewStyle(definition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cell style: %w", err)
+		}
+		ids[i] = id
+	}
+	return &styles{header: ids[0], sats: ids[1], date: ids[2], month: ids[3], number: ids[4]}, nil
+}
+
+// writeHeader writes a bold header row and freezes it
+func writeHeader(f *excelize.File, s *styles, sheet string, headers []string) error {
+	if err := f.SetSheetRow(sheet, "A1", &headers); err != nil {
+		return err
+	}
+	last, err := excelize.CoordinatesToCellName(len(headers), 1)
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "A1", last, s.header); err != nil {
+		return err
+	}
+	return f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// setColumnStyle applies a style to rows 2 to lastRow of a column
+func setColumnStyle(f *excelize.File, sheet, column string, lastRow, style int) error {
+	if lastRow < 2 {
+		return nil
+	}
+	return f.SetCellStyle(sheet, column+"2", fmt.Sprintf("%s%d", column, lastRow), style)
+}
+
+func writeTransactions(f *excelize.File, s *styles, transactions []*wallet.Transaction) error {
+	sheet := transactionsSheet
+	if err := writeHeader(f, s, sheet, []string{"Time", "Type", "Status", "Amount", "Fee", "Description", "Payment ID", "Note", "Labels"}); err != nil {
+		return err
+	}
+
+	for i, tx := range transactions {
+		row := []interface{}{tx.Timestamp, tx.Type, tx.Status, tx.AmountSats, tx.FeeSats, tx.Description, tx.ID, tx.Memo, strings.Join(tx.Labels, ", ")}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return err
+		}
+	}
+
+	lastRow := len(transactions) + 1
+	for column, style := range map[string]int{"A": s.date, "D": s.sats, "E": s.sats} {
+		if err := setColumnStyle(f, sheet, column, lastRow, style); err != nil {
+			return err
+		}
+	}
+	for _, width := range []struct {
+		column string
+		width  float64
+	}{{"A", 20}, {"D", 16}, {"E", 12}, {"F", 30}, {"G", 66}, {"H", 30}, {"I", 20}} {
+		if err := f.SetColWidth(sheet, width.column, width.column, width.width); err != nil {
+			return err
+		}
+	}
+	if lastRow < 2 {
+		return nil
+	}
+
+	// Color each row by the transaction type in column B
+	received, err := f.NewConditionalStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{receivedColor}}})
+	if err != nil {
+		return err
+	}
+	sent, err := f.NewConditionalStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{sentColor}}})
+	if err != nil {
+		return err
+	}
+	return f.SetConditionalFormat(sheet, fmt.Sprintf("A2:I%d", lastRow), []excelize.ConditionalFormatOptions{
+		{Type: "formula", Criteria: `$B2="receive"`, Format: &received},
+		{Type: "formula", Criteria: `$B2="send"`, Format: &sent},
+	})
+}
+
+// summaryRow totals the transactions of one type and status
+type summaryRow struct {
+	txType, status string
+	count          int
+	amountSats     int64
+	feesSats       int64
+}
+
+func writeSummary(f *excelize.File, s *styles, transactions []*wallet.Transaction) error {
+	sheet := summarySheet
+	if err := writeHeader(f, s, sheet, []string{"Type", "Status", "Count", "Amount", "Fees"}); err != nil {
+		return err
+	}
+
+	totals := make(map[[2]string]*summaryRow)
+	for _, tx := range transactions {
+		key := [2]string{tx.Type, tx.Status}
+		row, ok := totals[key]
+		if !ok {
+			row = &summaryRow{txType: tx.Type, status: tx.Status}
+			totals[key] = row
+		}
+		amount := tx.AmountSats
+		if amount < 0 {
+			amount = -amount
+		}
+		row.count++
+		row.amountSats += amount
+		row.feesSats += tx.FeeSats
+	}
+
+	rows := make([]*summaryRow, 0, len(totals))
+	for _, row := range totals {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].txType != rows[j].txType {
+			return rows[i].txType < rows[j].txType
+		}
+		return rows[i].status < rows[j].status
+	})
+
+	for i, row := range rows {
+		values := []interface{}{row.txType, row.status, row.count, row.amountSats, row.feesSats}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &values); err != nil {
+			return err
+		}
+	}
+
+	lastRow := len(rows) + 1
+	for _, column := range []string{"D", "E"} {
+		if err := setColumnStyle(f, sheet, column, lastRow, s.sats); err != nil {
+			return err
+		}
+	}
+	return f.SetColWidth(sheet, "D", "E", 16)
+}
+
+func writeFeeHistory(f *excelize.File, s *styles, buckets []wallet.FeeHistoryBucket) error {
+	sheet := feeHistorySheet
+	if err := writeHeader(f, s, sheet, []string{"Month", "Transactions", "Total Fees", "Average Fee", "Max Fee", "Total Sent", "Fee %"}); err != nil {
+		return err
+	}
+
+	for i := range buckets {
+		bucket := &buckets[i]
+		values := []interface{}{
+			bucket.Start, bucket.Transactions, bucket.TotalFeesSats, bucket.AverageFeeSats(),
+			bucket.MaxFeeSats, bucket.TotalSentSats, bucket.FeePercent(),
+		}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &values); err != nil {
+			return err
+		}
+	}
+
+	lastRow := len(buckets) + 1
+	for column, style := range map[string]int{"A": s.month, "C": s.sats, "D": s.sats, "E": s.sats, "F": s.sats, "G": s.number} {
+		if err := setColumnStyle(f, sheet, column, lastRow, style); err != nil {
+			return err
+		}
+	}
+	return f.SetColWidth(sheet, "B", "F", 14)
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/excel"
+	"github.com/breez/tiny-spark/metrics"
+	"github.com/breez/tiny-spark/wallet"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// exportTimeout bounds a one-shot export so cron jobs never hang
+const exportTimeout = 30 * time.Second
+
+// QR code image sizes accepted by export qr, in pixels
+const (
+	minQRSize = 64
+	maxQRSize = 1024
+)
+
+func exportCommand(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		printExportUsage()
+		return
+	}
+
+	switch args[0] {
+	case "prometheus":
+		exportPrometheus(ctx, w)
+	case "lndhub":
+		exportLNDHub(ctx, w, args[1:])
+	case "excel":
+		exportExcel(ctx, w, args[1:])
+	case "csv":
+		exportCSV(ctx, w, args[1:])
+	default:
+		fmt.Printf("Unknown export format: %s\n\n", args[0])
+		printExportUsage()
+	}
+}
+
+func printExportUsage() {
+	fmt.Println("Usage: tiny-client export <format>")
+	fmt.Println("Formats:")
+	fmt.Println("  prometheus                     Print wallet metrics in the Prometheus text format")
+	fmt.Println("  lndhub [--output export.json]  Export the payment history in the LNDHub format")
+	fmt.Println("  excel --output history.xlsx    Export transactions, totals and fee history as a spreadsheet")
+	fmt.Println("  csv [--output history.csv]     Export transactions with their local notes and labels as CSV")
+	fmt.Println("  qr <invoice_id> --output invoice.png [--size 256]")
+	fmt.Println("                                 Save the QR code of a created invoice as a PNG image")
+	fmt.Println("  qr --string <text> --output qr.png [--size 256]")
+	fmt.Println("                                 Save the QR code of any payment request or address")
+}
+
+func exportPrometheus(ctx context.Context, w *wallet.Wallet) {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	// The SDK calls ignore ctx, so collect in the background and give up on
+	// the timeout instead of waiting for them
+	type result struct {
+		snapshot *metrics.Snapshot
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		snapshot, err := metrics.Collect(ctx, w)
+		done <- result{snapshot, err}
+	}()
+
+	var snapshot *metrics.Snapshot
+	select {
+	case r := <-done:
+		if r.err != nil {
+			log.Fatalf("Failed to collect metrics: %v", r.err)
+		}
+		snapshot = r.snapshot
+	case <-ctx.Done():
+		log.Fatalf("Failed to collect metrics: no answer within %s", exportTimeout)
+	}
+
+	if err := metrics.WritePrometheus(os.Stdout, snapshot); err != nil {
+		log.Fatalf("Failed to write metrics: %v", err)
+	}
+}
+
+func exportLNDHub(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("export lndhub", flag.ExitOnError)
+	output := fs.String("output", "", "File to write the export to instead of stdout")
+	parseArgs(fs, args)
+
+	export, skipped, err := w.ExportLNDHub(ctx)
+	if err != nil {
+		log.Fatalf("Failed to export history: %v", err)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode export: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+	} else if err := os.WriteFile(*output, data, 0600); err != nil {
+		log.Fatalf("Failed to write export: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d invoices, %d payments and %d on-chain transactions\n",
+		len(export.Invoices), len(export.Payments), len(export.Transactions))
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d Spark and token transfers, which LNDHub can't represent\n", skipped)
+	}
+}
+
+// exportExcel writes the payment history, totals and monthly fee history to an
+// .xlsx file. Spreadsheets are binary so they can't be written to stdout.
+func exportExcel(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("export excel", flag.ExitOnError)
+	output := fs.String("output", "", "The .xlsx file to write")
+	parseArgs(fs, args)
+
+	if *output == "" {
+		log.Fatalf("--output is required: an Excel file can't be written to stdout")
+	}
+
+	transactions, err := w.GetAllTransactions(ctx)
+	if err != nil {
+		log.Fatalf("Failed to export history: %v", err)
+	}
+	feeHistory, err := w.ComputeFeeHistory(ctx, time.Time{}, time.Time{}, "month")
+	if err != nil {
+		log.Fatalf("Failed to compute fee history: %v", err)
+	}
+
+	if err := excel.WriteWorkbook(*output, transactions, feeHistory); err != nil {
+		log.Fatalf("Failed to write export: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d transactions to %s\n", len(transactions), *output)
+}
+
+// exportCSV writes every transaction with its local note and labels as CSV.
+// Labels are separated by semicolons.
+func exportCSV(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("export csv", flag.ExitOnError)
+	output := fs.String("output", "", "File to write the export to instead of stdout")
+	parseArgs(fs, args)
+
+	transactions, err := w.GetAllTransactions(ctx)
+	if err != nil {
+		log.Fatalf("Failed to export history: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"timestamp", "type", "status", "amount_sats", "fee_sats", "description", "payment_id", "payment_hash", "note", "labels"})
+	for _, tx := range transactions {
+		writer.Write([]string{
+			tx.Timestamp.UTC().Format(time.RFC3339),
+			tx.Type,
+			tx.Status,
+			strconv.FormatInt(tx.AmountSats, 10),
+			strconv.FormatInt(tx.FeeSats, 10),
+			tx.Description,
+			tx.ID,
+			tx.PaymentHash,
+			tx.Memo,
+			strings.Join(tx.Labels, ";"),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatalf("Failed to write export: %v", err)
+	}
+
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d transactions to %s\n", len(transactions), *output)
+	}
+}
+
+// exportQR writes the QR code of an invoice from invoices.json, or of any
+// string, to a PNG file. It runs without connecting to the SDK.
+func exportQR(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("export qr", flag.ExitOnError)
+	output := fs.String("output", "", "The .png file to write")
+	size := fs.Int("size", 256, "Width and height of the image in pixels (64-1024)")
+	text := fs.String("string", "", "Encode this string instead of an invoice")
+	args = parseArgs(fs, args)
+
+	if *output == "" {
+		log.Fatalf("--output is required")
+	}
+	if *size < minQRSize || *size > maxQRSize {
+		log.Fatalf("Invalid --size %d: must be between %d and %d pixels", *size, minQRSize, maxQRSize)
+	}
+
+	content := *text
+	switch {
+	case content != "" && len(args) > 0:
+		log.Fatalf("An invoice ID and --string can't be used together")
+	case content == "" && len(args) == 0:
+		printExportUsage()
+		return
+	case content == "":
+		invoices, err := wallet.ListInvoices(accountDir(cfg))
+		if err != nil {
+			log.Fatalf("Failed to load invoices: %v", err)
+		}
+		for _, invoice := range invoices {
+			if invoice.ID == args[0] {
+				content = strings.ToUpper(invoice.Bolt11)
+				break
+			}
+		}
+		if content == "" {
+			log.Fatalf("Failed to export QR code: %v: %s (use --string for addresses)", wallet.ErrInvoiceNotFound, args[0])
+		}
+	}
+
+	if err := qrcode.WriteFile(content, qrcode.Medium, *size, *output); err != nil {
+		log.Fatalf("Failed to write QR code: %v", err)
+	}
+	fmt.Printf("Wrote %dx%d QR code to %s\n", *size, *size, *output)
+}
+
+func importCommand(cfg *config.Config, args []string) {
+	if len(args) < 1 {
+		printImportUsage()
+		return
+	}
+
+	switch args[0] {
+	case "lndhub":
+		importLNDHub(cfg, args[1:])
+	case "list":
+		showImported(cfg)
+	default:
+		fmt.Printf("Unknown import command: %s\n\n", args[0])
+		printImportUsage()
+	}
+}
+
+func printImportUsage() {
+	fmt.Println("Usage: tiny-client import <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  lndhub --input export.json  Import history from an LNDHub export (no payments are made)")
+	fmt.Println("  list                        Show the imported history")
+}
+
+func importLNDHub(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("import lndhub", flag.ExitOnError)
+	input := fs.String("input", "", "LNDHub export file to import")
+	parseArgs(fs, args)
+
+	if *input == "" {
+		log.Fatalf("--input is required")
+	}
+
+	added, err := wallet.ImportLNDHub(accountDir(cfg), *input)
+	if err != nil {
+		log.Fatalf("Failed to import history: %v", err)
+	}
+	fmt.Printf("Imported %d new records from %s\n", added, *input)
+}
+
+func showImported(cfg *config.Config) {
+	transactions, err := wallet.ImportedTransactions(accountDir(cfg))
+	if err != nil {
+		log.Fatalf("Failed to load imported history: %v", err)
+	}
+
+	fmt.Println("Imported Transactions:")
+	fmt.Println(strings.Repeat("-", 20))
+	if len(transactions) == 0 {
+		fmt.Println("No imported transactions found")
+		return
+	}
+
+	printTransactionTable(transactions, func(description string) string {
+		return truncateString(description, 20)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// showFailed lists the failed payments of a recent period
+func showFailed(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("failed", flag.ExitOnError)
+	sinceStr := fs.String("since", "7d", "Period to include, such as 7d or 12h, or a date (YYYY-MM-DD)")
+	limit := fs.Int("limit", 50, "Maximum number of payments to show")
+	parseArgs(fs, args)
+
+	since, err := parseSince(*sinceStr, time.Now())
+	if err != nil {
+		log.Fatalf("Invalid --since: %v", err)
+	}
+
+	transactions, err := w.ListFailedPayments(ctx, since, *limit)
+	if err != nil {
+		log.Fatalf("Failed to list failed payments: %v", err)
+	}
+
+	fmt.Printf("Failed Payments Since %s:\n", since.Format("2006-01-02 15:04"))
+	fmt.Println(strings.Repeat("-", 20))
+
+	if len(transactions) == 0 {
+		fmt.Println("No failed payments found")
+		return
+	}
+
+	printTransactionTable(transactions, func(description string) string {
+		return truncateString(description, 20)
+	})
+	fmt.Println("\nRetry a lightning payment with: tiny-client retry <payment_id>")
+}
+
+// retryPayment pays the destination of a failed payment again
+func retryPayment(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client retry <payment_id>")
+		return
+	}
+
+	response, err := w.RetryPayment(ctx, args[0])
+	if err != nil {
+		log.Fatalf("Failed to retry payment: %v", err)
+	}
+
+	fmt.Printf("Payment Sent:\n")
+	fmt.Printf("Payment Hash: %s\n", response.PaymentHash)
+	fmt.Printf("Amount:       %d sats\n", response.AmountSats)
+	fmt.Printf("Fee:          %d sats\n", response.FeeSats)
+	fmt.Printf("Status:       %s\n", response.Status)
+}
+
+// parseSince parses a period such as 7d, 12h or 30m before now, or a date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid number of days: %q", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+func feeHistory(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("fee-history", flag.ExitOnError)
+	sinceStr := fs.String("since", "", "First day to include (YYYY-MM-DD)")
+	untilStr := fs.String("until", "", "Last day to include (YYYY-MM-DD)")
+	groupBy := fs.String("group-by", "", "Group fees by day, week or month")
+	parseArgs(fs, args)
+
+	var since, until time.Time
+	var err error
+	if *sinceStr != "" {
+		if since, err = time.ParseInLocation("2006-01-02", *sinceStr, time.Local); err != nil {
+			log.Fatalf("Invalid --since date: %v", err)
+		}
+	}
+	if *untilStr != "" {
+		if until, err = time.ParseInLocation("2006-01-02", *untilStr, time.Local); err != nil {
+			log.Fatalf("Invalid --until date: %v", err)
+		}
+		// Include the whole last day
+		until = until.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	buckets, err := w.ComputeFeeHistory(ctx, since, until, *groupBy)
+	if err != nil {
+		log.Fatalf("Failed to compute fee history: %v", err)
+	}
+
+	totals := wallet.FeeHistoryBucket{}
+	for _, bucket := range buckets {
+		totals.Transactions += bucket.Transactions
+		totals.TotalFeesSats += bucket.TotalFeesSats
+		totals.TotalSentSats += bucket.TotalSentSats
+		if bucket.MaxFeeSats > totals.MaxFeeSats {
+			totals.MaxFeeSats = bucket.MaxFeeSats
+		}
+	}
+
+	fmt.Println("Fee History:")
+	fmt.Println(strings.Repeat("-", 12))
+	if totals.Transactions == 0 {
+		fmt.Println("No completed transactions found")
+		return
+	}
+
+	if *groupBy != "" {
+		tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tabWriter, "PERIOD\tTXS\tTOTAL FEES\tAVG FEE\tMAX FEE\tFEE %")
+		fmt.Fprintln(tabWriter, "------\t---\t----------\t-------\t-------\t-----")
+		for _, bucket := range buckets {
+			fmt.Fprintf(tabWriter, "%s\t%d\t%d\t%.1f\t%d\t%s\n",
+				formatPeriod(bucket.Start, *groupBy), bucket.Transactions, bucket.TotalFeesSats,
+				bucket.AverageFeeSats(), bucket.MaxFeeSats, formatFeePercent(&bucket))
+		}
+		tabWriter.Flush()
+		fmt.Println()
+	}
+
+	fmt.Printf("Transactions:    %d\n", totals.Transactions)
+	fmt.Printf("Total Fees:      %d sats\n", totals.TotalFeesSats)
+	fmt.Printf("Average Fee:     %.1f sats\n", totals.AverageFeeSats())
+	fmt.Printf("Max Fee:         %d sats\n", totals.MaxFeeSats)
+	fmt.Printf("Fees / Sent:     %s\n", formatFeePercent(&totals))
+}
+
+// formatPeriod labels a fee history bucket by its grouping
+func formatPeriod(start time.Time, groupBy string) string {
+	switch groupBy {
+	case "week":
+		return "week of " + start.Format("2006-01-02")
+	case "month":
+		return start.Format("2006-01")
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+// formatFeePercent shows the fee percentage, or "-" when nothing was sent
+func formatFeePercent(bucket *wallet.FeeHistoryBucket) string {
+	if bucket.TotalSentSats == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", bucket.FeePercent())
+}
+
+// feeReportPeriods are the periods fee-report accepts
+var feeReportPeriods = map[string]time.Duration{
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
+}
+
+// feeReport shows the fees of the sends in the last period by payment method,
+// compared to the period before it, and the sends with notable fees
+func feeReport(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("fee-report", flag.ExitOnError)
+	period := fs.String("period", "30d", "Period to report on: 7d, 30d, 90d or 1y")
+	parseArgs(fs, args)
+
+	length, ok := feeReportPeriods[*period]
+	if !ok {
+		log.Fatalf("Invalid period %q: must be 7d, 30d, 90d or 1y", *period)
+	}
+
+	until := time.Now()
+	since := until.Add(-length)
+	report, err := w.ComputeFeeReport(ctx, since, until)
+	if err != nil {
+		log.Fatalf("Failed to compute fee report: %v", err)
+	}
+	previous, err := w.ComputeFeeReport(ctx, since.Add(-length), since)
+	if err != nil {
+		log.Fatalf("Failed to compute fee report: %v", err)
+	}
+
+	title := fmt.Sprintf("Fee Report (last %s):", *period)
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("-", len(title)))
+	if report.Total.Payments == 0 {
+		fmt.Println("No completed sends found")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "METHOD\tPAYMENTS\tSENT\tFEES\tFEE %")
+	fmt.Fprintln(tabWriter, "------\t--------\t----\t----\t-----")
+	for _, group := range append(report.Groups, &report.Total) {
+		fmt.Fprintf(tabWriter, "%s\t%d\t%d\t%d\t%s\n", group.Method, group.Payments, group.SentSats, group.FeesSats, formatGroupFeePercent(group))
+	}
+	tabWriter.Flush()
+
+	fmt.Println()
+	fmt.Printf("Total Fees:      %d sats (%s)\n", report.Total.FeesSats, formatFeeChange(report.Total.FeesSats, previous.Total.FeesSats, *period))
+	fmt.Printf("Average Rate:    %s\n", formatGroupFeePercent(&report.Total))
+
+	if len(report.Notable) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("Notable fees (above %.0f%% of the amount):\n", wallet.NotableFeePercent)
+	tabWriter = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "DATE\tMETHOD\tAMOUNT\tFEE\tFEE %\tPAYMENT ID")
+	fmt.Fprintln(tabWriter, "----\t------\t------\t---\t-----\t----------")
+	for _, notable := range report.Notable {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%d\t%d\t%.2f%%\t%s\n", notable.Timestamp.Format("2006-01-02 15:04:05"),
+			notable.Method, notable.AmountSats, notable.FeeSats, notable.FeePercent(), notable.PaymentID)
+	}
+	tabWriter.Flush()
+}
+
+// formatGroupFeePercent formats the fee rate of a fee report group, "-" when
+// nothing was sent
+func formatGroupFeePercent(group *wallet.FeeReportGroup) string {
+	if group.SentSats == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", group.FeePercent())
+}
+
+// formatFeeChange compares the fees of a period to the period before it
+func formatFeeChange(fees, previous int64, period string) string {
+	if previous == 0 {
+		return fmt.Sprintf("no fees in the previous %s", period)
+	}
+	change := float64(fees-previous) / float64(previous) * 100
+	return fmt.Sprintf("%+.0f%% vs last %s", change, period)
+}
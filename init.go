@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/wallet"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// apiKeySignupURL is where Breez API keys can be requested
+const apiKeySignupURL = "https://breez.technology/request-api-key/"
+
+// confirmWords is the number of generated words the user must re-enter
+const confirmWords = 3
+
+// initWizard asks for the wallet settings, writes them to the .env file and
+// runs doctor. The wizard itself never connects to the SDK.
+func initWizard(configFiles []string, network string) {
+	in := bufio.NewReader(os.Stdin)
+
+	fmt.Println("Breez Tiny Spark setup")
+	fmt.Println()
+
+	mnemonic := askMnemonic(in)
+
+	fmt.Println()
+	fmt.Printf("A Breez API key is required. Request one at %s\n", apiKeySignupURL)
+	apiKey := askValid(in, "API key", "", "BREEZ_API_KEY")
+
+	if network == "" {
+		network = "mainnet"
+	}
+	network = askValid(in, "Network (mainnet, testnet or regtest)", network, "BREEZ_NETWORK")
+
+	defaultDir := ".tiny-spark"
+	if home, err := os.UserHomeDir(); err == nil {
+		defaultDir = filepath.Join(home, ".tiny-spark")
+	}
+	workingDir := askValid(in, "Working directory", defaultDir, "BREEZ_WORKING_DIR")
+	if rest, ok := strings.CutPrefix(workingDir, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			workingDir = filepath.Join(home, rest)
+		}
+	}
+
+	// Write to the file with the highest precedence, like config set
+	var configFile string
+	if len(configFiles) > 0 {
+		configFile = configFiles[len(configFiles)-1]
+	}
+	path := config.EnvFilePath(configFile)
+	if _, err := os.Stat(path); err == nil {
+		if !askYesNo(in, fmt.Sprintf("%s exists, update its settings?", path)) {
+			fmt.Println("Nothing written")
+			return
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("Failed to check %s: %v", path, err)
+	}
+
+	settings := [][2]string{
+		{"BREEZ_MNEMONIC", mnemonic},
+		{"BREEZ_API_KEY", apiKey},
+		{"BREEZ_NETWORK", network},
+		{"BREEZ_WORKING_DIR", workingDir},
+	}
+	for _, setting := range settings {
+		if _, err := config.Set(path, setting[0], setting[1]); err != nil {
+			log.Fatalf("Failed to write %s: %v", setting[0], err)
+		}
+	}
+	fmt.Printf("Wrote %s\n", path)
+	fmt.Println()
+
+	doctor([]string{path}, "")
+}
+
+// askMnemonic generates a mnemonic the user confirms or reads an existing one
+func askMnemonic(in *bufio.Reader) string {
+	for {
+		answer := strings.ToLower(ask(in, "Generate a new mnemonic (g) or enter an existing one (e)?", "g"))
+		switch answer {
+		case "g", "generate":
+			return generateMnemonic(in)
+		case "e", "enter":
+			for {
+				mnemonic := strings.Join(strings.Fields(ask(in, "Mnemonic", "")), " ")
+				if err := wallet.ValidateMnemonic(mnemonic); err != nil {
+					fmt.Printf("Invalid mnemonic: %v\n", err)
+					continue
+				}
+				return mnemonic
+			}
+		}
+	}
+}
+
+// generateMnemonic shows a new 12 word mnemonic and has the user re-enter
+// some of its words to show it was written down
+func generateMnemonic(in *bufio.Reader) string {
+	entropy, err := bip39.NewEntropy(128)
+	if err != nil {
+		log.Fatalf("Failed to generate entropy: %v", err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		log.Fatalf("Failed to generate mnemonic: %v", err)
+	}
+	words := strings.Fields(mnemonic)
+
+	fmt.Println()
+	fmt.Println("Write down these words in order. They are the only way to recover the wallet:")
+	fmt.Println()
+	for i, word := range words {
+		fmt.Printf("  %2d. %s\n", i+1, word)
+	}
+	fmt.Println()
+
+	for {
+		confirmed := true
+		for _, i := range rand.Perm(len(words))[:confirmWords] {
+			if strings.ToLower(ask(in, fmt.Sprintf("Word #%d", i+1), "")) != words[i] {
+				confirmed = false
+			}
+		}
+		if confirmed {
+			return mnemonic
+		}
+		fmt.Println("That doesn't match the mnemonic above, check the words and try again")
+	}
+}
+
+// askValid asks until the answer is a valid value for the setting key
+func askValid(in *bufio.Reader, question, defaultValue, key string) string {
+	for {
+		answer := ask(in, question, defaultValue)
+		if err := config.Validate(key, answer); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return answer
+	}
+}
+
+func askYesNo(in *bufio.Reader, question string) bool {
+	answer := strings.ToLower(ask(in, question+" [y/N]", ""))
+	return answer == "y" || answer == "yes"
+}
+
+// ask prints a question and returns the trimmed answer, or defaultValue if
+// the answer is empty. It exits when the input ends.
+func ask(in *bufio.Reader, question, defaultValue string) string {
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		log.Fatalf("Setup aborted: %v", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return defaultValue
+}
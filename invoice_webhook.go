@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// webhookLogFile collects the output of the background webhook waits
+const webhookLogFile = "webhooks.log"
+
+// detachInvoiceWebhook starts tiny-client again in the background to wait for
+// the invoice to be paid and notify webhook, so receive --webhook returns as
+// soon as the invoice is printed. The child's output is appended to
+// webhooks.log in the account data directory.
+func detachInvoiceWebhook(w *wallet.Wallet, invoice *wallet.ReceivePaymentResponse, webhook string, autoAcceptProbing bool) {
+	executable, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to start the webhook wait: %v", err)
+	}
+	logPath := filepath.Join(w.DataDir(), webhookLogFile)
+	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", logPath, err)
+	}
+	defer logFile.Close()
+
+	// Keep the global flags, such as --config-file and --network, so the child
+	// connects to the same wallet
+	childArgs := append([]string{}, os.Args[1:len(os.Args)-len(flag.Args())]...)
+	childArgs = append(childArgs, "notify-invoice",
+		"--webhook", webhook,
+		"--expires", strconv.FormatInt(invoice.ExpiresAt.Unix(), 10))
+	if autoAcceptProbing {
+		childArgs = append(childArgs, "--auto-accept-probing")
+	}
+	childArgs = append(childArgs, invoice.PaymentRequest)
+
+	cmd := exec.Command(executable, childArgs...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		log.Fatalf("Failed to start the webhook wait: %v", err)
+	}
+	fmt.Printf("\nWaiting for the payment in the background (pid %d), logging to %s\n", cmd.Process.Pid, logPath)
+	cmd.Process.Release()
+}
+
+// notifyInvoice is the background half of receive --webhook: it waits for the
+// invoice to be paid or expire and POSTs the payment to the webhook
+func notifyInvoice(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("notify-invoice", flag.ExitOnError)
+	webhook := fs.String("webhook", "", "URL to POST the payment to")
+	expires := fs.Int64("expires", 0, "Unix time the invoice expires at")
+	autoAcceptProbing := fs.Bool("auto-accept-probing", false, "Ignore receives below BREEZ_MIN_RECEIVE_SATS")
+	args = parseArgs(fs, args)
+
+	if len(args) != 1 || *webhook == "" || *expires == 0 {
+		log.Fatalf("Usage: tiny-client notify-invoice <bolt11> --webhook <url> --expires <unix_time>")
+	}
+	if *autoAcceptProbing {
+		w.SetAutoAcceptProbing(true)
+	}
+
+	invoice := &wallet.ReceivePaymentResponse{PaymentRequest: args[0], ExpiresAt: time.Unix(*expires, 0)}
+	waitInvoicePayment(ctx, w, invoice, *webhook)
+}
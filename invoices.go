@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/detect"
+	"github.com/breez/tiny-spark/wallet"
+	"github.com/skip2/go-qrcode"
+)
+
+// invoicesCommand manages the invoices recorded by receive lightning,
+// btcpay-relay and the LNURL servers. It only reads the account directory, so
+// it runs without connecting to the SDK; invoices check runs as invoicesCheck
+// once connected.
+func invoicesCommand(cfg *config.Config, args []string) {
+	if len(args) < 1 {
+		printInvoicesUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		listInvoices(cfg, args[1:])
+	case "cleanup":
+		cleanupInvoices(cfg, args[1:])
+	case "verify":
+		verifyInvoice(cfg, args[1:])
+	case "qr":
+		invoiceQR(args[1:])
+	default:
+		fmt.Printf("Unknown invoices command: %s\n\n", args[0])
+		printInvoicesUsage()
+	}
+}
+
+func printInvoicesUsage() {
+	fmt.Println("Usage: tiny-client invoices <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  list [--expired]               Show recorded invoices")
+	fmt.Println("  cleanup --before YYYY-MM-DD    Delete paid and expired invoices created before the date")
+	fmt.Println("  check                          Update unpaid invoices from the payment history")
+	fmt.Println("  verify <bolt11>                Check whether an invoice was created by this wallet")
+	fmt.Println("  qr <invoice_or_address> [--output F]  Show a payment request as a QR code, or save it as PNG")
+}
+
+// invoiceQR shows any payment request as a QR code in the terminal, or writes
+// it to a PNG file with --output. Unlike export qr it takes the request itself
+// rather than the ID of a stored invoice.
+func invoiceQR(args []string) {
+	fs := flag.NewFlagSet("invoices qr", flag.ExitOnError)
+	output := fs.String("output", "", "Write the QR code to this .png file instead of the terminal")
+	size := fs.Int("size", 256, "Width and height of the image in pixels (64-1024, with --output)")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client invoice qr <bolt11_or_address> [--output file.png] [--size 256]")
+		return
+	}
+	if *size < minQRSize || *size > maxQRSize {
+		log.Fatalf("Invalid --size %d: must be between %d and %d pixels", *size, minQRSize, maxQRSize)
+	}
+
+	// Only the format is checked, which needs no connection to the SDK
+	content := strings.TrimSpace(args[0])
+	hint, err := detect.Detect(content)
+	if err != nil {
+		log.Fatalf("Failed to create QR code: %v", err)
+	}
+	// Upper case invoices fit the denser alphanumeric QR mode
+	if hint == detect.Bolt11 {
+		content = strings.ToUpper(content)
+	}
+
+	if *output == "" {
+		fmt.Printf("%s:\n", hint)
+		if err := printQR(content); err != nil {
+			log.Fatalf("Failed to create QR code: %v", err)
+		}
+		return
+	}
+	if err := qrcode.WriteFile(content, qrcode.Medium, *size, *output); err != nil {
+		log.Fatalf("Failed to write QR code: %v", err)
+	}
+	fmt.Printf("Wrote %dx%d QR code of the %s to %s\n", *size, *size, hint, *output)
+}
+
+// verifyInvoice tells whether an invoice is in the invoice database, exiting
+// with code 1 when it isn't so scripts can check provenance
+func verifyInvoice(cfg *config.Config, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client invoices verify <bolt11>")
+		return
+	}
+
+	own, invoice, err := wallet.IsOwnInvoice(accountDir(cfg), args[0])
+	if err != nil {
+		log.Fatalf("Failed to verify invoice: %v", err)
+	}
+	if !own {
+		fmt.Println("This invoice is not in our database")
+		os.Exit(1)
+	}
+	fmt.Printf("This invoice was created by this wallet on %s for %d sats\n", invoice.CreatedAt.Format("2006-01-02 15:04:05"), invoice.AmountSats)
+}
+
+// invoicesCheck marks unpaid invoices paid or expired from the payment history
+func invoicesCheck(ctx context.Context, w *wallet.Wallet) {
+	updated, err := w.CheckInvoices(ctx)
+	if err != nil {
+		log.Fatalf("Failed to check invoices: %v", err)
+	}
+	fmt.Printf("Updated the status of %d invoices\n", updated)
+}
+
+func listInvoices(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("invoices list", flag.ExitOnError)
+	expired := fs.Bool("expired", false, "Only show expired invoices")
+	parseArgs(fs, args)
+
+	invoices, err := wallet.ListInvoices(accountDir(cfg))
+	if err != nil {
+		log.Fatalf("Failed to load invoices: %v", err)
+	}
+
+	fmt.Println("Invoices:")
+	fmt.Println(strings.Repeat("-", 20))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tAMOUNT\tSTATUS\tCREATED\tEXPIRES\tDESCRIPTION")
+	fmt.Fprintln(tw, "--\t------\t------\t-------\t-------\t-----------")
+	shown := 0
+	for _, invoice := range invoices {
+		if *expired && invoice.Status != wallet.InvoiceStatusExpired {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			truncateString(invoice.ID, 16),
+			invoice.AmountSats,
+			invoice.Status,
+			invoice.CreatedAt.Format("2006-01-02 15:04"),
+			invoice.ExpiresAt.Format("2006-01-02 15:04"),
+			truncateString(invoice.Description, 20))
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Println("No invoices found")
+		return
+	}
+	tw.Flush()
+}
+
+func cleanupInvoices(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("invoices cleanup", flag.ExitOnError)
+	beforeStr := fs.String("before", "", "Delete invoices created before this date (YYYY-MM-DD)")
+	parseArgs(fs, args)
+
+	if *beforeStr == "" {
+		log.Fatalf("--before is required")
+	}
+	before, err := time.ParseInLocation("2006-01-02", *beforeStr, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid --before date: %v", err)
+	}
+
+	deleted, err := wallet.CleanupInvoices(accountDir(cfg), before)
+	if err != nil {
+		log.Fatalf("Failed to clean up invoices: %v", err)
+	}
+	fmt.Printf("Deleted %d invoices created before %s\n", deleted, *beforeStr)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+func lightningAddressCommand(ctx context.Context, args []string) {
+	if len(args) < 1 || args[0] != "resolve" {
+		printLightningAddressUsage()
+		return
+	}
+
+	fs := flag.NewFlagSet("lightning-address resolve", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the pay request as JSON")
+	positional := parseArgs(fs, args[1:])
+	if len(positional) < 1 {
+		printLightningAddressUsage()
+		return
+	}
+
+	info, err := wallet.ResolveLightningAddress(ctx, positional[0])
+	if err != nil {
+		log.Fatalf("Failed to resolve lightning address: %v", err)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			log.Fatalf("Failed to encode lightning address: %v", err)
+		}
+		return
+	}
+
+	fmt.Println("Lightning Address:")
+	fmt.Println("------------------")
+	fmt.Printf("Address:     %s\n", info.Address)
+	fmt.Printf("Description: %s\n", info.Description)
+	if info.LongDescription != "" {
+		fmt.Printf("Details:     %s\n", info.LongDescription)
+	}
+	if info.Identifier != "" {
+		fmt.Printf("Identifier:  %s\n", info.Identifier)
+	}
+	if info.Email != "" {
+		fmt.Printf("Email:       %s\n", info.Email)
+	}
+	if info.ImageType != "" {
+		fmt.Printf("Image:       %s\n", info.ImageType)
+	}
+	fmt.Printf("Min Amount:  %d sats\n", info.MinSendableSats)
+	fmt.Printf("Max Amount:  %d sats\n", info.MaxSendableSats)
+	if info.CommentAllowed > 0 {
+		fmt.Printf("Comments:    up to %d characters\n", info.CommentAllowed)
+	} else {
+		fmt.Printf("Comments:    not allowed\n")
+	}
+}
+
+func printLightningAddressUsage() {
+	fmt.Println("Usage: tiny-client lightning-address resolve <user@domain> [--json]")
+}
@@ -0,0 +1,126 @@
+package lightningaddress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// fetchTimeout bounds the request to the Lightning address domain
+const fetchTimeout = 5 * time.Second
+
+// PayRequest is the LNURL-pay response served for a Lightning address
+type PayRequest struct {
+	Tag            string `json:"tag"`
+	Callback       string `json:"callback"`
+	MinSendable    uint64 `json:"minSendable"`
+	MaxSendable    uint64 `json:"maxSendable"`
+	Metadata       string `json:"metadata"`
+	CommentAllowed uint16 `json:"commentAllowed"`
+}
+
+// Metadata is the parsed LNURL-pay metadata
+type Metadata struct {
+	Description     string
+	LongDescription string
+	Identifier      string
+	Email           string
+	// ImageType is the MIME type of the embedded image, if there is one
+	ImageType string
+}
+
+// URL returns the LNURL-pay endpoint of a user@domain Lightning address
+func URL(address string) (string, error) {
+	user, domain, ok := strings.Cut(strings.TrimSpace(address), "@")
+	if !ok || user == "" || domain == "" || strings.ContainsAny(domain, "/@") {
+		return "", fmt.Errorf("invalid lightning address: %q", address)
+	}
+
+	scheme := "https"
+	if strings.HasSuffix(domain, ".onion") {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/.well-known/lnurlp/%s", scheme, strings.ToLower(domain), url.PathEscape(strings.ToLower(user))), nil
+}
+
+// Resolve fetches the LNURL-pay request behind a Lightning address
+func Resolve(ctx context.Context, address string) (*PayRequest, error) {
+	endpoint, err := URL(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		PayRequest
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+		}
+		return nil, fmt.Errorf("failed to parse pay request: %w", err)
+	}
+	if strings.EqualFold(response.Status, "ERROR") {
+		return nil, fmt.Errorf("lightning address service returned an error: %s", response.Reason)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	if response.Tag != "payRequest" {
+		return nil, fmt.Errorf("unexpected lnurl tag %q for a lightning address", response.Tag)
+	}
+
+	return &response.PayRequest, nil
+}
+
+// ParseMetadata parses the JSON array of [mime type, content] pairs an
+// LNURL-pay request carries, ignoring entries it doesn't know
+func ParseMetadata(raw string) (*Metadata, error) {
+	var entries [][]any
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("invalid pay request metadata: %w", err)
+	}
+
+	metadata := &Metadata{}
+	for _, entry := range entries {
+		if len(entry) < 2 {
+			continue
+		}
+		kind, _ := entry[0].(string)
+		content, _ := entry[1].(string)
+
+		switch {
+		case kind == "text/plain":
+			metadata.Description = content
+		case kind == "text/long-desc":
+			metadata.LongDescription = content
+		case kind == "text/identifier":
+			metadata.Identifier = content
+		case kind == "text/email":
+			metadata.Email = content
+		case strings.HasPrefix(kind, "image/"):
+			metadata.ImageType = strings.TrimSuffix(kind, ";base64")
+		}
+	}
+	return metadata, nil
+}
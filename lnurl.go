@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/breez/tiny-spark/lnurlpay"
+	"github.com/breez/tiny-spark/wallet"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+func lnurlCommand(ctx context.Context, args []string) {
+	if len(args) < 1 || args[0] != "decode" {
+		printLnurlUsage()
+		return
+	}
+
+	fs := flag.NewFlagSet("lnurl decode", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the decoded LNURL as JSON")
+	fromClipboard := fs.Bool("from-clipboard", false, "Read the LNURL from the clipboard")
+	positional := parseArgs(fs, args[1:])
+
+	var lnurl string
+	switch {
+	case *fromClipboard:
+		var err error
+		lnurl, err = readClipboard()
+		if err != nil {
+			log.Fatalf("Failed to read lnurl: %v", err)
+		}
+	case len(positional) > 0:
+		lnurl = positional[0]
+	default:
+		printLnurlUsage()
+		return
+	}
+
+	info, err := wallet.InspectLnurl(ctx, lnurl)
+	if err != nil {
+		log.Fatalf("Failed to decode lnurl: %v", err)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			log.Fatalf("Failed to encode lnurl: %v", err)
+		}
+		return
+	}
+
+	fmt.Println("LNURL Details:")
+	fmt.Println("--------------")
+	fmt.Printf("Type:        %s\n", info.Type)
+	fmt.Printf("Domain:      %s\n", info.Domain)
+	fmt.Printf("URL:         %s\n", info.URL)
+	if info.Description != "" {
+		fmt.Printf("Description: %s\n", info.Description)
+	}
+
+	switch info.Type {
+	case "pay":
+		fmt.Printf("Min Amount:  %d sats\n", info.MinSendableMsat/1000)
+		fmt.Printf("Max Amount:  %d sats\n", info.MaxSendableMsat/1000)
+		if info.CommentAllowed > 0 {
+			fmt.Printf("Comment:     up to %d characters\n", info.CommentAllowed)
+		}
+	case "withdraw":
+		fmt.Printf("Min Amount:  %d sats\n", info.MinWithdrawableMsat/1000)
+		fmt.Printf("Max Amount:  %d sats\n", info.MaxWithdrawableMsat/1000)
+	case "auth":
+		if info.Action != "" {
+			fmt.Printf("Action:      %s\n", info.Action)
+		}
+	case "channel":
+		fmt.Printf("Node:        %s\n", info.NodeURI)
+	}
+}
+
+// lnurlServe serves a static LNURL-pay endpoint that creates an invoice for
+// each payer
+func lnurlServe(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("lnurl serve", flag.ExitOnError)
+	amountSats := fs.Uint64("amount-sats", 0, "Amount of every payment, 0 to let the payer choose")
+	port := fs.Int("port", 8080, "Port to listen on")
+	path := fs.String("path", "/tip", "Path of the LNURL-pay endpoint")
+	description := fs.String("description", "Payment to tiny-spark", "Description shown to payers and put in the invoices")
+	parseArgs(fs, args)
+
+	go w.WatchInvoiceExpiry(ctx, wallet.InvoiceExpiryInterval)
+
+	srv := lnurlpay.New(w, *path, *amountSats, *description, slog.Default())
+	fmt.Printf("Serving LNURL-pay on http://localhost:%d/%s\n", *port, strings.Trim(*path, "/"))
+	if err := srv.ListenAndServe(ctx, fmt.Sprintf(":%d", *port)); err != nil {
+		log.Fatalf("LNURL-pay server failed: %v", err)
+	}
+}
+
+// lnurlReceiveDuration is how long receive lnurl serves its pay request
+const lnurlReceiveDuration = 30 * time.Minute
+
+// receiveLnurl serves a temporary LNURL-pay endpoint on a random port until a
+// payment to one of its invoices arrives or lnurlReceiveDuration passes.
+// Timing out exits with code 2.
+func receiveLnurl(ctx context.Context, w *wallet.Wallet, args []string, host string, qr bool) {
+	if len(args) < 2 {
+		printReceiveUsage()
+		return
+	}
+	minSats, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid minimum amount: %v", err)
+	}
+	maxSats, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid maximum amount: %v", err)
+	}
+	if minSats == 0 || maxSats < minSats {
+		log.Fatalf("Invalid amount range: %d-%d sats", minSats, maxSats)
+	}
+	description := strings.Join(args[2:], " ")
+	if description == "" {
+		description = "Payment to tiny-spark"
+	}
+
+	// Port 0 picks a free port, so this can't clash with a running serve
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatalf("Failed to start LNURL-pay server: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	const path = "/lnurlp"
+	payURL := "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + path
+	lnurl, err := wallet.EncodeLnurl(payURL)
+	if err != nil {
+		log.Fatalf("Failed to create lnurl: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lnurlReceiveDuration)
+	defer cancel()
+
+	srv := lnurlpay.NewRange(w, path, minSats, maxSats, description, slog.Default())
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ctx, listener)
+	}()
+
+	fmt.Printf("LNURL-pay Request Created:\n")
+	fmt.Printf("Amount:      %d-%d sats\n", minSats, maxSats)
+	fmt.Printf("Description: %s\n", description)
+	fmt.Printf("URL:         %s\n", payURL)
+	fmt.Printf("Expires:     %s\n", time.Now().Add(lnurlReceiveDuration).Format("2006-01-02 15:04:05"))
+	fmt.Printf("\nLNURL:\n%s\n", lnurl)
+	if qr {
+		if err := printQR(lnurl); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	fmt.Println("\nWaiting for a payment...")
+
+	select {
+	case tx := <-srv.Payments():
+		fmt.Printf("Received +%d sats\n", tx.AmountSats)
+		cancel()
+		<-served
+	case err := <-served:
+		if err != nil {
+			log.Fatalf("LNURL-pay server failed: %v", err)
+		}
+	case <-ctx.Done():
+		<-served
+		fmt.Printf("No payment received within %s\n", lnurlReceiveDuration)
+		// Exit code 2 lets scripts tell a timeout apart from an error
+		os.Exit(2)
+	}
+}
+
+// printQR prints text as a QR code on the terminal
+func printQR(text string) error {
+	code, err := qrcode.New(text, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("failed to create QR code: %w", err)
+	}
+	fmt.Print(code.ToSmallString(false))
+	return nil
+}
+
+func printLnurlUsage() {
+	fmt.Println("Usage: tiny-client lnurl decode <lnurl> [--json]")
+	fmt.Println("       tiny-client lnurl decode --from-clipboard [--json]")
+	fmt.Println("       tiny-client lnurl serve [--amount-sats 5000] [--port 8080] [--path /tip]")
+	fmt.Println("Accepts bech32 LNURLs, lnurlp:// and lnurlw:// URLs and Lightning addresses")
+}
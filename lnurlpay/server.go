@@ -0,0 +1,267 @@
+package lnurlpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/breez/tiny-spark/lightningaddress"
+	"github.com/breez/tiny-spark/middleware"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+const (
+	// maxPayerChosenSats caps the amount when the payer chooses it
+	maxPayerChosenSats = 1_000_000
+	// invoiceExpiry is the expiry of the invoices created for payers
+	invoiceExpiry = 10 * time.Minute
+	// paymentPollInterval is how often received payments are checked for
+	paymentPollInterval = 3 * time.Second
+	// shutdownTimeout bounds how long Serve waits for open requests on exit
+	shutdownTimeout = 5 * time.Second
+	// readHeaderTimeout and idleTimeout keep slow or idle clients from
+	// holding connections open
+	readHeaderTimeout = 10 * time.Second
+	idleTimeout       = 2 * time.Minute
+)
+
+// Server serves a single static LNURL-pay endpoint: <path> returns the pay
+// request and <path>/callback creates an invoice for the requested amount
+type Server struct {
+	wallet      *wallet.Wallet
+	path        string
+	minSats     uint64
+	maxSats     uint64
+	description string
+	logger      *slog.Logger
+
+	// mu guards invoices, the payment hashes of the invoices created for payers
+	mu       sync.Mutex
+	invoices map[string]bool
+	payments chan *wallet.Transaction
+}
+
+type errorResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type callbackResponse struct {
+	PR     string   `json:"pr"`
+	Routes []string `json:"routes"`
+}
+
+// New creates a server for path charging amountSats per payment, or letting
+// the payer choose the amount when it is 0
+func New(w *wallet.Wallet, path string, amountSats uint64, description string, logger *slog.Logger) *Server {
+	if amountSats > 0 {
+		return NewRange(w, path, amountSats, amountSats, description, logger)
+	}
+	return NewRange(w, path, 1, maxPayerChosenSats, description, logger)
+}
+
+// NewRange creates a server for path letting the payer choose an amount
+// between minSats and maxSats
+func NewRange(w *wallet.Wallet, path string, minSats, maxSats uint64, description string, logger *slog.Logger) *Server {
+	return &Server{
+		wallet:      w,
+		path:        "/" + strings.Trim(path, "/"),
+		minSats:     minSats,
+		maxSats:     maxSats,
+		description: description,
+		logger:      logger,
+		invoices:    make(map[string]bool),
+		payments:    make(chan *wallet.Transaction, 1),
+	}
+}
+
+// Payments returns a channel that receives the payments of the invoices this
+// server created. Payments no one reads are dropped.
+func (s *Server) Payments() <-chan *wallet.Transaction {
+	return s.payments
+}
+
+// Handler returns the HTTP handler serving the pay request and its callback
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.path, s.handlePayRequest)
+	mux.HandleFunc(s.path+"/callback", s.handleCallback)
+
+	return middleware.Logging(s.logger, mux)
+}
+
+// ListenAndServe starts serving the endpoint on addr and logs the payments
+// received while it runs
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	go s.logPayments(ctx)
+
+	s.logger.Info("starting lnurl-pay server", "addr", addr, "path", s.path, "min_sats", s.minSats, "max_sats", s.maxSats)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	return srv.ListenAndServe()
+}
+
+// Serve serves the endpoint on listener until ctx is done, logging the
+// payments received while it runs, and then shuts the server down
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go s.logPayments(ctx)
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Info("starting lnurl-pay server", "addr", listener.Addr().String(), "path", s.path, "min_sats", s.minSats, "max_sats", s.maxSats)
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// logPayments logs every payment received since the server started until the
+// context is done, and passes the ones paying this server's invoices, matched
+// by payment hash, to Payments. The seen set lives for the whole run, so a
+// payment that arrives between two polls is still reported once.
+func (s *Server) logPayments(ctx context.Context) {
+	since := uint64(time.Now().Unix())
+	seen := make(map[string]bool)
+
+	ticker := time.NewTicker(paymentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		txs, err := s.wallet.ReceivedPaymentsSince(ctx, since)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("Failed to check for incoming payments", "error", err)
+			}
+			continue
+		}
+
+		for _, tx := range txs {
+			if seen[tx.ID] {
+				continue
+			}
+			seen[tx.ID] = true
+			s.logger.Info("Payment received", "payment_id", tx.ID, "amount_sats", tx.AmountSats, "description", tx.Description)
+
+			s.mu.Lock()
+			ours := s.invoices[tx.PaymentHash]
+			s.mu.Unlock()
+			if ours {
+				select {
+				case s.payments <- tx:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// limits returns the accepted amount range in millisatoshis
+func (s *Server) limits() (minMsat, maxMsat uint64) {
+	return s.minSats * 1000, s.maxSats * 1000
+}
+
+func (s *Server) metadata() string {
+	data, _ := json.Marshal([][]string{{"text/plain", s.description}})
+	return string(data)
+}
+
+func (s *Server) handlePayRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	minMsat, maxMsat := s.limits()
+	writeJSON(w, http.StatusOK, lightningaddress.PayRequest{
+		Tag:         "payRequest",
+		Callback:    baseURL(r) + s.path + "/callback",
+		MinSendable: minMsat,
+		MaxSendable: maxMsat,
+		Metadata:    s.metadata(),
+	})
+}
+
+func (s *Server) handleCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	amountMsat, err := strconv.ParseUint(r.URL.Query().Get("amount"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid amount: %q", r.URL.Query().Get("amount")))
+		return
+	}
+	minMsat, maxMsat := s.limits()
+	if amountMsat < minMsat || amountMsat > maxMsat {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("amount must be between %d and %d msat", minMsat, maxMsat))
+		return
+	}
+	if amountMsat%1000 != 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("amount must be a whole number of satoshis"))
+		return
+	}
+
+	invoice, err := s.wallet.CreateInvoice(r.Context(), amountMsat/1000, s.description, invoiceExpiry)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.invoices[invoice.ID] = true
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, callbackResponse{PR: invoice.Bolt11, Routes: []string{}})
+}
+
+// baseURL returns the scheme and host the request was sent to, honoring
+// X-Forwarded-Proto from a TLS terminating proxy
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an LNURL error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Status: "ERROR", Reason: err.Error()})
+}
@@ -0,0 +1,153 @@
+package logrotate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// CheckInterval is how often Watch checks the log file size by default
+const CheckInterval = 60 * time.Second
+
+// RotatingWriter is an io.Writer appending to a log file that is rotated to
+// <path>.1, <path>.2, ... once it grows past a maximum size. Writes and
+// rotations are serialized, so no line is lost or split across files.
+type RotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+}
+
+// NewRotatingWriter opens path for appending, keeping at most maxBackups
+// rotated files once the log exceeds maxSize bytes
+func NewRotatingWriter(path string, maxSize int64, maxBackups int) (*RotatingWriter, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid maximum log size: %d", maxSize)
+	}
+	if maxBackups < 0 {
+		return nil, fmt.Errorf("invalid maximum log backups: %d", maxBackups)
+	}
+
+	w := &RotatingWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the current log file
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Write(p)
+}
+
+// Close closes the current log file
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// RotateIfNeeded rotates the log file if it is larger than the maximum size
+func (w *RotatingWriter) RotateIfNeeded() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := w.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", w.path, err)
+	}
+	if info.Size() <= w.maxSize {
+		return nil
+	}
+	return w.rotate()
+}
+
+// Watch checks the log file size every interval until ctx is done, reporting
+// rotation failures to onError
+func (w *RotatingWriter) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.RotateIfNeeded(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// rotate moves the current file to <path>.1 and opens a fresh one; the caller
+// must hold the lock
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", w.path, err)
+	}
+
+	// Reopen even if shifting failed so later writes still reach a file
+	err := w.shiftBackups()
+	if openErr := w.open(); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// shiftBackups renames <path>.N to <path>.N+1, dropping the oldest backup, and
+// the current file to <path>.1
+func (w *RotatingWriter) shiftBackups() error {
+	if w.maxBackups == 0 {
+		return removeIfExists(w.path)
+	}
+
+	if err := removeIfExists(w.backup(w.maxBackups)); err != nil {
+		return err
+	}
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		if err := renameIfExists(w.backup(i), w.backup(i+1)); err != nil {
+			return err
+		}
+	}
+	return renameIfExists(w.path, w.backup(1))
+}
+
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", w.path, err)
+	}
+	w.file = file
+	return nil
+}
+
+func (w *RotatingWriter) backup(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+func renameIfExists(from, to string) error {
+	if err := os.Rename(from, to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to rotate %s: %w", from, err)
+	}
+	return nil
+}
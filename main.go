@@ -1,30 +1,166 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"github.com/breez/tiny-spark/bip85"
+	"github.com/breez/tiny-spark/btcpay"
 	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/detect"
+	"github.com/breez/tiny-spark/logrotate"
+	"github.com/breez/tiny-spark/server"
+	"github.com/breez/tiny-spark/uri"
 	"github.com/breez/tiny-spark/wallet"
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	configFile := flag.String("config-file", "", "Path of the .env file to load instead of searching for one")
+	var envFiles stringList
+	flag.Var(&envFiles, "env-file", "Path of a .env file to load; repeat to layer files, later ones win")
+	network := flag.String("network", "", "Override BREEZ_NETWORK: mainnet, testnet or regtest")
+	verbose := flag.Bool("v", false, "Log info messages and SDK sync events")
+	veryVerbose := flag.Bool("vv", false, "Also log every wallet method call with its duration")
+	trace := flag.Bool("vvv", false, "Also log every SDK request and response, with secrets redacted")
+	quiet := flag.Bool("q", false, "Only log errors")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	logLevel, err := verbosity(*verbose, *veryVerbose, *trace, *quiet)
+	if err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+
+	var configFiles []string
+	if *configFile != "" {
+		configFiles = append(configFiles, *configFile)
+	}
+	configFiles = append(configFiles, envFiles...)
+
+	args := flag.Args()
+	if len(args) < 1 {
 		printUsage()
 		return
 	}
 
-	command := os.Args[1]
+	command := args[0]
+
+	// Log the config file search when BREEZ_LOG_LEVEL is already set in the environment
+	setupLogger(levelOr(logLevel, os.Getenv("BREEZ_LOG_LEVEL")), os.Stderr)
+
+	// config edits the .env file, so it must work before the configuration is valid
+	if command == "config" {
+		configCommand(configFiles, args[1:])
+		return
+	}
+	// init creates the configuration and doctor reports what is wrong with it
+	switch command {
+	case "init":
+		initWizard(configFiles, *network)
+		return
+	case "doctor":
+		doctor(configFiles, *network)
+		return
+	}
 
 	// Load configuration
-	cfg, err := config.LoadConfig()
+	cfg, err := loadConfig(configFiles)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatalConfigError(err)
+	}
+	for _, problem := range config.ValidateConfig(cfg) {
+		if problem.Warning {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", problem)
+		}
+	}
+	if *network != "" {
+		if err := config.Validate("BREEZ_NETWORK", *network); err != nil {
+			log.Fatalf("Invalid --network: %v", err)
+		}
+		cfg.BreezNetwork = *network
+	}
+	setupLogger(levelOr(logLevel, cfg.BreezLogLevel), os.Stderr)
+
+	// Commands that only need the configuration run without connecting to the SDK
+	switch command {
+	case "bip85":
+		bip85Command(cfg, args[1:])
+		return
+	case "backup":
+		backupCommand(args[1:])
+		return
+	case "lnurl":
+		if len(args) < 2 || args[1] != "serve" {
+			lnurlCommand(context.Background(), args[1:])
+			return
+		}
+	case "import":
+		importCommand(cfg, args[1:])
+		return
+	case "lightning-address":
+		lightningAddressCommand(context.Background(), args[1:])
+		return
+	case "token":
+		if len(args) > 1 && args[1] == "metadata" {
+			tokenMetadata(cfg, args[2:])
+			return
+		}
+		if len(args) > 1 && (args[1] == "freeze" || args[1] == "unfreeze") {
+			tokenFreeze(cfg, args[1], args[2:])
+			return
+		}
+	case "node":
+		if len(args) > 1 && args[1] == "blacklist" {
+			nodeBlacklist(cfg, args[2:])
+			return
+		}
+	case "accounts":
+		accountsCommand(cfg, args[1:])
+		return
+	case "export":
+		if len(args) > 1 && args[1] == "qr" {
+			exportQR(cfg, args[2:])
+			return
+		}
+	case "invoices", "invoice":
+		if len(args) < 2 || args[1] != "check" {
+			invoicesCommand(cfg, args[1:])
+			return
+		}
+	case "notify-invoice":
+		// Started in the background by receive --webhook; keep waiting when
+		// the terminal it was started from is closed
+		signal.Ignore(syscall.SIGHUP)
+	case "btcpay-relay":
+		// Fail before spending time connecting to the SDK
+		if cfg.BreezBTCPayToken == "" {
+			log.Fatalf("BREEZ_BTCPAY_TOKEN must be set to run btcpay-relay")
+		}
+	}
+
+	if stored, err := wallet.StoredNetwork(cfg.BreezWorkingDir); err != nil {
+		slog.Warn("Failed to read the wallet network", "error", err)
+	} else if stored != "" && stored != cfg.BreezNetwork {
+		fmt.Fprintf(os.Stderr, "Warning: %s was last used on %s, now connecting to %s\n", cfg.BreezWorkingDir, stored, cfg.BreezNetwork)
 	}
 
 	// Initialize wallet
@@ -38,37 +174,67 @@ func main() {
 
 	switch command {
 	case "balance", "bal":
-		showBalance(ctx, w)
+		showBalance(ctx, w, args[1:])
 	case "transactions", "tx":
-		limit := 10
-		if len(os.Args) > 2 {
-			if l, err := strconv.Atoi(os.Args[2]); err == nil {
-				limit = l
-			}
-		}
-		showTransactions(ctx, w, limit)
+		showTransactions(ctx, w, args[1:])
+	case "search":
+		searchTransactions(ctx, w, args[1:])
+	case "fee-history":
+		feeHistory(ctx, w, args[1:])
+	case "fee-report":
+		feeReport(ctx, w, args[1:])
 	case "receive":
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: tiny-client receive <type> <amount> [description]")
-			fmt.Println("Types: lightning, bitcoin, spark")
-			return
-		}
-		receivePayment(ctx, w, os.Args[2], os.Args[3], strings.Join(os.Args[4:], " "))
+		receivePayment(ctx, w, args[1:])
+	case "notify-invoice":
+		notifyInvoice(ctx, w, args[1:])
 	case "send":
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: tiny-client send <type> <destination> <amount>")
-			fmt.Println("Types: lightning, bitcoin, spark, lnurl")
-			return
-		}
-		sendPayment(ctx, w, os.Args[2], os.Args[3], os.Args[4])
+		sendPayment(ctx, w, cfg, args[1:])
+	case "pay":
+		pay(ctx, w, cfg, args[1:])
 	case "payment":
-		if len(os.Args) < 3 {
+		if len(args) < 2 {
 			fmt.Println("Usage: tiny-client payment <payment_id>")
 			return
 		}
-		showPayment(ctx, w, os.Args[2])
+		showPayment(ctx, w, args[1])
 	case "tokens":
-		showTokens(ctx, w)
+		showTokens(ctx, w, args[1:])
+	case "node":
+		nodeCommand(ctx, w, args[1:])
+	case "token":
+		tokenCommand(ctx, w, args[1:])
+	case "wait-receive":
+		waitReceive(ctx, w, args[1:])
+	case "monitor":
+		monitor(ctx, w, args[1:])
+	case "watch-balance":
+		watchBalance(ctx, w, args[1:])
+	case "serve":
+		serve(ctx, w, cfg, logLevel, args[1:])
+	case "btcpay-relay":
+		btcpayRelay(ctx, w, cfg, args[1:])
+	case "lnurl":
+		lnurlServe(ctx, w, args[2:])
+	case "export":
+		exportCommand(ctx, w, args[1:])
+	case "bump-fee":
+		bumpFee(ctx, w, args[1:])
+	case "channels":
+		channelsCommand(ctx, w, args[1:])
+	case "autopilot":
+		autopilot(ctx, w)
+	case "sweep":
+		sweepCommand(ctx, w, args[1:])
+	case "rescan":
+		rescan(ctx, w, args[1:])
+	case "failed":
+		showFailed(ctx, w, args[1:])
+	case "retry":
+		retryPayment(ctx, w, args[1:])
+	case "invoices", "invoice":
+		invoicesCheck(ctx, w)
+	case "contacts":
+		contactsCommand(ctx, w, args[1:])
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -77,25 +243,165 @@ func main() {
 	}
 }
 
+// loadConfig loads the configuration from the given .env files, or from the
+// first .env file in the search path if there are none
+func loadConfig(configFiles []string) (*config.Config, error) {
+	if len(configFiles) > 0 {
+		return config.LoadConfigFromFiles(configFiles)
+	}
+	return config.LoadConfig("")
+}
+
+// fatalConfigError exits with every configuration error listed, or with err
+// itself when the configuration couldn't be loaded at all
+func fatalConfigError(err error) {
+	var problems config.ConfigErrors
+	if !errors.As(err, &problems) {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "Invalid configuration:")
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+	}
+	os.Exit(1)
+}
+
 func printUsage() {
 	fmt.Println("Breez Tiny Spark")
 	fmt.Println("==================")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  tiny-spark <command> [arguments]")
+	fmt.Println("  tiny-spark [global flags] <command> [arguments]")
+	fmt.Println()
+	fmt.Println("Global flags:")
+	fmt.Println("  --config-file <path>           Load this .env file instead of searching for one")
+	fmt.Println("  --env-file <path>              Load this .env file; repeat to layer files, later ones win")
+	fmt.Println("  --network <name>               Use mainnet, testnet or regtest instead of BREEZ_NETWORK")
+	fmt.Println("  -v, -vv, -vvv                  Log info, debug or trace output instead of BREEZ_LOG_LEVEL")
+	fmt.Println("  -q                             Only log errors")
 	fmt.Println()
 	fmt.Println("Commands:")
+	fmt.Println("  init                           Set up a wallet interactively and write the .env file")
+	fmt.Println("  doctor                         Check the configuration and the SDK connection")
 	fmt.Println("  balance, bal                    Show wallet balance")
+	fmt.Println("    --breakdown                  Split the balance into deposits, in-flight payments and tokens")
+	fmt.Println("    --compare-to-yesterday       Show the change since the end of yesterday")
 	fmt.Println("  transactions, tx [limit]       Show transaction history (default 10)")
+	fmt.Println("    --dedup                      Hide duplicate entries with the same payment ID")
+	fmt.Println("    --type send|receive|probe    Only show sends, receives or probes")
+	fmt.Println("    --status <status>            Only show Pending, Complete or Failed transactions")
+	fmt.Println("    --amount-above N             Only show transactions of more than N sats")
+	fmt.Println("    --amount-below N             Only show transactions of less than N sats")
+	fmt.Println("    --group-by-day [--expand]    Show daily totals, with --expand the transactions below")
+	fmt.Println("    --exclude-self, --only-self  Hide or only show payments to the wallet's own invoices")
+	fmt.Println("    --payment-hash <hash>        Show the transaction with this payment hash (full or prefix)")
+	fmt.Println("  search <query> [--regex]       Find transactions by description (case-insensitive)")
+	fmt.Println("  failed [--since 7d]            Show failed payments")
+	fmt.Println("  retry <payment_id>             Pay the invoice of a failed lightning payment again")
+	fmt.Println("  fee-history [--since DATE] [--until DATE] [--group-by day|week|month]")
+	fmt.Println("                                 Summarize fees paid over time")
+	fmt.Println("  fee-report [--period 7d|30d|90d|1y]")
+	fmt.Println("                                 Compare fees by payment method to the previous period")
 	fmt.Println("  receive <type> <amount> [desc]  Create payment request")
+	fmt.Println("    --watch [--timeout 3600]     Wait for an on-chain deposit (bitcoin only, exit 2 on timeout)")
+	fmt.Println("    --webhook <url>              POST the payment as JSON to url once the invoice is paid, waiting in the background (lightning only)")
+	fmt.Println("    --wait                       Wait until the invoice is paid (lightning only, exit 2 on expiry)")
+	fmt.Println("    --auto-accept-probing        Ignore receives below BREEZ_MIN_RECEIVE_SATS while waiting (lightning only)")
+	fmt.Println("    --static                     Show the reusable Spark address (spark only)")
+	fmt.Println("    --refresh                    Fetch the Spark address again instead of using the cache (spark only)")
+	fmt.Println("    --desc-hash <sha256>         Commit to a description hash (lightning only, not supported yet)")
+	fmt.Println("  receive lnurl <min> <max> [desc]  Serve a temporary LNURL-pay request on a random port (30 min)")
+	fmt.Println("    --host <host>                Host or IP payers reach this machine at (default localhost)")
+	fmt.Println("    --qr                         Also print the LNURL as a QR code in the terminal")
+	fmt.Println("  pay <dest> [amount]            Send to an invoice, address or LNURL, detecting its type")
 	fmt.Println("  send <type> <dest> <amount>    Send payment")
+	fmt.Println("    --from-clipboard             Pay the lightning invoice on the clipboard")
+	fmt.Println("    --split N                    Send a spark payment as N sequential payments")
+	fmt.Println("    --estimate                   Show the lightning fee and ask before paying")
+	fmt.Println("    --rbf                        Signal replace-by-fee on a bitcoin payment (not supported yet)")
+	fmt.Println("    --memo <text>                Keep a local note for a lightning payment (not supported for spark)")
+	fmt.Println("    --range                      Show the amount range of an lnurl service without paying")
+	fmt.Println("    --max-fee-percent P          Refuse an lnurl payment whose fee is above P% of the amount")
+	fmt.Println("    --max-fee-sats N             Refuse an lnurl payment whose fee is above N sats")
+	fmt.Println("    --confirm-large              Send more than BREEZ_WARN_ABOVE_SATS without asking")
+	fmt.Println("    --non-interactive            Never prompt; large amounts then need --confirm-large")
+	fmt.Println("    --op-return <hex>            Embed up to 80 bytes in an OP_RETURN output (not supported yet)")
+	fmt.Println("    --consolidate-utxos          Prefer spending many small inputs in a bitcoin payment (not supported yet)")
+	fmt.Println("    --batch-estimate --from-file F  Estimate the fees of sending to each address,amount_sats in F (bitcoin only)")
+	fmt.Println("    --preimage-file F --output R  Pay each invoice in JSONL file F after checking its preimage (lightning only)")
+	fmt.Println("  bump-fee <txid> --fee-rate N   Fee bump a bitcoin payment sent with --rbf (not supported yet)")
+	fmt.Println("  channels list                  List Lightning channels with a HEALTH column (not supported yet)")
+	fmt.Println("  channels open|close|rebalance  Open, close or rebalance Lightning channels (not supported yet)")
+	fmt.Println("  autopilot                      Suggest channels to open or close from the history (not supported yet)")
+	fmt.Println("  sweep lightning <address>      Send the whole balance on-chain, fees included (needs --confirm)")
+	fmt.Println("    --speed fast|medium|slow     Confirmation speed of the sweep (default medium)")
+	fmt.Println("  rescan [--from-timestamp DATE] Rebuild the local payment cache from the SDK")
+	fmt.Println("    --progress                   Show a spinner with the elapsed time instead of per-page lines")
+	fmt.Println("  backup verify <file>           Check a backup file can be restored (not supported yet)")
 	fmt.Println("  payment <id>                   Show payment details")
 	fmt.Println("  tokens                         Show token balances")
+	fmt.Println("    --show-zero                  Include tokens with a zero balance (hidden by default)")
+	fmt.Println("  token list [--all]             Show tokens with metadata and last activity")
+	fmt.Println("  token history <token_id>       Show token transfer history")
+	fmt.Println("  token history --all-tokens     Show the transfers of every held token, newest first")
+	fmt.Println("  token receive --watch --token-id <id> [--timeout 300]")
+	fmt.Println("                                 Wait for an incoming token transfer (exit 2 on timeout)")
+	fmt.Println("  token metadata set <token_id>  Override token name, ticker, decimals or logo")
+	fmt.Println("  token freeze|unfreeze <id>     Stop or resume sending and watching for a token locally")
+	fmt.Println("  token mint|burn <token_id> <amount> --confirm")
+	fmt.Println("                                 Mint or burn supply of the wallet's issued token")
+	fmt.Println("  wait-receive [--timeout 300] [--min-amount-sats 1]")
+	fmt.Println("                                 Wait for an incoming payment (exit 2 on timeout)")
+	fmt.Println("  monitor [--rows 10]            Show a live feed of transactions and the balance")
+	fmt.Println("  watch-balance [--interval 30]  Print the balance when it changes")
+	fmt.Println("    --alert-above N              Send a desktop notification when the balance rises above N sats")
+	fmt.Println("    --alert-below N              Send a desktop notification when the balance falls below N sats")
+	fmt.Println("  serve [--addr localhost:8080]  Serve the wallet over a JSON HTTP API")
+	fmt.Println("    --cors <origin>              Allow browser requests from an origin, or * for any")
+	fmt.Println("    --cors-allowed-origins <a,b> Allow browser requests from several origins")
+	fmt.Println("    --cors-origins-file <path>   Allow browser requests from the origins in a file, reloaded on change")
+	fmt.Println("    --rate-limit <n>             Limit each IP to n requests per minute (POST /send 10, GET /balance 60)")
+	fmt.Println("    --tls-cert F --tls-key F     Serve HTTPS with a PEM certificate and key")
+	fmt.Println("    --tls-auto <domain>          Serve HTTPS with a Let's Encrypt certificate for domain")
+	fmt.Println("    --logfile <path>             Log to a file, rotated by --log-max-size-mb 100 and --log-max-backups 3")
+	fmt.Println("  btcpay-relay [--addr :7070]    Serve as the Lightning backend of a BTCPay Server")
+	fmt.Println("  invoices list [--expired], invoices cleanup --before DATE")
+	fmt.Println("                                 Show or prune the invoices from receive lightning, btcpay-relay and lnurl")
+	fmt.Println("  invoices verify <bolt11>       Check whether an invoice was created by this wallet")
+	fmt.Println("  invoice qr <request> [--output F]  Show a payment request as a QR code, or save it as PNG")
+	fmt.Println("  export prometheus              Print wallet metrics in the Prometheus text format")
+	fmt.Println("  export lndhub [--output F]     Export the payment history in the LNDHub format")
+	fmt.Println("  export excel --output F        Export transactions, totals and fee history as .xlsx")
+	fmt.Println("  export csv [--output F]        Export transactions with their local notes and labels as CSV")
+	fmt.Println("  export qr <invoice_id>|--string S --output F [--size 256]")
+	fmt.Println("                                 Save a QR code of an invoice or any string as a PNG image")
+	fmt.Println("  import lndhub --input F, import list")
+	fmt.Println("                                 Import LNDHub history (no payments are made) and show it")
+	fmt.Println("  contacts export [--format json|csv] [--output F]")
+	fmt.Println("  contacts import --input F [--merge|--replace]")
+	fmt.Println("                                 Move the contact book between wallets")
+	fmt.Println("  lnurl decode <lnurl> [--json]  Show the service an LNURL points to before paying")
+	fmt.Println("  lnurl serve [--amount-sats N] [--port 8080] [--path /tip]")
+	fmt.Println("                                 Serve a static LNURL-pay endpoint")
+	fmt.Println("  lightning-address resolve <user@domain> [--json]")
+	fmt.Println("                                 Show who a Lightning address pays before paying")
+	fmt.Println("  config get <key>, config set <key> <value> [--confirm]")
+	fmt.Println("                                 Read or change a setting in the .env file")
+	fmt.Println("  bip85 derive --index N [--words 12|24]")
+	fmt.Println("                                 Derive a BIP85 child mnemonic (not stored)")
+	fmt.Println("  node info <pubkey>             Show a node's alias, addresses and channels from the LN graph")
+	fmt.Println("  node connect <pubkey>@<host>:<port>  Connect to a Lightning peer (not supported yet)")
+	fmt.Println("  node disconnect <pubkey>       Disconnect from a Lightning peer (not supported yet)")
+	fmt.Println("  node peers                     List connected Lightning peers (not supported yet)")
+	fmt.Println("  node blacklist add|remove <pubkey>, node blacklist list")
+	fmt.Println("                                 Manage nodes excluded from routing")
+	fmt.Println("  accounts list|create <name>|switch <name>")
+	fmt.Println("                                 Manage accounts derived from the mnemonic")
 	fmt.Println("  help                           Show this help")
 	fmt.Println()
 	fmt.Println("Receive types:")
 	fmt.Println("  lightning    Create Lightning invoice")
-	fmt.Println("  bitcoin      Create Bitcoin address")
+	fmt.Println("  bitcoin      Create Bitcoin address (BIP21 URI when an amount is given)")
 	fmt.Println("  spark        Create Spark address")
 	fmt.Println()
 	fmt.Println("Send types:")
@@ -103,15 +409,116 @@ func printUsage() {
 	fmt.Println("  bitcoin      Send to Bitcoin address")
 	fmt.Println("  spark        Send to Spark address")
 	fmt.Println("  lnurl        Pay LNURL address")
+	fmt.Println("  token        Send tokens to a Spark address (--token-id <id> [--human])")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  tiny-spark balance")
 	fmt.Println("  tiny-spark receive lightning 5000 'Coffee payment'")
 	fmt.Println("  tiny-spark send lightning lnbc1... 5000")
+	fmt.Println("  tiny-spark send token spark1... 1.5 --token-id <id> --human")
 	fmt.Println("  tiny-spark transactions 20")
+	fmt.Println("  tiny-spark receive lightning 5000 && tiny-spark wait-receive")
+}
+
+// setupLogger installs the default structured logger at the configured level
+func setupLogger(level string, out io.Writer) {
+	var logLevel slog.Level
+	if strings.EqualFold(level, "trace") {
+		logLevel = wallet.LevelTrace
+	} else if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		logLevel = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
+		Level:       logLevel,
+		ReplaceAttr: traceLevelName,
+	})))
+
+	// slog.SetDefault routes the log package through the handler; keep
+	// log.Fatalf messages as plain lines instead
+	log.SetOutput(out)
+	log.SetFlags(log.LstdFlags)
 }
 
-func showBalance(ctx context.Context, w *wallet.Wallet) {
+// traceLevelName prints wallet.LevelTrace as TRACE instead of DEBUG-4
+func traceLevelName(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level == wallet.LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
+
+// verbosity returns the log level selected by the -v, -vv, -vvv and -q flags,
+// or "" when none of them is set. The most verbose flag wins.
+func verbosity(verbose, veryVerbose, trace, quiet bool) (string, error) {
+	if quiet && (verbose || veryVerbose || trace) {
+		return "", errors.New("-q can't be combined with -v, -vv or -vvv")
+	}
+	switch {
+	case trace:
+		return "trace", nil
+	case veryVerbose:
+		return "debug", nil
+	case verbose:
+		return "info", nil
+	case quiet:
+		return "error", nil
+	default:
+		return "", nil
+	}
+}
+
+// levelOr returns the level set on the command line, or fallback if none was
+func levelOr(flagLevel, fallback string) string {
+	if flagLevel != "" {
+		return flagLevel
+	}
+	return fallback
+}
+
+// stringList is a flag that collects every value it is given
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseArgs parses the flags defined on fs wherever they appear in args and
+// returns the remaining positional arguments in order
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func showBalance(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("balance", flag.ExitOnError)
+	breakdown := fs.Bool("breakdown", false, "Show the deposit, in-flight and token components of the balance")
+	compareToYesterday := fs.Bool("compare-to-yesterday", false, "Show the change since the end of yesterday")
+	parseArgs(fs, args)
+
+	if *breakdown {
+		showBalanceBreakdown(ctx, w)
+		return
+	}
+	if *compareToYesterday {
+		showBalanceChange(ctx, w)
+		return
+	}
+
 	fmt.Println("Wallet Balance:")
 	fmt.Println("----------------")
 	balance, err := w.GetBalance(ctx)
@@ -119,12 +526,121 @@ func showBalance(ctx context.Context, w *wallet.Wallet) {
 		log.Fatalf("Failed to get balance: %v", err)
 	}
 
+	if account := w.GetCurrentAccount(); account.Number != nil {
+		fmt.Printf("Account:           %s\n", account.Name)
+	}
+
 	fmt.Printf("Lightning Balance: %d sats\n", balance.LightningBalanceSats)
 	fmt.Printf("Max Payable:       %d sats\n", balance.MaxPayableSats)
 	fmt.Printf("Max Receivable:    %d sats\n", balance.MaxReceivableSats)
 }
 
-func showTransactions(ctx context.Context, w *wallet.Wallet, limit int) {
+// showBalanceChange compares the balance with the one reconstructed from the
+// payment history at the end of yesterday
+func showBalanceChange(ctx context.Context, w *wallet.Wallet) {
+	balance, err := w.GetBalance(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get balance: %v", err)
+	}
+
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	yesterday, err := w.GetBalanceAt(ctx, today)
+	if errors.Is(err, wallet.ErrNoBalanceHistory) {
+		fmt.Println("No historical data yet; run daily to build history.")
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to get yesterday's balance: %v", err)
+	}
+
+	fmt.Println("Balance Change:")
+	fmt.Println("---------------")
+	fmt.Printf("Today:     %d sats\n", balance.LightningBalanceSats)
+	fmt.Printf("Yesterday: %d sats (end of %s)\n", yesterday, today.AddDate(0, 0, -1).Format("2006-01-02"))
+	fmt.Printf("Change:    %s sats\n", formatAmount(balance.LightningBalanceSats-yesterday, false))
+}
+
+func showBalanceBreakdown(ctx context.Context, w *wallet.Wallet) {
+	breakdown, err := w.GetBalanceBreakdown(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get balance breakdown: %v", err)
+	}
+
+	fmt.Println("Balance Breakdown:")
+	fmt.Println("------------------")
+	fmt.Printf("Spark Balance:            %d sats\n", breakdown.SparkBalanceSats)
+	fmt.Printf("Confirmed On-chain:       %d sats (unclaimed deposits)\n", breakdown.ConfirmedDepositSats)
+	fmt.Printf("Pending On-chain:         %d sats (awaiting confirmations)\n", breakdown.PendingDepositSats)
+	fmt.Printf("In-flight Payments:       %d sats\n", breakdown.InFlightSats)
+	if breakdown.MaxPayableSats != breakdown.SparkBalanceSats {
+		fmt.Printf("Max Payable:              %d sats\n", breakdown.MaxPayableSats)
+	}
+	if breakdown.MaxReceivableSats != breakdown.SparkBalanceSats {
+		fmt.Printf("Max Receivable:           %d sats\n", breakdown.MaxReceivableSats)
+	}
+
+	if len(breakdown.Tokens) > 0 {
+		fmt.Println("Tokens:")
+		for _, token := range breakdown.Tokens {
+			amount := token.Balance
+			if balance, ok := new(big.Int).SetString(token.Balance, 10); ok {
+				amount = wallet.FormatTokenAmount(balance, token.Decimals)
+			}
+			name := token.Ticker
+			if name == "" {
+				name = truncateString(token.TokenID, 12)
+			}
+			fmt.Printf("  %-22s  %s (no sats price available)\n", name+":", amount)
+		}
+	}
+
+	fmt.Printf("Total Net Worth:          %d sats (excluding tokens)\n", breakdown.TotalSats)
+}
+
+func showTransactions(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("transactions", flag.ExitOnError)
+	dedup := fs.Bool("dedup", false, "Remove duplicate entries with the same payment ID")
+	txType := fs.String("type", "", "Only show send, receive or probe transactions")
+	status := fs.String("status", "", "Only show Pending, Complete or Failed transactions")
+	amountAbove := fs.Int64("amount-above", -1, "Only show transactions of more than this many sats")
+	amountBelow := fs.Int64("amount-below", -1, "Only show transactions of less than this many sats")
+	groupByDay := fs.Bool("group-by-day", false, "Show daily totals instead of individual transactions")
+	expand := fs.Bool("expand", false, "List the transactions of each day below its totals (with --group-by-day)")
+	excludeSelf := fs.Bool("exclude-self", false, "Hide payments from the wallet to its own invoices")
+	onlySelf := fs.Bool("only-self", false, "Only show payments from the wallet to its own invoices")
+	paymentHash := fs.String("payment-hash", "", "Show the transaction with this payment hash or hash prefix")
+	args = parseArgs(fs, args)
+
+	if *paymentHash != "" {
+		tx, err := w.FindPaymentByHash(ctx, *paymentHash)
+		if err != nil {
+			log.Fatalf("Failed to find transaction: %v", err)
+		}
+		fmt.Printf("Payment Details:\n")
+		printTransaction(tx)
+		return
+	}
+
+	if *excludeSelf && *onlySelf {
+		log.Fatalf("--exclude-self and --only-self can't be used together")
+	}
+
+	opts := wallet.ListTransactionsOptions{Type: *txType, Status: *status}
+	if *amountAbove >= 0 {
+		opts.AmountAboveSats = amountAbove
+	}
+	if *amountBelow >= 0 {
+		opts.AmountBelowSats = amountBelow
+	}
+
+	limit := 10
+	if len(args) > 0 {
+		if l, err := strconv.Atoi(args[0]); err == nil {
+			limit = l
+		}
+	}
+
 	fmt.Printf("Last %d Transactions:\n", limit)
 	fmt.Println(strings.Repeat("-", 20))
 
@@ -132,12 +648,76 @@ func showTransactions(ctx context.Context, w *wallet.Wallet, limit int) {
 	if err != nil {
 		log.Fatalf("Failed to get transactions: %v", err)
 	}
+	if *dedup {
+		transactions = wallet.DeduplicateTransactions(transactions)
+	}
+	transactions = wallet.FilterTransactions(transactions, opts)
+	if *excludeSelf || *onlySelf {
+		localInvoices, err := w.LocalInvoiceHashes()
+		if err != nil {
+			log.Fatalf("Failed to load local invoices: %v", err)
+		}
+		self, others := wallet.SplitSelfPayments(transactions, localInvoices)
+		if *onlySelf {
+			transactions = self
+		} else {
+			transactions = others
+		}
+	}
 
 	if len(transactions) == 0 {
 		fmt.Println("No transactions found")
 		return
 	}
 
+	if *groupByDay {
+		printDayGroups(wallet.GroupByDay(transactions), *expand)
+		return
+	}
+
+	printTransactionTable(transactions, func(description string) string {
+		return truncateString(description, 20)
+	})
+}
+
+// printDayGroups prints the daily totals of transactions, with expand followed
+// by the day's transactions indented below each row
+func printDayGroups(groups []wallet.DayGroup, expand bool) {
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "DATE\tRECEIVED\tRECEIVED SATS\tSENT\tSENT SATS\tFEES\tNET")
+	fmt.Fprintln(tabWriter, "----\t--------\t-------------\t----\t---------\t----\t---")
+
+	for _, group := range groups {
+		fmt.Fprintf(tabWriter, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			group.Date.Format("2006-01-02"), group.ReceivedCount, group.ReceivedSats,
+			group.SentCount, group.SentSats, group.FeesSats, formatAmount(group.NetSats, false))
+
+		if !expand {
+			continue
+		}
+		// Each transaction's amount goes under the received or sent column and
+		// its status and description under the net column
+		for _, tx := range group.Transactions {
+			received, sent := "", ""
+			if tx.Type == "send" {
+				sent = formatAmount(tx.AmountSats, true)
+			} else {
+				received = formatAmount(tx.AmountSats, false)
+			}
+			description := truncateString(tx.DisplayDescription(), 20)
+			if description == "" {
+				description = "-"
+			}
+			fmt.Fprintf(tabWriter, "  %s\t\t%s\t\t%s\t%d\t%s %s\n",
+				tx.Timestamp.Format("15:04"), received, sent, tx.FeeSats, tx.Status, description)
+		}
+	}
+	tabWriter.Flush()
+}
+
+// printTransactionTable prints transactions as a table, formatting each
+// description with describe
+func printTransactionTable(transactions []*wallet.Transaction, describe func(string) string) {
 	// Use tabwriter for nice formatting
 	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(tabWriter, "TIME\tTYPE\tAMOUNT\tFEE\tSTATUS\tDESCRIPTION")
@@ -145,9 +725,9 @@ func showTransactions(ctx context.Context, w *wallet.Wallet, limit int) {
 
 	for _, tx := range transactions {
 		timestamp := tx.Timestamp.Format("2006-01-02 15:04")
-		amountStr := formatAmount(tx.AmountSats)
-		feeStr := formatAmount(tx.FeeSats)
-		description := truncateString(tx.Description, 20)
+		amountStr := formatAmount(tx.AmountSats, tx.Type == "send")
+		feeStr := formatAmount(tx.FeeSats, false)
+		description := describe(tx.DisplayDescription())
 		if description == "" {
 			description = "-"
 		}
@@ -158,23 +738,121 @@ func showTransactions(ctx context.Context, w *wallet.Wallet, limit int) {
 	tabWriter.Flush()
 }
 
-func receivePayment(ctx context.Context, w *wallet.Wallet, paymentType, amountStr, description string) {
-	amount, err := strconv.ParseUint(amountStr, 10, 64)
-	if err != nil {
-		log.Fatalf("Invalid amount: %v", err)
+func receivePayment(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("receive", flag.ExitOnError)
+	amountBTC := fs.String("amount-btc", "", "Amount in BTC (bitcoin only)")
+	static := fs.Bool("static", false, "Show the reusable Spark address (spark only)")
+	refresh := fs.Bool("refresh", false, "Fetch the Spark address again instead of using the cache (spark only)")
+	descHash := fs.String("desc-hash", "", "Hex SHA256 of the description to commit to instead of the description (lightning only)")
+	watch := fs.Bool("watch", false, "Wait for an on-chain deposit to the address (bitcoin only)")
+	timeout := fs.Int("timeout", 3600, "Seconds to wait for a deposit with --watch")
+	webhook := fs.String("webhook", "", "POST the payment as JSON to this URL once the invoice is paid (lightning only)")
+	wait := fs.Bool("wait", false, "Wait until the invoice is paid or expires (lightning only)")
+	qr := fs.Bool("qr", false, "Also print the LNURL as a QR code (lnurl only)")
+	host := fs.String("host", "localhost", "Host or IP payers reach this machine at (lnurl only)")
+	autoAcceptProbing := fs.Bool("auto-accept-probing", false, "Accept receives below BREEZ_MIN_RECEIVE_SATS silently while waiting (lightning only)")
+	args = parseArgs(fs, args)
+
+	if *webhook != "" {
+		if parsed, err := url.Parse(*webhook); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			log.Fatalf("Invalid webhook URL: %s", *webhook)
+		}
+	}
+
+	if len(args) < 1 {
+		printReceiveUsage()
+		return
+	}
+	paymentType := strings.ToLower(args[0])
+	args = args[1:]
+
+	if paymentType == "lnurl" {
+		receiveLnurl(ctx, w, args, *host, *qr)
+		return
+	}
+
+	if (*webhook != "" || *wait) && paymentType != "lightning" && paymentType != "ln" {
+		log.Fatalf("--webhook and --wait are only supported for lightning receives")
+	}
+	if *autoAcceptProbing {
+		if paymentType != "lightning" && paymentType != "ln" {
+			log.Fatalf("--auto-accept-probing is only supported for lightning receives")
+		}
+		w.SetAutoAcceptProbing(true)
+	}
+
+	if *refresh {
+		if paymentType != "spark" {
+			log.Fatalf("--refresh is only supported for spark receives")
+		}
+		if err := w.ClearSparkAddressCache(); err != nil {
+			log.Fatalf("Failed to refresh spark address: %v", err)
+		}
+	}
+
+	if *watch && paymentType != "bitcoin" && paymentType != "btc" {
+		log.Fatalf("--watch is only supported for bitcoin receives")
+	}
+
+	if *static {
+		if paymentType != "spark" {
+			log.Fatalf("--static is only supported for spark receives")
+		}
+		receiveStaticSparkAddress(ctx, w)
+		return
 	}
 
+	var amount uint64
+	var err error
+	switch {
+	case *amountBTC != "":
+		if paymentType != "bitcoin" && paymentType != "btc" {
+			log.Fatalf("--amount-btc is only supported for bitcoin receives")
+		}
+		amount, err = uri.BTCToSats(*amountBTC)
+		if err != nil {
+			log.Fatalf("Invalid amount: %v", err)
+		}
+	case len(args) > 0:
+		amount, err = strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid amount: %v", err)
+		}
+		args = args[1:]
+	case paymentType == "lightning" || paymentType == "ln":
+		printReceiveUsage()
+		return
+	}
+
+	description := strings.Join(args, " ")
+	if *descHash != "" {
+		if paymentType != "lightning" && paymentType != "ln" {
+			log.Fatalf("--desc-hash is only supported for lightning receives")
+		}
+		if description != "" {
+			log.Fatalf("A description and --desc-hash can't be used together")
+		}
+	}
+	label := description
 	if description == "" {
 		description = "Payment request"
 	}
 
 	var response *wallet.ReceivePaymentResponse
 
-	switch strings.ToLower(paymentType) {
+	switch paymentType {
 	case "lightning", "ln":
-		response, err = w.ReceiveLightningInvoice(ctx, amount, description)
+		if *descHash != "" {
+			response, err = w.ReceiveLightningInvoiceWithDescriptionHash(ctx, amount, *descHash)
+		} else {
+			response, err = w.ReceiveLightningInvoice(ctx, amount, description)
+		}
 	case "bitcoin", "btc":
-		response, err = w.ReceiveBitcoinAddress(ctx)
+		if amount > 0 {
+			response, err = w.ReceiveBitcoinAddressWithAmount(ctx, amount, label)
+		} else {
+			response, err = w.ReceiveBitcoinAddress(ctx)
+		}
 	case "spark":
 		response, err = w.ReceiveSparkAddress(ctx)
 	default:
@@ -192,93 +870,1014 @@ func receivePayment(ctx context.Context, w *wallet.Wallet, paymentType, amountSt
 	fmt.Printf("Description: %s\n", response.Description)
 	fmt.Printf("Expires:     %s\n", response.ExpiresAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("\nPayment Request:\n%s\n", response.PaymentRequest)
+
+	if *watch {
+		watchDeposit(ctx, w, *timeout)
+	}
+	switch {
+	case *wait:
+		waitInvoicePayment(ctx, w, response, *webhook)
+	case *webhook != "":
+		detachInvoiceWebhook(w, response, *webhook, *autoAcceptProbing)
+	}
 }
 
-func sendPayment(ctx context.Context, w *wallet.Wallet, paymentType, destination, amountStr string) {
-	var response *wallet.PaymentResponse
-	var err error
+// waitInvoicePayment waits for an invoice to be paid and POSTs the payment to
+// webhook if it is set. Expiry exits with code 2.
+func waitInvoicePayment(ctx context.Context, w *wallet.Wallet, invoice *wallet.ReceivePaymentResponse, webhook string) {
+	ctx, cancel := context.WithDeadline(ctx, invoice.ExpiresAt)
+	defer cancel()
 
-	switch strings.ToLower(paymentType) {
-	case "lightning", "ln":
-		response, err = w.SendLightningInvoice(ctx, destination)
-	case "bitcoin", "btc":
-		amount, err2 := strconv.ParseInt(amountStr, 10, 64)
-		if err2 != nil {
-			log.Fatalf("Invalid amount: %v", err2)
-		}
-		response, err = w.SendBitcoinAddress(ctx, destination, amount)
-	case "spark":
-		amount, err2 := strconv.ParseInt(amountStr, 10, 64)
-		if err2 != nil {
-			log.Fatalf("Invalid amount: %v", err2)
-		}
-		response, err = w.SendSparkAddress(ctx, destination, amount)
-	case "lnurl":
-		amount, err2 := strconv.ParseUint(amountStr, 10, 64)
-		if err2 != nil {
-			log.Fatalf("Invalid amount: %v", err2)
-		}
-		response, err = w.LnUrlPay(ctx, destination, amount, "Payment via LNURL")
-	default:
-		log.Fatalf("Unknown send type: %s", paymentType)
+	fmt.Printf("\nWaiting for the invoice to be paid (until %s)...\n", invoice.ExpiresAt.Format("2006-01-02 15:04:05"))
+	payment, err := w.WaitForInvoicePayment(ctx, invoice.PaymentRequest, wallet.InvoicePaymentInterval)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Println("The invoice expired without being paid")
+		// Exit code 2 lets scripts tell a timeout apart from an error
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Failed to wait for invoice payment: %v", err)
+	}
+
+	fmt.Printf("Received +%d sats\n", payment.AmountSats)
+	if webhook == "" {
+		return
+	}
+	// The original context may be past its deadline by now
+	if err := wallet.NotifyWebhook(context.Background(), webhook, payment); err != nil {
+		log.Fatalf("Failed to notify webhook: %v", err)
 	}
+	fmt.Printf("Notified %s\n", webhook)
+}
+
+// watchDeposit waits for an on-chain deposit, exiting with code 2 on timeout
+func watchDeposit(ctx context.Context, w *wallet.Wallet, timeout int) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
 
+	fmt.Printf("\nWaiting for on-chain deposit (timeout %ds)...\n", timeout)
+	deposit, err := w.WaitForOnchainDeposit(ctx, wallet.DepositWatchInterval)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Println("Timed out waiting for on-chain deposit")
+		// Exit code 2 lets scripts tell a timeout apart from an error
+		os.Exit(2)
+	}
 	if err != nil {
-		log.Fatalf("Failed to send %s payment: %v", paymentType, err)
+		log.Fatalf("Failed to wait for on-chain deposit: %v", err)
 	}
 
-	fmt.Printf("Payment Sent:\n")
-	fmt.Printf("Payment Hash: %s\n", response.PaymentHash)
-	fmt.Printf("Amount:       %d sats\n", response.AmountSats)
-	fmt.Printf("Fee:          %d sats\n", response.FeeSats)
-	fmt.Printf("Status:       %s\n", response.Status)
-	fmt.Printf("Completed:    %s\n", response.CompletedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Received +%d sats on-chain\n", deposit.AmountSats)
+	if deposit.Txid != "" {
+		fmt.Printf("TXID: %s\n", deposit.Txid)
+	}
+	if !deposit.Claimed {
+		fmt.Println("The deposit is confirmed and will be added to the balance once claimed")
+	}
 }
 
-func showPayment(ctx context.Context, w *wallet.Wallet, paymentID string) {
-	payment, err := w.GetPayment(ctx, paymentID)
+func receiveStaticSparkAddress(ctx context.Context, w *wallet.Wallet) {
+	address, err := w.GetStaticSparkAddress(ctx)
 	if err != nil {
-		log.Fatalf("Failed to get payment: %v", err)
+		log.Fatalf("Failed to get static spark address: %v", err)
 	}
 
-	fmt.Printf("Payment Details:\n")
-	fmt.Printf("ID:          %s\n", payment.ID)
-	fmt.Printf("Type:        %s\n", payment.Type)
-	fmt.Printf("Amount:      %s sats\n", formatAmount(payment.AmountSats))
-	fmt.Printf("Fee:         %s sats\n", formatAmount(payment.FeeSats))
-	fmt.Printf("Status:      %s\n", payment.Status)
-	fmt.Printf("Description: %s\n", payment.Description)
-	fmt.Printf("Time:        %s\n", payment.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Static Spark Address:\n%s\n", address)
+	fmt.Fprintln(os.Stderr, "\nNote: this address is reusable, so all payments to it can be linked to each other.")
+	fmt.Fprintln(os.Stderr, "Share a fresh payment request instead when payer privacy matters.")
 }
 
-func showTokens(ctx context.Context, w *wallet.Wallet) {
-	fmt.Println("Token Balances:")
-	fmt.Println("---------------")
+func printReceiveUsage() {
+	fmt.Println("Usage: tiny-client receive <type> <amount> [description]")
+	fmt.Println("       tiny-client receive bitcoin --amount-btc <btc> [description]")
+	fmt.Println("       tiny-client receive bitcoin --watch [--timeout 3600]")
+	fmt.Println("       tiny-client receive spark [--static] [--refresh]")
+	fmt.Println("       tiny-client receive lightning <amount> --desc-hash <sha256>")
+	fmt.Println("       tiny-client receive lightning <amount> [description] --webhook <url> [--wait] [--auto-accept-probing]")
+	fmt.Println("       tiny-client receive lnurl <min_sats> <max_sats> [description] [--host 192.168.1.10] [--qr]")
+	fmt.Println("Types: lightning, bitcoin, spark, lnurl")
+}
 
-	tokens, err := w.GetTokenBalances(ctx)
-	if err != nil {
-		log.Fatalf("Failed to get token balances: %v", err)
+func sendPayment(ctx context.Context, w *wallet.Wallet, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	tokenID := fs.String("token-id", "", "Token identifier for token sends")
+	human := fs.Bool("human", false, "Interpret the token amount as a decimal in whole tokens")
+	fromClipboard := fs.Bool("from-clipboard", false, "Read the lightning invoice from the clipboard")
+	split := fs.Int("split", 1, "Split a spark payment into this many sequential payments")
+	estimate := fs.Bool("estimate", false, "Show the estimated fee of a lightning payment and ask before paying")
+	rbf := fs.Bool("rbf", false, "Signal replace-by-fee on a bitcoin payment so it can be fee bumped")
+	comment := fs.String("comment", "", "Message for the recipient of a lightning or lnurl payment")
+	showRange := fs.Bool("range", false, "Show the amount range an lnurl service accepts without paying")
+	opReturn := fs.String("op-return", "", "Hex data of at most 80 bytes to embed in an OP_RETURN output (bitcoin only)")
+	memo := fs.String("memo", "", "Note kept locally for a lightning payment (spark memos are not supported yet)")
+	confirmLarge := fs.Bool("confirm-large", false, "Send amounts above BREEZ_WARN_ABOVE_SATS without asking")
+	nonInteractive := fs.Bool("non-interactive", false, "Never prompt; large amounts then need --confirm-large")
+	maxFeePercent := fs.Float64("max-fee-percent", 0, "Refuse an lnurl payment whose fee is above this percentage of the amount")
+	maxFeeSats := fs.Int64("max-fee-sats", 0, "Refuse an lnurl payment whose fee is above this many sats")
+	batchEstimate := fs.Bool("batch-estimate", false, "Estimate the total fee of sending to every recipient in --from-file (bitcoin only)")
+	fromFile := fs.String("from-file", "", "CSV file of address,amount_sats recipients for --batch-estimate")
+	consolidate := fs.Bool("consolidate-utxos", false, "Prefer spending many small inputs in a bitcoin payment (not supported yet)")
+	preimageFile := fs.String("preimage-file", "", "JSONL file of invoice, preimage and amount_sats payments to send in turn (lightning only)")
+	output := fs.String("output", "", "JSONL file to write the --preimage-file results to")
+	args = parseArgs(fs, args)
+
+	if *batchEstimate {
+		if len(args) < 1 || (strings.ToLower(args[0]) != "bitcoin" && strings.ToLower(args[0]) != "btc") {
+			log.Fatalf("--batch-estimate is only supported for bitcoin sends")
+		}
+		if *fromFile == "" {
+			log.Fatalf("--batch-estimate requires --from-file")
+		}
+		estimateBatchFees(ctx, w, *fromFile)
+		return
 	}
 
-	if len(tokens) == 0 {
-		fmt.Println("No tokens found")
+	if *preimageFile != "" {
+		if len(args) < 1 || (strings.ToLower(args[0]) != "lightning" && strings.ToLower(args[0]) != "ln") {
+			log.Fatalf("--preimage-file is only supported for lightning sends")
+		}
+		if *output == "" {
+			log.Fatalf("--preimage-file requires --output")
+		}
+		sendPreimageBatch(ctx, w, *preimageFile, *output, int64(cfg.BreezWarnAboveSats), *confirmLarge, *nonInteractive)
 		return
 	}
 
-	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(tabWriter, "TOKEN ID\tNAME\tTICKER\tBALANCE")
-	fmt.Fprintln(tabWriter, "---------\t----\t------\t-------")
+	if *fromClipboard && len(args) > 0 {
+		if paymentType := strings.ToLower(args[0]); paymentType != "lightning" && paymentType != "ln" {
+			log.Fatalf("--from-clipboard is only supported for lightning sends")
+		}
+		invoice, err := readClipboardInvoice()
+		if err != nil {
+			log.Fatalf("Failed to read invoice: %v", err)
+		}
+		fmt.Printf("Paying invoice from clipboard: %s\n", truncateString(invoice, 40))
+		args = append([]string{args[0], invoice}, args[1:]...)
+	}
 
-	for _, token := range tokens {
-		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n",
+	if len(args) < 2 {
+		fmt.Println("Usage: tiny-client send <type> <destination> <amount>")
+		fmt.Println("       tiny-client send lightning --from-clipboard")
+		fmt.Println("       tiny-client send spark <address> <amount> --split N")
+		fmt.Println("Types: lightning, bitcoin, spark, lnurl, token")
+		return
+	}
+
+	paymentType, destination := args[0], args[1]
+	amountStr := ""
+	if len(args) > 2 {
+		amountStr = args[2]
+	}
+
+	if strings.ToLower(paymentType) == "token" {
+		sendToken(ctx, w, destination, amountStr, *tokenID, *human)
+		return
+	}
+	checkedAmount := amountStr
+	if pt := strings.ToLower(paymentType); checkedAmount == "" && (pt == "lightning" || pt == "ln") {
+		// Invoices usually carry the amount themselves
+		if sats, ok := wallet.InvoiceAmountSats(destination); ok {
+			checkedAmount = strconv.FormatInt(sats, 10)
+		}
+	}
+	if !checkSendAmount(checkedAmount, int64(cfg.BreezWarnAboveSats), *confirmLarge, *nonInteractive) {
+		fmt.Println("Payment cancelled")
+		return
+	}
+	if pt := strings.ToLower(paymentType); *memo != "" && pt != "spark" && pt != "lightning" && pt != "ln" {
+		log.Fatalf("--memo is only supported for lightning and spark sends")
+	}
+	if (*maxFeePercent != 0 || *maxFeeSats != 0) && strings.ToLower(paymentType) != "lnurl" {
+		log.Fatalf("--max-fee-percent and --max-fee-sats are only supported for lnurl sends")
+	}
+	if pt := strings.ToLower(paymentType); *consolidate && pt != "bitcoin" && pt != "btc" {
+		log.Fatalf("--consolidate-utxos is only supported for bitcoin sends")
+	}
+	if *maxFeePercent < 0 || *maxFeeSats < 0 {
+		log.Fatalf("Fee limits must not be negative")
+	}
+	if *split != 1 {
+		if strings.ToLower(paymentType) != "spark" {
+			log.Fatalf("--split is only supported for spark sends")
+		}
+		sendSparkSplit(ctx, w, destination, amountStr, *split)
+		return
+	}
+
+	var response *wallet.PaymentResponse
+	var err error
+
+	switch strings.ToLower(paymentType) {
+	case "lightning", "ln":
+		if *estimate && !confirmLightningFee(ctx, w, destination) {
+			fmt.Println("Payment cancelled")
+			return
+		}
+		response, err = w.SendLightningInvoiceWithComment(ctx, destination, *comment)
+		// The payment went through, so a memo that can't be saved only warrants a warning
+		if err == nil && *memo != "" {
+			if err := w.SetPaymentMemo(response.PaymentHash, *memo); err != nil {
+				slog.Warn("Failed to save payment memo", "payment_hash", response.PaymentHash, "error", err)
+			}
+		}
+	case "bitcoin", "btc":
+		amount, err2 := strconv.ParseInt(amountStr, 10, 64)
+		if err2 != nil {
+			log.Fatalf("Invalid amount: %v", err2)
+		}
+		// Refuse rather than send a transaction that can't be bumped later
+		if *rbf {
+			log.Fatalf("--rbf: replace-by-fee signaling is %v", wallet.ErrNotSupported)
+		}
+		switch {
+		case *opReturn != "" && *consolidate:
+			log.Fatalf("--op-return and --consolidate-utxos can't be used together")
+		case *opReturn != "":
+			response, err = w.SendBitcoinAddressWithMemo(ctx, destination, amount, *opReturn, wallet.ConfirmationSpeedMedium)
+		case *consolidate:
+			response, err = w.SendBitcoinAddressConsolidating(ctx, destination, amount, wallet.ConfirmationSpeedMedium)
+		default:
+			response, err = w.SendBitcoinAddress(ctx, destination, amount)
+		}
+	case "spark":
+		amount, err2 := strconv.ParseInt(amountStr, 10, 64)
+		if err2 != nil {
+			log.Fatalf("Invalid amount: %v", err2)
+		}
+		if *memo != "" {
+			response, err = w.SendSparkAddressWithMemo(ctx, destination, amount, *memo)
+		} else {
+			response, err = w.SendSparkAddress(ctx, destination, amount)
+		}
+	case "lnurl":
+		if *showRange {
+			showLnurlRange(ctx, w, destination)
+			return
+		}
+		// Invoices passed by mistake carry their own amount
+		var amount uint64
+		if amountStr != "" {
+			var err2 error
+			amount, err2 = strconv.ParseUint(amountStr, 10, 64)
+			if err2 != nil {
+				log.Fatalf("Invalid amount: %v", err2)
+			}
+		}
+		lnurlComment := *comment
+		if lnurlComment == "" {
+			lnurlComment = "Payment via LNURL"
+		}
+		limit := wallet.FeeLimit{MaxFeePercent: *maxFeePercent, MaxFeeSats: *maxFeeSats}
+		response, err = w.LnUrlPayWithFeeLimit(ctx, destination, amount, lnurlComment, limit)
+		var below *wallet.ErrAmountBelowMinimum
+		var above *wallet.ErrAmountAboveMaximum
+		switch {
+		case errors.Is(err, wallet.ErrFeeTooHigh):
+			log.Fatalf("Payment not sent: %v", err)
+		case errors.As(err, &below):
+			log.Fatalf("Service accepts %s sats; you requested %s sats.", formatRange(below.LnurlPayRange), groupDigits(amount))
+		case errors.As(err, &above):
+			log.Fatalf("Service accepts %s sats; you requested %s sats.", formatRange(above.LnurlPayRange), groupDigits(amount))
+		}
+	default:
+		log.Fatalf("Unknown send type: %s", paymentType)
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to send %s payment: %v", paymentType, err)
+	}
+
+	fmt.Printf("Payment Sent:\n")
+	fmt.Printf("Payment Hash: %s\n", response.PaymentHash)
+	fmt.Printf("Amount:       %d sats\n", response.AmountSats)
+	fmt.Printf("Fee:          %d sats\n", response.FeeSats)
+	fmt.Printf("Status:       %s\n", response.Status)
+	fmt.Printf("Completed:    %s\n", response.CompletedAt.Format("2006-01-02 15:04:05"))
+}
+
+// checkSendAmount guards against mistyped amounts. It warns about amounts that
+// look like they are in the wrong unit, and amounts above warnAbove need
+// confirmLarge or a yes at the prompt. Without an amount there's nothing to
+// check, since invoices carry their own.
+func checkSendAmount(amountStr string, warnAbove int64, confirmLarge, nonInteractive bool) bool {
+	amount, err := strconv.ParseInt(amountStr, 10, 64)
+	if err != nil || amount <= 0 {
+		return true
+	}
+
+	switch {
+	case amount < 10:
+		slog.Warn("Amounts are in sats, check this isn't meant to be thousands of sats", "amount_sats", amount)
+	case amount%100_000_000 == 0:
+		slog.Warn("Amounts are in sats, not BTC", "amount_sats", amount, "btc", amount/100_000_000)
+	}
+
+	if amount <= warnAbove {
+		return true
+	}
+	if nonInteractive || confirmLarge {
+		slog.Warn("Sending a large amount", "amount_sats", amount, "warn_above_sats", warnAbove)
+		if !confirmLarge {
+			log.Fatalf("Refusing to send %s sats without --confirm-large (above BREEZ_WARN_ABOVE_SATS=%s)",
+				groupDigits(uint64(amount)), groupDigits(uint64(warnAbove)))
+		}
+		return true
+	}
+
+	fmt.Fprintln(os.Stderr, "!!! WARNING: LARGE PAYMENT !!!")
+	fmt.Fprintf(os.Stderr, "You are about to send %s sats (%s BTC), more than %s sats.\n",
+		groupDigits(uint64(amount)), uri.FormatBTCAmount(uint64(amount), false), groupDigits(uint64(warnAbove)))
+	fmt.Fprintln(os.Stderr, "Pass --confirm-large to skip this question.")
+	return askYesNo(bufio.NewReader(os.Stdin), "Send anyway?")
+}
+
+// showLnurlRange prints the amount range an LNURL-pay service accepts
+func showLnurlRange(ctx context.Context, w *wallet.Wallet, lnurlAddress string) {
+	payRange, err := w.GetLnurlPayRange(ctx, lnurlAddress)
+	if err != nil {
+		log.Fatalf("Failed to get lnurl amount range: %v", err)
+	}
+	fmt.Printf("Service accepts %s sats\n", formatRange(*payRange))
+}
+
+// formatRange formats an LNURL-pay range such as 100–100,000
+func formatRange(payRange wallet.LnurlPayRange) string {
+	return groupDigits(payRange.MinSats) + "–" + groupDigits(payRange.MaxSats)
+}
+
+// groupDigits formats n with comma separated thousands
+func groupDigits(n uint64) string {
+	digits := strconv.FormatUint(n, 10)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+func bumpFee(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("bump-fee", flag.ExitOnError)
+	feeRate := fs.Int64("fee-rate", 0, "New fee rate in sat/vbyte")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 || *feeRate == 0 {
+		fmt.Println("Usage: tiny-client bump-fee <txid> --fee-rate <sat/vbyte>")
+		fmt.Println("The transaction must have been sent with send bitcoin --rbf")
+		return
+	}
+
+	response, err := w.BumpFee(ctx, args[0], *feeRate)
+	if err != nil {
+		log.Fatalf("Failed to bump fee: %v", err)
+	}
+
+	fmt.Printf("Replacement Sent:\n")
+	fmt.Printf("Payment Hash: %s\n", response.PaymentHash)
+	fmt.Printf("Fee:          %d sats\n", response.FeeSats)
+	fmt.Printf("Status:       %s\n", response.Status)
+}
+
+// confirmLightningFee shows the estimated fee of paying an invoice and asks
+// whether to go ahead
+func confirmLightningFee(ctx context.Context, w *wallet.Wallet, invoice string) bool {
+	estimate, err := w.EstimateLightningFee(ctx, invoice, nil)
+	if err != nil {
+		log.Fatalf("Failed to estimate fee: %v", err)
+	}
+
+	fee := strconv.FormatInt(estimate.FeeSats, 10)
+	if estimate.Confidence != wallet.FeeConfidenceExact {
+		fee = "~" + fee
+	}
+	fmt.Printf("Amount:       %d sats\n", estimate.AmountSats)
+	return askYesNo(bufio.NewReader(os.Stdin), fmt.Sprintf("Estimated fee: %s sats. Proceed?", fee))
+}
+
+// pay sends to a destination of any kind, detecting whether it is an
+// invoice, an address or an LNURL. The remaining arguments and flags are
+// those of the matching send command.
+func pay(ctx context.Context, w *wallet.Wallet, cfg *config.Config, args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		fmt.Println("Usage: tiny-client pay <destination> [amount] [send flags]")
+		return
+	}
+
+	hint, err := w.DetectInputType(ctx, args[0])
+	if err != nil {
+		log.Fatalf("Failed to detect destination type: %v", err)
+	}
+
+	var sendType string
+	switch hint {
+	case detect.Bolt11:
+		sendType = "lightning"
+	case detect.BitcoinAddress:
+		sendType = "bitcoin"
+	case detect.SparkAddress:
+		sendType = "spark"
+	case detect.LightningAddress, detect.LNURL, detect.BIP21:
+		// LnUrlPay pays BIP21 URIs with the best method they offer
+		sendType = "lnurl"
+	default:
+		log.Fatalf("Paying a %s is not supported", hint)
+	}
+
+	fmt.Printf("Detected %s\n", hint)
+	sendPayment(ctx, w, cfg, append([]string{sendType}, args...))
+}
+
+// estimateBatchFees sums the on-chain fee of sending to each recipient of a
+// CSV file as a separate payment, by confirmation speed. Nothing is sent.
+func estimateBatchFees(ctx context.Context, w *wallet.Wallet, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open recipients: %v", err)
+	}
+	defer file.Close()
+
+	recipients, err := readRecipientsCSV(file)
+	if err != nil {
+		log.Fatalf("Failed to read recipients: %v", err)
+	}
+	if len(recipients) == 0 {
+		log.Fatalf("No recipients in %s", path)
+	}
+
+	var total wallet.OnchainFeeQuote
+	for _, recipient := range recipients {
+		quote, err := w.EstimateBitcoinFee(ctx, recipient.Address, recipient.AmountSats)
+		if err != nil {
+			log.Fatalf("Failed to estimate fee for %s: %v", recipient.Address, err)
+		}
+		total.AmountSats += quote.AmountSats
+		total.FastSats += quote.FastSats
+		total.MediumSats += quote.MediumSats
+		total.SlowSats += quote.SlowSats
+	}
+
+	fmt.Printf("Estimated fees for %d separate payments of %s sats in total:\n",
+		len(recipients), groupDigits(uint64(total.AmountSats)))
+
+	// The SDK quotes fees without the transaction it would build, so the
+	// size isn't known
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "SPEED\tESTIMATED TOTAL FEE SATS\tESTIMATED TX SIZE BYTES\tTIME TO CONFIRM")
+	fmt.Fprintln(tabWriter, "-----\t------------------------\t-----------------------\t---------------")
+	fmt.Fprintf(tabWriter, "fast\t%d\t-\t~10 minutes\n", total.FastSats)
+	fmt.Fprintf(tabWriter, "medium\t%d\t-\t~30 minutes\n", total.MediumSats)
+	fmt.Fprintf(tabWriter, "slow\t%d\t-\t~1 hour\n", total.SlowSats)
+	tabWriter.Flush()
+
+	fmt.Println()
+	fmt.Println("Estimate only, no payments were sent.")
+}
+
+// recipient is an on-chain payment listed in a recipients CSV file
+type recipient struct {
+	Address    string
+	AmountSats int64
+}
+
+// readRecipientsCSV reads address,amount_sats lines, skipping a header line
+func readRecipientsCSV(in io.Reader) ([]recipient, error) {
+	records, err := csv.NewReader(in).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []recipient
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected address and amount_sats", i+1)
+		}
+		amount, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("line %d: invalid amount %q", i+1, record[1])
+		}
+		recipients = append(recipients, recipient{Address: strings.TrimSpace(record[0]), AmountSats: amount})
+	}
+	return recipients, nil
+}
+
+func sendSparkSplit(ctx context.Context, w *wallet.Wallet, destination, amountStr string, parts int) {
+	total, err := strconv.ParseInt(amountStr, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid amount: %v", err)
+	}
+
+	responses, err := w.SendSparkAddressSplit(ctx, destination, total, parts)
+
+	var sent, fees int64
+	for i, response := range responses {
+		fmt.Printf("Part %d/%d: %d sats, fee %d sats, %s (%s)\n",
+			i+1, parts, response.AmountSats, response.FeeSats, response.Status, response.PaymentHash)
+		sent += response.AmountSats
+		fees += response.FeeSats
+	}
+
+	fmt.Println()
+	fmt.Printf("Sent:         %d of %d sats in %d/%d payments\n", sent, total, len(responses), parts)
+	fmt.Printf("Total Fee:    %d sats\n", fees)
+	if err != nil {
+		fmt.Printf("Not Sent:     %d sats\n", total-sent)
+		log.Fatalf("Failed to send spark payment: %v", err)
+	}
+}
+
+func sendToken(ctx context.Context, w *wallet.Wallet, destination, amountStr, tokenID string, human bool) {
+	if tokenID == "" {
+		log.Fatalf("--token-id is required for token sends")
+	}
+
+	var amount *big.Int
+	if human {
+		decimals, err := w.GetTokenDecimals(ctx, tokenID)
+		if err != nil {
+			log.Fatalf("Failed to get token decimals: %v", err)
+		}
+		amount, err = wallet.ParseTokenAmount(amountStr, decimals)
+		if err != nil {
+			log.Fatalf("Invalid amount: %v", err)
+		}
+		if amount.Sign() <= 0 {
+			log.Fatalf("Invalid amount: %q", amountStr)
+		}
+	} else {
+		var ok bool
+		amount, ok = new(big.Int).SetString(amountStr, 10)
+		if !ok || amount.Sign() <= 0 {
+			log.Fatalf("Invalid amount: %q", amountStr)
+		}
+	}
+
+	response, err := w.SendToken(ctx, destination, tokenID, amount)
+	if err != nil {
+		log.Fatalf("Failed to send token payment: %v", err)
+	}
+
+	fmt.Printf("Token Payment Sent:\n")
+	fmt.Printf("Payment ID:   %s\n", response.PaymentHash)
+	fmt.Printf("Token ID:     %s\n", tokenID)
+	fmt.Printf("Amount:       %s base units\n", amount.String())
+	fmt.Printf("Fee:          %d\n", response.FeeSats)
+	fmt.Printf("Status:       %s\n", response.Status)
+	fmt.Printf("Completed:    %s\n", response.CompletedAt.Format("2006-01-02 15:04:05"))
+}
+
+func showPayment(ctx context.Context, w *wallet.Wallet, paymentID string) {
+	payment, err := w.GetPayment(ctx, paymentID)
+	if err != nil {
+		log.Fatalf("Failed to get payment: %v", err)
+	}
+
+	fmt.Printf("Payment Details:\n")
+	printTransaction(payment)
+}
+
+// printTransaction prints the fields of a single transaction
+func printTransaction(tx *wallet.Transaction) {
+	fmt.Printf("ID:          %s\n", tx.ID)
+	fmt.Printf("Type:        %s\n", tx.Type)
+	fmt.Printf("Amount:      %s sats\n", formatAmount(tx.AmountSats, tx.Type == "send"))
+	fmt.Printf("Fee:         %s sats\n", formatAmount(tx.FeeSats, false))
+	fmt.Printf("Status:      %s\n", tx.Status)
+	if tx.PaymentHash != "" && tx.PaymentHash != tx.ID {
+		fmt.Printf("Hash:        %s\n", tx.PaymentHash)
+	}
+	fmt.Printf("Description: %s\n", tx.Description)
+	fmt.Printf("Time:        %s\n", tx.Timestamp.Format("2006-01-02 15:04:05"))
+	if tx.Memo != "" {
+		fmt.Printf("Note:        %s\n", tx.Memo)
+	}
+}
+
+func waitReceive(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("wait-receive", flag.ExitOnError)
+	timeout := fs.Int("timeout", 300, "Seconds to wait for an incoming payment")
+	minAmount := fs.Int64("min-amount-sats", 1, "Minimum amount of the incoming payment")
+	parseArgs(fs, args)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(*timeout)*time.Second)
+	defer cancel()
+
+	fmt.Printf("Waiting for incoming payment (timeout %ds)...\n", *timeout)
+	tx, err := w.WaitForIncomingPayment(ctx, *minAmount, 3*time.Second)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Println("Timed out waiting for incoming payment")
+		// Exit code 2 lets scripts tell a timeout apart from an error
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Failed to wait for incoming payment: %v", err)
+	}
+
+	fmt.Printf("Payment Received:\n")
+	printTransaction(tx)
+}
+
+func showTokens(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("tokens", flag.ExitOnError)
+	showZero := fs.Bool("show-zero", false, "Include tokens with a zero balance")
+	hideZero := fs.Bool("hide-zero", false, "Hide tokens with a zero balance (default)")
+	parseArgs(fs, args)
+
+	if *showZero && *hideZero {
+		log.Fatalf("--show-zero and --hide-zero can't be used together")
+	}
+
+	fmt.Println("Token Balances:")
+	fmt.Println("---------------")
+
+	tokens, err := w.GetTokenBalances(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get token balances: %v", err)
+	}
+
+	tokens, hidden := hideZeroTokens(tokens, *showZero)
+
+	if len(tokens) == 0 {
+		fmt.Println("No tokens found")
+		if hidden > 0 {
+			fmt.Printf("(%d zero-balance tokens hidden; use --show-zero to list them)\n", hidden)
+		}
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "TOKEN ID\tNAME\tTICKER\tBALANCE")
+	fmt.Fprintln(tabWriter, "---------\t----\t------\t-------")
+
+	for _, token := range tokens {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n",
 			token.TokenID, token.Name, token.Ticker, token.Balance)
 	}
 	tabWriter.Flush()
+
+	if hidden > 0 {
+		fmt.Printf("\n(%d zero-balance tokens hidden; use --show-zero to list them)\n", hidden)
+	}
+}
+
+// hideZeroTokens drops tokens with a zero balance unless showZero is set,
+// returning the rest and how many were dropped
+func hideZeroTokens(tokens []*wallet.TokenBalance, showZero bool) ([]*wallet.TokenBalance, int) {
+	if showZero {
+		return tokens, 0
+	}
+	var kept []*wallet.TokenBalance
+	for _, token := range tokens {
+		if balance, ok := new(big.Int).SetString(token.Balance, 10); ok && balance.Sign() == 0 {
+			continue
+		}
+		kept = append(kept, token)
+	}
+	return kept, len(tokens) - len(kept)
+}
+
+func bip85Command(cfg *config.Config, args []string) {
+	if len(args) < 1 || args[0] != "derive" {
+		fmt.Println("Usage: tiny-client bip85 derive --index <n> [--words 12|24]")
+		return
+	}
+
+	fs := flag.NewFlagSet("bip85 derive", flag.ExitOnError)
+	index := fs.Uint("index", 0, "Child index to derive")
+	words := fs.Uint("words", 12, "Number of words in the child mnemonic (12, 18 or 24)")
+	parseArgs(fs, args[1:])
+
+	// Converting larger values would silently derive another index
+	if *index > math.MaxUint32 || *words > math.MaxUint32 {
+		log.Fatalf("--index and --words must be at most %d", uint64(math.MaxUint32))
+	}
+	mnemonic, err := bip85.DeriveMnemonic(cfg.BreezMnemonic, uint32(*words), uint32(*index))
+	if err != nil {
+		log.Fatalf("Failed to derive child mnemonic: %v", err)
+	}
+
+	// The warning goes to stderr so stdout only carries the mnemonic
+	fmt.Fprintf(os.Stderr, "WARNING: Anyone with the master mnemonic can derive this child mnemonic.\n")
+	fmt.Fprintf(os.Stderr, "It is not stored anywhere; write it down now. (path m/83696968'/39'/0'/%d'/%d')\n\n", *words, *index)
+	fmt.Println(mnemonic)
 }
 
-// formatAmount formats satoshi amount with proper sign
-func formatAmount(sats int64) string {
+// nodeCommand runs the node commands that need the wallet; node blacklist is
+// handled before connecting
+func nodeCommand(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "connect":
+			nodeConnect(ctx, w, args[1:])
+			return
+		case "disconnect":
+			nodeDisconnect(ctx, w, args[1:])
+			return
+		case "peers":
+			nodePeers(ctx, w)
+			return
+		}
+	}
+	if len(args) < 2 || args[0] != "info" {
+		printNodeUsage()
+		return
+	}
+
+	info, err := w.GetNodePeerInfo(ctx, args[1])
+	if err != nil {
+		log.Fatalf("Failed to get node info: %v", err)
+	}
+
+	alias := info.Alias
+	if alias == "" {
+		alias = "-"
+	}
+	fmt.Println("Node Info:")
+	fmt.Printf("Pubkey:    %s\n", info.Pubkey)
+	fmt.Printf("Alias:     %s\n", alias)
+	if info.Color != "" {
+		fmt.Printf("Color:     %s\n", info.Color)
+	}
+	fmt.Printf("Capacity:  %d sats\n", info.CapacitySats)
+	fmt.Printf("Channels:  %d\n", info.ChannelCount)
+	if len(info.Addresses) == 0 {
+		fmt.Println("Addresses: -")
+	}
+	for i, address := range info.Addresses {
+		label := ""
+		if i == 0 {
+			label = "Addresses:"
+		}
+		fmt.Printf("%-10s %s\n", label, address)
+	}
+	fmt.Printf("Source:    %s\n", info.Source)
+}
+
+func printNodeUsage() {
+	fmt.Println("Usage: tiny-client node info <pubkey>")
+	fmt.Println("       tiny-client node connect <pubkey>@<host>:<port>")
+	fmt.Println("       tiny-client node disconnect <pubkey>")
+	fmt.Println("       tiny-client node peers")
+	fmt.Println("       tiny-client node blacklist add|remove <pubkey>, node blacklist list")
+}
+
+func nodeConnect(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		printNodeUsage()
+		return
+	}
+
+	pubkey, address, ok := strings.Cut(args[0], "@")
+	if !ok {
+		log.Fatalf("Invalid peer %q, expected <pubkey>@<host>:<port>", args[0])
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		log.Fatalf("Invalid peer address: %v", err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		log.Fatalf("Invalid peer port: %q", portStr)
+	}
+
+	if err := w.ConnectPeer(ctx, pubkey, host, uint16(port)); err != nil {
+		log.Fatalf("Failed to connect to peer: %v", err)
+	}
+	fmt.Printf("Connected to %s\n", args[0])
+}
+
+func nodeDisconnect(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		printNodeUsage()
+		return
+	}
+
+	if err := w.DisconnectPeer(ctx, args[0]); err != nil {
+		log.Fatalf("Failed to disconnect from peer: %v", err)
+	}
+	fmt.Printf("Disconnected from %s\n", args[0])
+}
+
+func nodePeers(ctx context.Context, w *wallet.Wallet) {
+	peers, err := w.ListPeers(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list peers: %v", err)
+	}
+
+	if len(peers) == 0 {
+		fmt.Println("No connected peers")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "PUBKEY\tALIAS\tCONNECTED SINCE")
+	fmt.Fprintln(tabWriter, "------\t-----\t---------------")
+	for _, peer := range peers {
+		alias := peer.Alias
+		if alias == "" {
+			alias = "-"
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", peer.Pubkey, alias, peer.ConnectedSince.Format("2006-01-02 15:04:05"))
+	}
+	tabWriter.Flush()
+}
+
+func nodeBlacklist(cfg *config.Config, args []string) {
+	if len(args) < 1 || (args[0] != "list" && len(args) < 2) {
+		fmt.Println("Usage: tiny-client node blacklist add <pubkey>")
+		fmt.Println("       tiny-client node blacklist remove <pubkey>")
+		fmt.Println("       tiny-client node blacklist list")
+		return
+	}
+
+	switch args[0] {
+	case "add":
+		added, err := wallet.AddToBlacklist(accountDir(cfg), args[1])
+		if err != nil {
+			log.Fatalf("Failed to add node to blacklist: %v", err)
+		}
+		if !added {
+			fmt.Printf("Node %s is already blacklisted\n", args[1])
+			return
+		}
+		fmt.Printf("Blacklisted node %s\n", args[1])
+		fmt.Println("Note: the blacklist is stored but not yet enforced when routing payments")
+	case "remove":
+		removed, err := wallet.RemoveFromBlacklist(accountDir(cfg), args[1])
+		if err != nil {
+			log.Fatalf("Failed to remove node from blacklist: %v", err)
+		}
+		if !removed {
+			fmt.Printf("Node %s is not blacklisted\n", args[1])
+			return
+		}
+		fmt.Printf("Removed node %s from blacklist\n", args[1])
+	case "list":
+		pubkeys, err := wallet.LoadBlacklist(accountDir(cfg))
+		if err != nil {
+			log.Fatalf("Failed to load blacklist: %v", err)
+		}
+		fmt.Println("Blacklisted Nodes:")
+		fmt.Println("------------------")
+		if len(pubkeys) == 0 {
+			fmt.Println("No blacklisted nodes")
+			return
+		}
+		for _, pubkey := range pubkeys {
+			fmt.Println(pubkey)
+		}
+	default:
+		log.Fatalf("Unknown blacklist command: %s", args[0])
+	}
+}
+
+// acmeCacheDir is the directory in the working directory where serve
+// --tls-auto keeps its Let's Encrypt account and certificates
+const acmeCacheDir = "acme_cache"
+
+// serve runs the HTTP API. logLevel is the level set by -v, -vv, -vvv or -q,
+// which --logfile keeps.
+func serve(ctx context.Context, w *wallet.Wallet, cfg *config.Config, logLevel string, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on (default :443 with --tls-auto)")
+	logFile := fs.String("logfile", "", "Write logs to this file instead of stderr")
+	logMaxSizeMB := fs.Int("log-max-size-mb", 100, "Rotate the log file once it exceeds this size in MB")
+	logMaxBackups := fs.Int("log-max-backups", 3, "Number of rotated log files to keep")
+	cors := fs.String("cors", "", "Allow browser requests from this origin, or * for any origin")
+	corsAllowedOrigins := fs.String("cors-allowed-origins", "", "Comma separated list of origins allowed to make browser requests")
+	corsOriginsFile := fs.String("cors-origins-file", "", "File of origins allowed to make browser requests, one per line, reloaded every minute and on SIGHUP")
+	rateLimit := fs.Int("rate-limit", 0, "Requests per minute allowed from each IP (0 disables rate limiting)")
+	tlsCert := fs.String("tls-cert", "", "Serve HTTPS with this PEM certificate (needs --tls-key)")
+	tlsKey := fs.String("tls-key", "", "PEM private key of --tls-cert")
+	tlsAuto := fs.String("tls-auto", "", "Serve HTTPS with a Let's Encrypt certificate for this domain")
+	parseArgs(fs, args)
+
+	if (*tlsCert == "") != (*tlsKey == "") {
+		log.Fatalf("--tls-cert and --tls-key must be used together")
+	}
+	if *tlsAuto != "" && *tlsCert != "" {
+		log.Fatalf("--tls-auto can't be combined with --tls-cert")
+	}
+	if *tlsAuto != "" {
+		// Let's Encrypt answers the TLS-ALPN challenge on port 443, which a
+		// localhost address can never receive
+		addrSet := false
+		fs.Visit(func(f *flag.Flag) { addrSet = addrSet || f.Name == "addr" })
+		if !addrSet {
+			*addr = ":443"
+		} else if isLoopbackAddr(*addr) {
+			log.Fatalf("--tls-auto can't get a certificate on %s: Let's Encrypt must reach --addr on port 443", *addr)
+		}
+	}
+	// A server other machines can reach must not expose POST /send to them
+	// without a token
+	if cfg.BreezServeToken == "" {
+		if *tlsAuto != "" {
+			log.Fatalf("--tls-auto needs API authentication: set BREEZ_SERVE_TOKEN")
+		}
+		if !isLoopbackAddr(*addr) {
+			log.Fatalf("Listening on %s needs API authentication: set BREEZ_SERVE_TOKEN or use a localhost address", *addr)
+		}
+	}
+
+	if *logFile != "" {
+		writer, err := logrotate.NewRotatingWriter(*logFile, int64(*logMaxSizeMB)*1024*1024, *logMaxBackups)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer writer.Close()
+
+		setupLogger(levelOr(logLevel, cfg.BreezLogLevel), writer)
+		go writer.Watch(ctx, logrotate.CheckInterval, func(err error) {
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file: %v\n", err)
+		})
+	}
+
+	go w.TrackPayments(ctx, wallet.PaymentTrackerInterval)
+	go w.WatchInvoiceExpiry(ctx, wallet.InvoiceExpiryInterval)
+
+	srv := server.New(w, slog.Default())
+	if cfg.BreezServeToken != "" {
+		srv.EnableAuth(cfg.BreezServeToken)
+	}
+	var origins []string
+	if *cors != "" {
+		origins = append(origins, *cors)
+	}
+	for _, origin := range strings.Split(*corsAllowedOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) > 0 {
+		if err := srv.EnableCORS(origins); err != nil {
+			log.Fatalf("Failed to enable CORS: %v", err)
+		}
+	}
+	if *corsOriginsFile != "" {
+		if err := srv.EnableCORSFile(*corsOriginsFile); err != nil {
+			log.Fatalf("Failed to read CORS origins file: %v", err)
+		}
+		go srv.WatchCORSFile(ctx, server.CORSFileReloadInterval)
+	}
+	if *rateLimit < 0 {
+		log.Fatalf("--rate-limit must not be negative")
+	}
+	if *rateLimit > 0 {
+		srv.EnableRateLimit(*rateLimit)
+	}
+
+	var err error
+	switch {
+	case *tlsCert != "":
+		err = srv.ListenAndServeTLS(*addr, *tlsCert, *tlsKey)
+	case *tlsAuto != "":
+		err = srv.ListenAndServeAutoTLS(*addr, *tlsAuto, filepath.Join(cfg.BreezWorkingDir, acmeCacheDir))
+	default:
+		err = srv.ListenAndServe(*addr)
+	}
+	if err != nil {
+		log.Fatalf("HTTP server failed: %v", err)
+	}
+}
+
+// isLoopbackAddr reports whether the host:port address only accepts
+// connections from this machine. An empty host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func btcpayRelay(ctx context.Context, w *wallet.Wallet, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("btcpay-relay", flag.ExitOnError)
+	addr := fs.String("addr", ":7070", "Address to listen on")
+	parseArgs(fs, args)
+
+	go w.WatchInvoiceExpiry(ctx, wallet.InvoiceExpiryInterval)
+
+	relay := btcpay.New(w, cfg.BreezNetwork, cfg.BreezBTCPayToken, slog.Default())
+	if err := relay.ListenAndServe(*addr); err != nil {
+		log.Fatalf("BTCPay relay failed: %v", err)
+	}
+}
+
+// formatAmount formats satoshi amount with proper sign. Outgoing zero amounts
+// are shown as -0 so a 0 sat send can't be mistaken for a receive.
+func formatAmount(sats int64, isOutgoing bool) string {
 	if sats == 0 {
+		if isOutgoing {
+			return "-0"
+		}
 		return "0"
 	}
 
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+func TestHideZeroTokens(t *testing.T) {
+	tokens := []*wallet.TokenBalance{
+		{TokenID: "empty", Balance: "0"},
+		{TokenID: "funded", Balance: "1500"},
+		{TokenID: "padded-empty", Balance: "000"},
+		{TokenID: "huge", Balance: "340282366920938463463374607431768211455"},
+		{TokenID: "unparsable", Balance: ""},
+	}
+
+	tests := []struct {
+		name       string
+		showZero   bool
+		want       []string
+		wantHidden int
+	}{
+		{"zero balances hidden by default", false, []string{"funded", "huge", "unparsable"}, 2},
+		{"--show-zero lists every token", true, []string{"empty", "funded", "padded-empty", "huge", "unparsable"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, hidden := hideZeroTokens(tokens, tt.showZero)
+			got := []string{}
+			for _, token := range kept {
+				got = append(got, token.TokenID)
+			}
+			if !reflect.DeepEqual(got, tt.want) || hidden != tt.wantHidden {
+				t.Errorf("hideZeroTokens() = %v, %d hidden, want %v, %d hidden", got, hidden, tt.want, tt.wantHidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// recentTransactions is the number of recent transactions summarized in the metrics
+const recentTransactions = 100
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Snapshot is the wallet state exported as metrics
+type Snapshot struct {
+	Balance      *wallet.Balance
+	Transactions []*wallet.Transaction
+	Tokens       []*wallet.TokenBalance
+}
+
+// Collect fetches the balance, recent transactions and token balances
+func Collect(ctx context.Context, w *wallet.Wallet) (*Snapshot, error) {
+	balance, err := w.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := w.GetTransactions(ctx, recentTransactions)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := w.GetTokenBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Balance:      balance,
+		Transactions: transactions,
+		Tokens:       tokens,
+	}, nil
+}
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition format
+func WritePrometheus(out io.Writer, s *Snapshot) error {
+	var b strings.Builder
+
+	writeGauge(&b, "tiny_spark_balance_sats", "Lightning balance in satoshis", float64(s.Balance.LightningBalanceSats))
+	writeGauge(&b, "tiny_spark_max_payable_sats", "Maximum payable amount in satoshis", float64(s.Balance.MaxPayableSats))
+	writeGauge(&b, "tiny_spark_max_receivable_sats", "Maximum receivable amount in satoshis", float64(s.Balance.MaxReceivableSats))
+
+	counts := make(map[[2]string]int)
+	amounts := make(map[string]int64)
+	var fees int64
+	for _, tx := range s.Transactions {
+		counts[[2]string{tx.Type, tx.Status}]++
+		amount := tx.AmountSats
+		if amount < 0 {
+			amount = -amount
+		}
+		amounts[tx.Type] += amount
+		fees += tx.FeeSats
+	}
+
+	b.WriteString("# HELP tiny_spark_recent_transactions Number of recent transactions by type and status\n")
+	b.WriteString("# TYPE tiny_spark_recent_transactions gauge\n")
+	countKeys := make([][2]string, 0, len(counts))
+	for key := range counts {
+		countKeys = append(countKeys, key)
+	}
+	sort.Slice(countKeys, func(i, j int) bool {
+		if countKeys[i][0] != countKeys[j][0] {
+			return countKeys[i][0] < countKeys[j][0]
+		}
+		return countKeys[i][1] < countKeys[j][1]
+	})
+	for _, key := range countKeys {
+		fmt.Fprintf(&b, "tiny_spark_recent_transactions{type=\"%s\",status=\"%s\"} %d\n", escapeLabel(key[0]), escapeLabel(key[1]), counts[key])
+	}
+
+	b.WriteString("# HELP tiny_spark_recent_transaction_amount_sats Total amount of recent transactions by type in satoshis\n")
+	b.WriteString("# TYPE tiny_spark_recent_transaction_amount_sats gauge\n")
+	types := make([]string, 0, len(amounts))
+	for txType := range amounts {
+		types = append(types, txType)
+	}
+	sort.Strings(types)
+	for _, txType := range types {
+		fmt.Fprintf(&b, "tiny_spark_recent_transaction_amount_sats{type=\"%s\"} %d\n", escapeLabel(txType), amounts[txType])
+	}
+
+	writeGauge(&b, "tiny_spark_recent_transaction_fees_sats", "Total fees of recent transactions in satoshis", float64(fees))
+
+	b.WriteString("# HELP tiny_spark_token_balance Token balance in base units\n")
+	b.WriteString("# TYPE tiny_spark_token_balance gauge\n")
+	tokens := append([]*wallet.TokenBalance(nil), s.Tokens...)
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].TokenID < tokens[j].TokenID })
+	for _, token := range tokens {
+		balance, ok := new(big.Float).SetString(token.Balance)
+		if !ok {
+			continue
+		}
+		value, _ := balance.Float64()
+		fmt.Fprintf(&b, "tiny_spark_token_balance{token_id=\"%s\",ticker=\"%s\"} %s\n", escapeLabel(token.TokenID), escapeLabel(token.Ticker), formatValue(value))
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func writeGauge(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(b, "%s %s\n", name, formatValue(value))
+}
+
+// formatValue formats a sample value without exponent notation for whole numbers
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// escapeLabel escapes a label value as required by the Prometheus text format
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	corsAllowedMethods = "GET, POST"
+	corsAllowedHeaders = "Content-Type, Authorization"
+)
+
+// Origins is an allow-list of CORS origins that can be replaced while the
+// server is running
+type Origins struct {
+	mu       sync.RWMutex
+	allowAll bool
+	allowed  map[string]bool
+}
+
+// NewOrigins creates an allow-list of the given origins. An origin of "*"
+// allows every origin.
+func NewOrigins(origins []string) *Origins {
+	o := &Origins{}
+	o.Set(origins)
+	return o
+}
+
+// Set replaces the allowed origins
+func (o *Origins) Set(origins []string) {
+	allowAll := false
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			allowAll = true
+		}
+		if origin != "" {
+			allowed[origin] = true
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.allowAll = allowAll
+	o.allowed = allowed
+}
+
+// Len returns the number of allowed origins
+func (o *Origins) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.allowed)
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request
+// origin, or "" if it isn't allowed
+func (o *Origins) allowOrigin(origin string) string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	switch {
+	case o.allowAll:
+		return "*"
+	case o.allowed[origin]:
+		return origin
+	default:
+		return ""
+	}
+}
+
+// CORS wraps a handler and adds the CORS headers browsers need to call the API
+// from the allowed origins. An origin of "*" allows every origin. Preflight
+// OPTIONS requests are answered with 204 without reaching the handler.
+func CORS(allowedOrigins []string, next http.Handler) http.Handler {
+	return CORSOrigins(NewOrigins(allowedOrigins), next)
+}
+
+// CORSOrigins is CORS with an allow-list that may change while serving. Every
+// request is checked against the current list.
+func CORSOrigins(origins *Origins, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allow := origins.allowOrigin(r.Header.Get("Origin"))
+		if allow == "*" {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			// The header depends on the origin, whether it matches or not, so
+			// caches must keep responses per origin
+			w.Header().Add("Vary", "Origin")
+			if allow != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allow)
+			}
+		}
+		w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+		w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ReadOriginsFile reads a CORS allow-list with one origin per line. Blank lines
+// and lines starting with # are skipped.
+func ReadOriginsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var origins []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		origins = append(origins, line)
+	}
+	return origins, scanner.Err()
+}
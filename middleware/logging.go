@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	// maxLoggedBodyBytes is the maximum number of body bytes included in a log line
+	maxLoggedBodyBytes = 1000
+	// maxRecordedBodyBytes is how much of a response body is kept for
+	// redaction before it is truncated to maxLoggedBodyBytes
+	maxRecordedBodyBytes = 64 * 1024
+	// maxRequestBodyBytes is the largest request body accepted
+	maxRequestBodyBytes = 1024 * 1024
+)
+
+// sensitiveFieldPattern matches JSON string fields that must never be logged,
+// including one left unterminated at the end of a recorded body
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)("(?:breez_)?(?:mnemonic|api_?key|secret|token)"\s*:\s*)"(?:[^"]*"|[^"]*$)`)
+
+// responseRecorder captures the status code and the beginning of the response body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if remaining := maxRecordedBodyBytes - r.body.Len(); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		r.body.Write(p[:remaining])
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush passes flushes through so streaming handlers keep working
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Logging wraps a handler and logs every request with its status and latency.
+// Request and response bodies are only logged when debug logging is enabled.
+// Request bodies larger than 1 MB are refused with 413.
+func Logging(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		debug := logger.Enabled(r.Context(), slog.LevelDebug)
+
+		var requestBody []byte
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+		}
+		if debug && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				status := http.StatusBadRequest
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				logger.Warn("failed to read http request body", "method", r.Method, "path", r.URL.Path, "error", err)
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			requestBody = body
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"latency_ms", time.Since(start).Milliseconds(),
+		}
+
+		if debug {
+			attrs = append(attrs,
+				"request_body", formatBody(requestBody),
+				"response_body", formatBody(recorder.body.Bytes()),
+			)
+			logger.Debug("http request", attrs...)
+			return
+		}
+		logger.Info("http request", attrs...)
+	})
+}
+
+// formatBody redacts sensitive fields and truncates a body for logging.
+// Redaction runs first so a secret cut off by the truncation is still hidden.
+func formatBody(body []byte) string {
+	redacted := sensitiveFieldPattern.ReplaceAllString(string(body), `$1"[REDACTED]"`)
+	if len(redacted) > maxLoggedBodyBytes {
+		return redacted[:maxLoggedBodyBytes] + "...(truncated)"
+	}
+	return redacted
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+const (
+	// monitorPollInterval is how often monitor fetches transactions and the balance
+	monitorPollInterval = 5 * time.Second
+	// monitorHighlight is how long a new transaction stays colored
+	monitorHighlight = 10 * time.Second
+)
+
+// ANSI sequences used by the monitor display
+const (
+	ansiHome       = "\033[H"
+	ansiClear      = "\033[2J"
+	ansiClearBelow = "\033[J"
+	ansiClearLine  = "\033[K"
+	ansiHideCursor = "\033[?25l"
+	ansiShowCursor = "\033[?25h"
+	ansiGreen      = "\033[32m"
+	ansiRed        = "\033[31m"
+	ansiReset      = "\033[0m"
+)
+
+// monitor redraws the recent transactions and the balance in place until
+// interrupted, coloring new incoming transactions green and outgoing ones red
+func monitor(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("monitor", flag.ExitOnError)
+	rows := fs.Int("rows", 10, "Number of recent transactions to show")
+	parseArgs(fs, args)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Print(ansiHideCursor + ansiClear)
+	defer fmt.Print(ansiShowCursor)
+
+	m := &monitorState{
+		rows:  *rows,
+		color: useColor(os.Stdout),
+		seen:  make(map[string]time.Time),
+	}
+	m.refresh(ctx, w)
+	m.render()
+
+	poll := time.NewTicker(monitorPollInterval)
+	defer poll.Stop()
+	// Redraw between polls so highlights fade on time
+	redraw := time.NewTicker(time.Second)
+	defer redraw.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return
+		case <-poll.C:
+			m.refresh(ctx, w)
+			m.render()
+		case <-redraw.C:
+			m.render()
+		}
+	}
+}
+
+// monitorState is what the monitor display shows
+type monitorState struct {
+	rows         int
+	color        bool
+	transactions []*wallet.Transaction
+	balance      *wallet.Balance
+	updatedAt    time.Time
+	err          error
+
+	// seen records when each transaction first appeared; the ones already
+	// there at startup have the zero time so they aren't highlighted
+	seen   map[string]time.Time
+	primed bool
+}
+
+func (m *monitorState) refresh(ctx context.Context, w *wallet.Wallet) {
+	transactions, err := w.GetTransactions(ctx, m.rows)
+	if err != nil {
+		m.err = err
+		return
+	}
+	balance, err := w.GetBalance(ctx)
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	now := time.Now()
+	for _, tx := range transactions {
+		if _, ok := m.seen[tx.ID]; !ok {
+			if m.primed {
+				m.seen[tx.ID] = now
+			} else {
+				m.seen[tx.ID] = time.Time{}
+			}
+		}
+	}
+	m.primed = true
+
+	m.transactions = transactions
+	m.balance = balance
+	m.updatedAt = now
+	m.err = nil
+}
+
+func (m *monitorState) render() {
+	var b strings.Builder
+	b.WriteString(ansiHome)
+
+	fmt.Fprintf(&b, "Tiny Spark Monitor (Ctrl-C to exit)%s\n", ansiClearLine)
+	if !m.updatedAt.IsZero() {
+		fmt.Fprintf(&b, "Updated %s%s\n", m.updatedAt.Format("15:04:05"), ansiClearLine)
+	}
+	fmt.Fprintf(&b, "%s\n", ansiClearLine)
+
+	fmt.Fprintf(&b, "%-16s  %-7s  %10s  %-8s  %s%s\n", "TIME", "TYPE", "AMOUNT", "STATUS", "DESCRIPTION", ansiClearLine)
+
+	// Oldest first so new transactions appear at the bottom and push older ones up
+	for i := m.rows - 1; i >= 0; i-- {
+		if i >= len(m.transactions) {
+			fmt.Fprintf(&b, "%s\n", ansiClearLine)
+			continue
+		}
+		tx := m.transactions[i]
+		line := fmt.Sprintf("%-16s  %-7s  %10s  %-8s  %s",
+			tx.Timestamp.Format("2006-01-02 15:04"),
+			tx.Type,
+			formatAmount(tx.AmountSats, tx.Type == "send"),
+			formatStatus(tx.Status),
+			truncateString(tx.DisplayDescription(), 30))
+
+		if first := m.seen[tx.ID]; m.color && !first.IsZero() && time.Since(first) < monitorHighlight {
+			if tx.Type == "send" {
+				line = ansiRed + line + ansiReset
+			} else {
+				line = ansiGreen + line + ansiReset
+			}
+		}
+		fmt.Fprintf(&b, "%s%s\n", line, ansiClearLine)
+	}
+
+	fmt.Fprintf(&b, "%s%s\n", strings.Repeat("-", 60), ansiClearLine)
+	if m.balance != nil {
+		fmt.Fprintf(&b, "Balance:      %d sats%s\n", m.balance.LightningBalanceSats, ansiClearLine)
+		fmt.Fprintf(&b, "Max Payable:  %d sats%s\n", m.balance.MaxPayableSats, ansiClearLine)
+	}
+	if m.err != nil {
+		fmt.Fprintf(&b, "Update failed: %v%s\n", m.err, ansiClearLine)
+	}
+	b.WriteString(ansiClearBelow)
+
+	fmt.Print(b.String())
+}
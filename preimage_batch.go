@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// preimageResult is the outcome of one payment of a preimage file, written to the
+// results file as a JSON line
+type preimageResult struct {
+	Index       int    `json:"index"`
+	Invoice     string `json:"invoice"`
+	PaymentHash string `json:"payment_hash,omitempty"`
+	Status      string `json:"status"`
+	AmountSats  int64  `json:"amount_sats,omitempty"`
+	FeeSats     int64  `json:"fee_sats,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// sendPreimageBatch pays the invoices of a JSONL file one after the other,
+// checking each line's preimage against its invoice first, and writes a result
+// line per payment to output. Failures don't stop the batch. The total of the
+// invoice amounts goes through checkSendAmount before anything is paid.
+func sendPreimageBatch(ctx context.Context, w *wallet.Wallet, path, output string, warnAbove int64, confirmLarge, nonInteractive bool) {
+	payments, err := readPreimageFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read preimage file: %v", err)
+	}
+	if len(payments) == 0 {
+		log.Fatalf("No payments in %s", path)
+	}
+
+	var totalSats int64
+	for _, payment := range payments {
+		if sats, ok := wallet.InvoiceAmountSats(payment.Invoice); ok {
+			totalSats += sats
+		}
+	}
+	fmt.Printf("Sending %d payments, %d sats in total\n", len(payments), totalSats)
+	if !checkSendAmount(strconv.FormatInt(totalSats, 10), warnAbove, confirmLarge, nonInteractive) {
+		fmt.Println("Payment cancelled")
+		return
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		log.Fatalf("Failed to create results file: %v", err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+
+	failed := 0
+	for i, payment := range payments {
+		result := preimageResult{Index: i + 1, Invoice: payment.Invoice, Status: "sent"}
+		if err := payment.Verify(); err != nil {
+			result.Status, result.Error = "failed", err.Error()
+		} else if response, err := w.SendLightningInvoice(ctx, payment.Invoice); err != nil {
+			result.Status, result.Error = "failed", err.Error()
+		} else {
+			result.PaymentHash = response.PaymentHash
+			result.AmountSats = response.AmountSats
+			result.FeeSats = response.FeeSats
+		}
+
+		if result.Status == "failed" {
+			failed++
+			fmt.Printf("[%d/%d] Failed: %s\n", i+1, len(payments), result.Error)
+		} else {
+			fmt.Printf("[%d/%d] Sent %d sats (fee %d sats)\n", i+1, len(payments), result.AmountSats, result.FeeSats)
+		}
+		if err := encoder.Encode(result); err != nil {
+			log.Fatalf("Failed to write results: %v", err)
+		}
+	}
+
+	fmt.Printf("\n%d/%d payments sent successfully, %d failed.\n", len(payments)-failed, len(payments), failed)
+	fmt.Printf("Results written to %s\n", output)
+}
+
+// readPreimageFile reads one {"invoice","preimage","amount_sats"} object per
+// line, skipping blank lines
+func readPreimageFile(path string) ([]*wallet.PreimagePayment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var payments []*wallet.PreimagePayment
+	scanner := bufio.NewScanner(file)
+	// Invoices with many route hints make long lines
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		payment := &wallet.PreimagePayment{}
+		if err := json.Unmarshal([]byte(text), payment); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if payment.Invoice == "" || payment.Preimage == "" {
+			return nil, fmt.Errorf("line %d: invoice and preimage are required", line)
+		}
+		payments = append(payments, payment)
+	}
+	return payments, scanner.Err()
+}
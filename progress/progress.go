@@ -0,0 +1,118 @@
+package progress
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Interval is how often the spinner is redrawn
+const Interval = 200 * time.Millisecond
+
+// frames are drawn one after the other, one per Interval
+var frames = []string{"|", "/", "-", `\`}
+
+// Spinner draws a rotating character, a label and the elapsed time on a
+// single stderr line until it is stopped. A disabled spinner does nothing.
+type Spinner struct {
+	enabled bool
+	out     *os.File
+
+	mu      sync.Mutex
+	label   string
+	started time.Time
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New creates a spinner drawing on stderr. It is disabled unless enabled is
+// set and stderr is a terminal, so redirected output stays clean.
+func New(enabled bool) *Spinner {
+	return &Spinner{enabled: enabled && isTerminal(os.Stderr), out: os.Stderr}
+}
+
+// Enabled reports whether the spinner draws anything, so callers can print
+// plain progress lines instead when it doesn't
+func (s *Spinner) Enabled() bool {
+	return s.enabled
+}
+
+// Start draws the spinner with a label until Stop is called. Starting a
+// running spinner only changes its label.
+func (s *Spinner) Start(label string) {
+	if !s.enabled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.label = label
+	if s.stop != nil {
+		return
+	}
+
+	s.started = time.Now()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// Update changes the label of a running spinner
+func (s *Spinner) Update(label string) {
+	if !s.enabled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.label = label
+}
+
+// Stop stops the spinner and clears its line, so the result can be printed
+// in its place. Stopping a spinner that isn't running does nothing.
+func (s *Spinner) Stop() {
+	if !s.enabled {
+		return
+	}
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+func (s *Spinner) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame++ {
+		s.draw(frames[frame%len(frames)])
+		select {
+		case <-stop:
+			// Carriage return and erase the line
+			fmt.Fprint(s.out, "\r\033[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Spinner) draw(frame string) {
+	s.mu.Lock()
+	label, elapsed := s.label, time.Since(s.started)
+	s.mu.Unlock()
+
+	seconds := int(elapsed.Seconds())
+	fmt.Fprintf(s.out, "\r\033[K%s %s %02d:%02d", frame, label, seconds/60, seconds%60)
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// idleTimeout is how long a client's bucket is kept after its last request
+const idleTimeout = 10 * time.Minute
+
+// Limiter limits the requests of each client IP with a token bucket per IP.
+// A bucket holds up to a minute of requests and refills continuously.
+type Limiter struct {
+	perMinute int
+	buckets   sync.Map // client IP -> *bucket
+	lastSweep atomic.Int64
+}
+
+type bucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// New creates a limiter allowing perMinute requests per minute from each IP
+func New(perMinute int) *Limiter {
+	l := &Limiter{perMinute: perMinute}
+	l.lastSweep.Store(time.Now().UnixNano())
+	return l
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty it
+// returns false with the time until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+	l.sweep(now)
+
+	value, _ := l.buckets.LoadOrStore(key, &bucket{tokens: float64(l.perMinute), last: now})
+	b := value.(*bucket)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rate := float64(l.perMinute) / float64(time.Minute)
+	b.tokens = math.Min(float64(l.perMinute), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rate)
+}
+
+// sweep drops the buckets of clients that have been idle for a while, at most
+// once per idleTimeout, so the map doesn't grow with every IP ever seen
+func (l *Limiter) sweep(now time.Time) {
+	last := l.lastSweep.Load()
+	if now.Sub(time.Unix(0, last)) < idleTimeout || !l.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	l.buckets.Range(func(key, value any) bool {
+		b := value.(*bucket)
+		b.mu.Lock()
+		idle := now.Sub(b.last) > idleTimeout
+		b.mu.Unlock()
+		if idle {
+			l.buckets.Delete(key)
+		}
+		return true
+	})
+}
+
+// Rule gives the requests of one method and path their own limit
+type Rule struct {
+	Method    string
+	Path      string
+	PerMinute int
+}
+
+// Middleware wraps a handler and answers 429 Too Many Requests with a
+// Retry-After header once a client IP exceeds its limit. Requests matching a
+// rule count against that rule's limit, all others against perMinute.
+func Middleware(perMinute int, rules []Rule, next http.Handler) http.Handler {
+	fallback := New(perMinute)
+	limiters := make(map[string]*Limiter, len(rules))
+	for _, rule := range rules {
+		limiters[rule.Method+" "+rule.Path] = New(rule.PerMinute)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter, ok := limiters[r.Method+" "+r.URL.Path]
+		if !ok {
+			limiter = fallback
+		}
+
+		allowed, retryAfter := limiter.Allow(clientIP(r))
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP address of the connection. Forwarding headers are
+// ignored since any client can set them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
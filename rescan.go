@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/breez/tiny-spark/progress"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// rescan rebuilds the local payment cache from the SDK's payment history
+func rescan(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("rescan", flag.ExitOnError)
+	fromStr := fs.String("from-timestamp", "", "Only import payments created on or after this date (YYYY-MM-DD)")
+	showProgress := fs.Bool("progress", false, "Show a spinner with the elapsed time while rescanning")
+	parseArgs(fs, args)
+
+	var from time.Time
+	if *fromStr != "" {
+		var err error
+		from, err = time.ParseInLocation("2006-01-02", *fromStr, time.Local)
+		if err != nil {
+			log.Fatalf("Invalid --from-timestamp date: %v", err)
+		}
+	}
+
+	spinner := progress.New(*showProgress)
+	spinner.Start("Rescanning...")
+	result, err := w.Rescan(ctx, from, func(imported int) {
+		if spinner.Enabled() {
+			spinner.Update(fmt.Sprintf("Rescanning... %d payments imported", imported))
+			return
+		}
+		fmt.Printf("Imported %d payments...\n", imported)
+	})
+	// Stop before printing anything, so the result doesn't share the
+	// spinner's line
+	spinner.Stop()
+	if err != nil {
+		log.Fatalf("Failed to rescan payments: %v", err)
+	}
+
+	fmt.Printf("Rescan complete: imported %d new, updated %d existing payments.\n", result.Added, result.Updated)
+	if result.InvoicesUpdated > 0 {
+		fmt.Printf("Updated the status of %d invoices\n", result.InvoicesUpdated)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+const (
+	highlightStart = "\033[1;33m"
+	highlightEnd   = "\033[0m"
+)
+
+func searchTransactions(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	useRegex := fs.Bool("regex", false, "Treat the query as a regular expression")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client search <query> [--regex]")
+		return
+	}
+	query := strings.Join(args, " ")
+
+	expr := regexp.QuoteMeta(query)
+	if *useRegex {
+		expr = query
+	}
+	pattern, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		log.Fatalf("Invalid regular expression: %v", err)
+	}
+
+	transactions, err := w.SearchTransactions(ctx, pattern)
+	if err != nil {
+		log.Fatalf("Failed to search transactions: %v", err)
+	}
+
+	if len(transactions) == 0 {
+		fmt.Printf("No transactions matching %q\n", query)
+		return
+	}
+
+	fmt.Printf("%d Transactions matching %q:\n", len(transactions), query)
+	fmt.Println(strings.Repeat("-", 20))
+
+	describe := func(description string) string { return description }
+	if useColor(os.Stdout) {
+		// The description is the last column, so highlighting doesn't affect alignment
+		describe = func(description string) string { return highlight(description, pattern) }
+	}
+	printTransactionTable(transactions, describe)
+}
+
+// highlight wraps every match of pattern in s with terminal color codes
+func highlight(s string, pattern *regexp.Regexp) string {
+	return pattern.ReplaceAllStringFunc(s, func(match string) string {
+		return highlightStart + match + highlightEnd
+	})
+}
+
+// useColor reports whether colored output should be written to f
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
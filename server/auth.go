@@ -0,0 +1,52 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// EnableAuth requires clients to send token as a bearer token in the
+// Authorization header on every request that isn't a GET
+func (s *Server) EnableAuth(token string) {
+	s.authToken = token
+}
+
+// authenticate rejects requests other than GET, HEAD and OPTIONS without the
+// bearer token of EnableAuth. Without a token they are always rejected, since
+// they can move funds.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if s.authToken == "" {
+			writeError(w, http.StatusForbidden, fmt.Errorf("%s requests are disabled: set BREEZ_SERVE_TOKEN to enable them", r.Method))
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireJSON writes a 415 response unless the request body is JSON. Browsers
+// can send cross-site form posts as text/plain without a preflight, but not
+// application/json.
+func requireJSON(w http.ResponseWriter, r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("Content-Type must be application/json"))
+		return false
+	}
+	return true
+}
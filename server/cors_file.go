@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/breez/tiny-spark/middleware"
+)
+
+// CORSFileReloadInterval is how often WatchCORSFile rereads the origins file
+const CORSFileReloadInterval = 60 * time.Second
+
+// EnableCORSFile allows browser requests from the origins listed in a file, one
+// per line, on top of the origins given to EnableCORS. WatchCORSFile keeps the
+// list up to date while serving. Like with EnableCORS, a "*" line is refused
+// without API authentication.
+func (s *Server) EnableCORSFile(path string) error {
+	origins, err := middleware.ReadOriginsFile(path)
+	if err != nil {
+		return err
+	}
+	if err := s.checkOrigins(origins); err != nil {
+		return err
+	}
+	s.corsFile = path
+	s.corsFileOrigins = origins
+	s.cors.Set(append(slices.Clone(s.corsOrigins), origins...))
+	return nil
+}
+
+// WatchCORSFile rereads the origins file of EnableCORSFile every interval and
+// whenever the process gets SIGHUP, until ctx is done. If the file can't be
+// read the current origins are kept.
+func (s *Server) WatchCORSFile(ctx context.Context, interval time.Duration) {
+	if s.corsFile == "" {
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-hup:
+			s.logger.Info("reloading cors origins file on SIGHUP", "path", s.corsFile)
+		}
+		s.reloadCORSFile()
+	}
+}
+
+func (s *Server) reloadCORSFile() {
+	origins, err := middleware.ReadOriginsFile(s.corsFile)
+	if err != nil {
+		s.logger.Warn("failed to reload cors origins file", "path", s.corsFile, "error", err)
+		return
+	}
+	if slices.Equal(origins, s.corsFileOrigins) {
+		return
+	}
+	if err := s.checkOrigins(origins); err != nil {
+		// Remember the refused list so the warning isn't repeated every reload
+		s.logger.Warn("ignoring cors origins file change", "path", s.corsFile, "error", err)
+		s.corsFileOrigins = origins
+		return
+	}
+
+	oldCount := s.cors.Len()
+	s.corsFileOrigins = origins
+	s.cors.Set(append(slices.Clone(s.corsOrigins), origins...))
+	s.logger.Info("cors origins changed", "path", s.corsFile, "old_count", oldCount, "new_count", s.cors.Len())
+}
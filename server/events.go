@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// keepaliveInterval is how often an event stream sends a ping comment so
+// proxies don't close an idle connection
+const keepaliveInterval = 30 * time.Second
+
+// subscriberBufferSize is the number of events buffered per stream
+const subscriberBufferSize = 16
+
+// broadcast fans the wallet's payment events out to every subscribed stream
+func (s *Server) broadcast(events <-chan wallet.PaymentEvent) {
+	for event := range events {
+		s.mu.Lock()
+		for subscriber := range s.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+				s.logger.Warn("dropped payment event for slow event stream", "type", event.Type, "payment_id", event.PaymentID)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) subscribe() chan wallet.PaymentEvent {
+	subscriber := make(chan wallet.PaymentEvent, subscriberBufferSize)
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+	return subscriber
+}
+
+func (s *Server) unsubscribe(subscriber chan wallet.PaymentEvent) {
+	s.mu.Lock()
+	delete(s.subscribers, subscriber)
+	s.mu.Unlock()
+}
+
+// handleEvents streams wallet events as server-sent events named after the
+// event type
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	subscriber := s.subscribe()
+	defer s.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event := <-subscriber:
+			data, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Error("failed to encode payment event", "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
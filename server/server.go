@@ -0,0 +1,367 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/breez/tiny-spark/metrics"
+	"github.com/breez/tiny-spark/middleware"
+	"github.com/breez/tiny-spark/ratelimit"
+	"github.com/breez/tiny-spark/wallet"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Server exposes wallet operations over a small JSON REST API
+type Server struct {
+	wallet          *wallet.Wallet
+	logger          *slog.Logger
+	cors            *middleware.Origins
+	corsOrigins     []string
+	corsFile        string
+	corsFileOrigins []string
+	rateLimit       int
+	authToken       string
+
+	mu          sync.Mutex
+	subscribers map[chan wallet.PaymentEvent]struct{}
+}
+
+type receiveRequest struct {
+	Type        string `json:"type"`
+	AmountSats  uint64 `json:"amount_sats"`
+	Description string `json:"description"`
+}
+
+type sendRequest struct {
+	Type        string `json:"type"`
+	Destination string `json:"destination"`
+	AmountSats  int64  `json:"amount_sats"`
+}
+
+// New creates a server backed by the given wallet
+func New(w *wallet.Wallet, logger *slog.Logger) *Server {
+	s := &Server{
+		wallet:      w,
+		logger:      logger,
+		cors:        middleware.NewOrigins(nil),
+		subscribers: make(map[chan wallet.PaymentEvent]struct{}),
+	}
+	go s.broadcast(w.Events())
+	return s
+}
+
+const (
+	// readHeaderTimeout bounds how long a client may take to send the request
+	// headers, so slow clients can't hold connections open
+	readHeaderTimeout = 10 * time.Second
+	// idleTimeout closes keep-alive connections left idle this long. There is
+	// no write timeout, since /events streams for as long as the client stays.
+	idleTimeout = 2 * time.Minute
+)
+
+// errWildcardWithoutAuth is returned for the "*" CORS origin without API
+// authentication, which would let any website script the API
+var errWildcardWithoutAuth = errors.New(`CORS origin "*" needs API authentication: set BREEZ_SERVE_TOKEN`)
+
+// EnableCORS adds CORS headers for the given origins to all responses so the
+// API can be called from browsers. An origin of "*" allows every origin and is
+// refused unless EnableAuth was called first.
+func (s *Server) EnableCORS(origins []string) error {
+	if err := s.checkOrigins(origins); err != nil {
+		return err
+	}
+	s.corsOrigins = origins
+	s.cors.Set(append(slices.Clone(origins), s.corsFileOrigins...))
+	return nil
+}
+
+// checkOrigins refuses the "*" origin unless the API needs authentication
+func (s *Server) checkOrigins(origins []string) error {
+	if s.authToken != "" {
+		return nil
+	}
+	for _, origin := range origins {
+		if strings.TrimSpace(origin) == "*" {
+			return errWildcardWithoutAuth
+		}
+	}
+	return nil
+}
+
+// rateLimitRules are the per-IP limits of endpoints that cost more than the
+// default limit allows
+var rateLimitRules = []ratelimit.Rule{
+	{Method: http.MethodPost, Path: "/send", PerMinute: 10},
+	{Method: http.MethodGet, Path: "/balance", PerMinute: 60},
+}
+
+// EnableRateLimit limits each client IP to perMinute requests per minute.
+// POST /send and GET /balance have their own, fixed limits.
+func (s *Server) EnableRateLimit(perMinute int) {
+	s.rateLimit = perMinute
+}
+
+// Handler returns the HTTP handler serving all API routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/balance", s.handleBalance)
+	mux.HandleFunc("/transactions", s.handleTransactions)
+	mux.HandleFunc("/payment/", s.handlePayment)
+	mux.HandleFunc("/tokens", s.handleTokens)
+	mux.HandleFunc("/receive", s.handleReceive)
+	mux.HandleFunc("/send", s.handleSend)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/events", s.handleEvents)
+
+	var handler http.Handler = s.authenticate(mux)
+	if s.rateLimit > 0 {
+		handler = ratelimit.Middleware(s.rateLimit, rateLimitRules, handler)
+	}
+	if len(s.corsOrigins) > 0 || s.corsFile != "" {
+		handler = middleware.CORSOrigins(s.cors, handler)
+	}
+	return middleware.Logging(s.logger, handler)
+}
+
+// httpServer returns the http.Server serving the API on addr
+func (s *Server) httpServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+}
+
+// ListenAndServe starts serving the API on addr
+func (s *Server) ListenAndServe(addr string) error {
+	s.logger.Info("starting http server", "addr", addr)
+	return s.httpServer(addr).ListenAndServe()
+}
+
+// ListenAndServeTLS starts serving the API over HTTPS on addr with the
+// certificate and key in the given PEM files
+func (s *Server) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	s.logger.Info("starting https server", "addr", addr, "cert", certFile)
+	return s.httpServer(addr).ListenAndServeTLS(certFile, keyFile)
+}
+
+// ListenAndServeAutoTLS starts serving the API over HTTPS on addr with a
+// certificate for domain obtained and renewed from Let's Encrypt. Certificates
+// are cached in cacheDir. The TLS-ALPN challenge is answered on addr itself,
+// so Let's Encrypt must reach it on port 443.
+func (s *Server) ListenAndServeAutoTLS(addr, domain, cacheDir string) error {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domain),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+	srv := s.httpServer(addr)
+	srv.TLSConfig = manager.TLSConfig()
+
+	s.logger.Info("starting https server", "addr", addr, "domain", domain, "acme_cache", cacheDir)
+	return srv.ListenAndServeTLS("", "")
+}
+
+// handleHealth reports whether the SDK is responding. A failed ping answers
+// 503 so load balancers take the instance out of rotation.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	if err := s.wallet.Ping(r.Context()); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "degraded",
+			"error":  err.Error(),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	balance, err := s.wallet.GetBalance(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	snapshot, err := metrics.Collect(r.Context(), s.wallet)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := metrics.WritePrometheus(w, snapshot); err != nil {
+		s.logger.Error("failed to write metrics", "error", err)
+	}
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	limit := 10
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	transactions, err := s.wallet.GetTransactions(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+func (s *Server) handlePayment(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	paymentID := strings.TrimPrefix(r.URL.Path, "/payment/")
+	if paymentID == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("payment id is required"))
+		return
+	}
+
+	payment, err := s.wallet.GetPayment(r.Context(), paymentID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	tokens, err := s.wallet.GetTokenBalances(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (s *Server) handleReceive(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) || !requireJSON(w, r) {
+		return
+	}
+
+	var req receiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.Description == "" {
+		req.Description = "Payment request"
+	}
+
+	var response *wallet.ReceivePaymentResponse
+	var err error
+
+	switch strings.ToLower(req.Type) {
+	case "lightning", "ln":
+		response, err = s.wallet.ReceiveLightningInvoice(r.Context(), req.AmountSats, req.Description)
+	case "bitcoin", "btc":
+		response, err = s.wallet.ReceiveBitcoinAddress(r.Context())
+	case "spark":
+		response, err = s.wallet.ReceiveSparkAddress(r.Context())
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown receive type: %s", req.Type))
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) || !requireJSON(w, r) {
+		return
+	}
+
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	var response *wallet.PaymentResponse
+	var err error
+
+	switch strings.ToLower(req.Type) {
+	case "lightning", "ln":
+		response, err = s.wallet.SendLightningInvoice(r.Context(), req.Destination)
+	case "bitcoin", "btc":
+		response, err = s.wallet.SendBitcoinAddress(r.Context(), req.Destination, req.AmountSats)
+	case "spark":
+		response, err = s.wallet.SendSparkAddress(r.Context(), req.Destination, req.AmountSats)
+	case "lnurl":
+		response, err = s.wallet.LnUrlPay(r.Context(), req.Destination, uint64(req.AmountSats), "Payment via LNURL")
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown send type: %s", req.Type))
+		return
+	}
+
+	var insufficient *wallet.ErrInsufficientFunds
+	var below *wallet.ErrAmountBelowMinimum
+	var above *wallet.ErrAmountAboveMaximum
+	if errors.As(err, &insufficient) || errors.As(err, &below) || errors.As(err, &above) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// requireMethod writes a 405 response if the request uses a different method
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// configCommand reads and writes settings in the .env file. It runs before the
+// configuration is loaded so it also works while required settings are missing.
+func configCommand(configFiles []string, args []string) {
+	if len(args) < 1 {
+		printConfigUsage()
+		return
+	}
+
+	switch args[0] {
+	case "get":
+		configGet(configFiles, args[1:])
+	case "set":
+		// Write to the file with the highest precedence
+		var configFile string
+		if len(configFiles) > 0 {
+			configFile = configFiles[len(configFiles)-1]
+		}
+		configSet(configFile, args[1:])
+	default:
+		fmt.Printf("Unknown config command: %s\n\n", args[0])
+		printConfigUsage()
+	}
+}
+
+func printConfigUsage() {
+	fmt.Println("Usage: tiny-client config <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  get <key>                      Show the effective value of a setting")
+	fmt.Println("  set <key> <value> [--confirm]  Write a setting to the .env file")
+	fmt.Println()
+	fmt.Println("Keys:")
+	fmt.Printf("  %s\n", strings.Join(config.SettingKeys(), "\n  "))
+	fmt.Println()
+	fmt.Println("BREEZ_API_KEY, BREEZ_MNEMONIC, BREEZ_BTCPAY_TOKEN and BREEZ_SERVE_TOKEN are never printed and need --confirm to change.")
+}
+
+func configGet(configFiles []string, args []string) {
+	if len(args) < 1 {
+		printConfigUsage()
+		return
+	}
+	key := strings.ToUpper(args[0])
+
+	value, err := config.Get(configFiles, key)
+	if err != nil {
+		log.Fatalf("Failed to get %s: %v", key, err)
+	}
+
+	switch {
+	case !config.IsSensitive(key):
+		fmt.Println(value)
+	case value == "":
+		fmt.Println("(not set)")
+	default:
+		fmt.Println("(set, hidden)")
+	}
+}
+
+func configSet(configFile string, args []string) {
+	fs := flag.NewFlagSet("config set", flag.ExitOnError)
+	confirm := fs.Bool("confirm", false, "Confirm changing a sensitive setting")
+	args = parseArgs(fs, args)
+
+	if len(args) < 2 {
+		printConfigUsage()
+		return
+	}
+	key := strings.ToUpper(args[0])
+	value := strings.Join(args[1:], " ")
+
+	if config.IsSensitive(key) && !*confirm {
+		log.Fatalf("%s is sensitive; pass --confirm to change it", key)
+	}
+	if key == "BREEZ_MNEMONIC" {
+		if err := wallet.ValidateMnemonic(value); err != nil {
+			log.Fatalf("Invalid value for %s: %v", key, err)
+		}
+	}
+
+	path, err := config.Set(configFile, key, value)
+	if errors.Is(err, config.ErrUnknownSetting) {
+		log.Fatalf("Unknown setting %s; run 'tiny-spark config' to list the keys", key)
+	}
+	if err != nil {
+		log.Fatalf("Failed to set %s: %v", key, err)
+	}
+
+	fmt.Printf("Set %s in %s\n", key, path)
+	if _, ok := os.LookupEnv(key); ok {
+		fmt.Fprintf(os.Stderr, "Warning: %s is also set in the environment, which takes precedence over %s\n", key, path)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// sweepCommand moves the whole balance off the wallet
+func sweepCommand(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 || args[0] != "lightning" {
+		fmt.Println("Usage: tiny-client sweep lightning <bitcoin_address> [--speed fast|medium|slow] --confirm")
+		return
+	}
+	sweepLightning(ctx, w, args[1:])
+}
+
+// sweepLightning sends the whole balance to an on-chain address, the fee taken
+// out of the amount. Without --confirm it only shows what would be sent.
+func sweepLightning(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("sweep lightning", flag.ExitOnError)
+	speedName := fs.String("speed", "medium", "Confirmation speed: fast, medium or slow")
+	confirm := fs.Bool("confirm", false, "Confirm sending the whole balance")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client sweep lightning <bitcoin_address> [--speed fast|medium|slow] --confirm")
+		return
+	}
+	address := args[0]
+	speed, err := wallet.ParseConfirmationSpeed(*speedName)
+	if err != nil {
+		log.Fatalf("Invalid speed: %v", err)
+	}
+
+	var dust *wallet.ErrSweepBelowDust
+	quote, err := w.QuoteLightningSweep(ctx, address, speed)
+	if errors.As(err, &dust) {
+		log.Fatalf("Nothing to sweep: %v", err)
+	}
+	if err != nil {
+		log.Fatalf("Failed to quote sweep: %v", err)
+	}
+
+	fmt.Printf("Closing Lightning balance of %d sats → ~%d sats on-chain after fees (%d sats, %s)\n",
+		quote.BalanceSats, quote.ReceiveSats, quote.FeeSats, *speedName)
+	if !*confirm {
+		log.Fatalf("Refusing to sweep the whole balance to %s without --confirm", address)
+	}
+
+	response, err := w.SweepLightningToOnchain(ctx, address, speed)
+	if errors.As(err, &dust) {
+		log.Fatalf("Nothing to sweep: %v", err)
+	}
+	if err != nil {
+		log.Fatalf("Failed to sweep: %v", err)
+	}
+
+	fmt.Println("Sweep Sent:")
+	fmt.Printf("Payment ID: %s\n", response.PaymentHash)
+	fmt.Printf("Address:    %s\n", address)
+	fmt.Printf("Amount:     %d sats\n", response.AmountSats)
+	fmt.Printf("Fee:        %d sats\n", response.FeeSats)
+	fmt.Printf("Status:     %s\n", response.Status)
+}
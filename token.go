@@ -0,0 +1,561 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/wallet"
+)
+
+func tokenCommand(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 1 {
+		printTokenUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		tokenList(ctx, w, args[1:])
+	case "history":
+		showTokenHistory(ctx, w, args[1:])
+	case "receive":
+		tokenReceive(ctx, w, args[1:])
+	case "approve":
+		tokenApprove(ctx, w, args[1:])
+	case "allowance":
+		tokenAllowance(ctx, w, args[1:])
+	case "revoke":
+		tokenRevoke(ctx, w, args[1:])
+	case "mint":
+		tokenSupply(ctx, w, "mint", args[1:])
+	case "burn":
+		tokenSupply(ctx, w, "burn", args[1:])
+	case "swap":
+		tokenSwap(ctx, w, args[1:])
+	default:
+		fmt.Printf("Unknown token command: %s\n\n", args[0])
+		printTokenUsage()
+	}
+}
+
+func printTokenUsage() {
+	fmt.Println("Usage: tiny-client token <command> [arguments]")
+	fmt.Println("Commands:")
+	fmt.Println("  list [--all] [--sort date|name|balance]         Show tokens with metadata and last activity")
+	fmt.Println("  history <token_id> [--limit 20] [--json|--csv]  Show token transfer history")
+	fmt.Println("  history --all-tokens [--limit 50]               Show the transfers of every held token")
+	fmt.Println("  receive --watch --token-id <id> [--timeout 300] Wait for an incoming token transfer")
+	fmt.Println("  approve <spender> <token_id> <amount>           Allow a spender to pull tokens")
+	fmt.Println("  allowance <spender> <token_id>                  Show a spender's allowance")
+	fmt.Println("  revoke <spender> <token_id>                     Remove a spender's allowance")
+	fmt.Println("  metadata set <token_id> [--name N] [--ticker T] [--decimals D] [--logo-url U]")
+	fmt.Println("                                                  Override token metadata")
+	fmt.Println("  freeze <token_id>                               Stop sending and watching for a token locally")
+	fmt.Println("  unfreeze <token_id>                             Lift a local token freeze")
+	fmt.Println("  mint <token_id> <amount> --confirm              Mint supply of a token this wallet issued")
+	fmt.Println("  burn <token_id> <amount> --confirm              Burn supply of a token this wallet issued")
+	fmt.Println("  swap <from_id> <to_id> <amount>                 Swap one token for another (not supported yet)")
+}
+
+// tokenSwap quotes a swap of a decimal amount of one token for another and
+// asks before swapping
+func tokenSwap(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 3 {
+		fmt.Println("Usage: tiny-client token swap <from_token_id> <to_token_id> <amount>")
+		return
+	}
+	fromID, toID := args[0], args[1]
+
+	fromDecimals, err := w.GetTokenDecimals(ctx, fromID)
+	if err != nil {
+		log.Fatalf("Failed to get token decimals: %v", err)
+	}
+	toDecimals, err := w.GetTokenDecimals(ctx, toID)
+	if err != nil {
+		log.Fatalf("Failed to get token decimals: %v", err)
+	}
+	amount, err := wallet.ParseTokenAmount(args[2], fromDecimals)
+	if err != nil {
+		log.Fatalf("Invalid amount: %v", err)
+	}
+
+	quote, err := w.QuoteTokenSwap(ctx, fromID, toID, amount)
+	if err != nil {
+		log.Fatalf("Failed to quote token swap: %v", err)
+	}
+	// The quoted rate is in base units; show it per whole token
+	rate := new(big.Rat).Mul(quote.Rate, new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals)), nil),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals)), nil)))
+	question := fmt.Sprintf("Swap %s %s → estimated %s %s at rate %s; fee: %d sats. Proceed?",
+		wallet.FormatTokenAmount(amount, fromDecimals), fromID,
+		wallet.FormatTokenAmount(quote.ToAmount, toDecimals), toID, rate.FloatString(8), quote.FeeSats)
+	if !askYesNo(bufio.NewReader(os.Stdin), question) {
+		fmt.Println("Swap cancelled")
+		return
+	}
+
+	result, err := w.SwapTokens(ctx, fromID, toID, amount)
+	if err != nil {
+		log.Fatalf("Failed to swap tokens: %v", err)
+	}
+	fmt.Println("Tokens Swapped:")
+	fmt.Printf("Sent:         %s %s\n", wallet.FormatTokenAmount(result.FromAmount, fromDecimals), fromID)
+	fmt.Printf("Received:     %s %s\n", wallet.FormatTokenAmount(result.ToAmount, toDecimals), toID)
+	fmt.Printf("Fee:          %d sats\n", result.FeeSats)
+}
+
+// tokenList shows the held tokens, or with --all every token in the metadata
+// registry as well, newest activity first unless --sort says otherwise
+func tokenList(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("token list", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include tokens from the metadata registry with a zero balance")
+	sortBy := fs.String("sort", "date", "Sort by date (newest first), name or balance (largest first)")
+	parseArgs(fs, args)
+
+	tokens, err := w.ListKnownTokens(ctx, *all)
+	if err != nil {
+		log.Fatalf("Failed to list tokens: %v", err)
+	}
+
+	switch *sortBy {
+	case "date":
+		sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].LastActivity.After(tokens[j].LastActivity) })
+	case "name":
+		sort.SliceStable(tokens, func(i, j int) bool { return strings.ToLower(tokens[i].Name) < strings.ToLower(tokens[j].Name) })
+	case "balance":
+		sort.SliceStable(tokens, func(i, j int) bool { return tokenValue(tokens[i]).Cmp(tokenValue(tokens[j])) > 0 })
+	default:
+		log.Fatalf("Invalid --sort %q: must be date, name or balance", *sortBy)
+	}
+
+	fmt.Println("Tokens:")
+	fmt.Println("-------")
+
+	if len(tokens) == 0 {
+		fmt.Println("No tokens found")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "TOKEN ID\tNAME\tTICKER\tDECIMALS\tBALANCE\tLAST ACTIVITY\tFROZEN")
+	fmt.Fprintln(tabWriter, "--------\t----\t------\t--------\t-------\t-------------\t------")
+	for _, token := range tokens {
+		balance := token.Balance
+		if amount, ok := new(big.Int).SetString(token.Balance, 10); ok {
+			balance = wallet.FormatTokenAmount(amount, token.Decimals)
+		}
+		lastActivity := "-"
+		if !token.LastActivity.IsZero() {
+			lastActivity = token.LastActivity.Format("2006-01-02")
+		}
+		frozen := "no"
+		if token.Frozen {
+			frozen = "yes"
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			truncateString(token.TokenID, 12), token.Name, token.Ticker, token.Decimals, balance, lastActivity, frozen)
+	}
+	tabWriter.Flush()
+}
+
+// tokenValue returns a token balance in whole tokens so balances of tokens
+// with different decimals can be compared
+func tokenValue(token *wallet.KnownToken) *big.Rat {
+	value, ok := new(big.Rat).SetString(token.Balance)
+	if !ok {
+		return new(big.Rat)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.Decimals)), nil)
+	return value.Quo(value, new(big.Rat).SetInt(scale))
+}
+
+// tokenSupply mints or burns a decimal amount of a token issued by the wallet
+func tokenSupply(ctx context.Context, w *wallet.Wallet, action string, args []string) {
+	fs := flag.NewFlagSet("token "+action, flag.ExitOnError)
+	confirm := fs.Bool("confirm", false, "Confirm the irreversible "+action)
+	args = parseArgs(fs, args)
+
+	if len(args) < 2 {
+		fmt.Printf("Usage: tiny-client token %s <token_id> <amount> --confirm\n", action)
+		return
+	}
+	tokenID := args[0]
+
+	decimals, err := w.GetTokenDecimals(ctx, tokenID)
+	if err != nil {
+		log.Fatalf("Failed to get token decimals: %v", err)
+	}
+	amount, err := wallet.ParseTokenAmount(args[1], decimals)
+	if err != nil {
+		log.Fatalf("Invalid amount: %v", err)
+	}
+	if !*confirm {
+		log.Fatalf("Refusing to %s %s tokens (%s base units) of %s without --confirm: this can't be undone",
+			action, args[1], amount, tokenID)
+	}
+
+	var response *wallet.PaymentResponse
+	if action == "mint" {
+		response, err = w.MintToken(ctx, tokenID, amount)
+	} else {
+		response, err = w.BurnToken(ctx, tokenID, amount)
+	}
+	if err != nil {
+		log.Fatalf("Failed to %s token: %v", action, err)
+	}
+
+	if action == "mint" {
+		fmt.Println("Token Minted:")
+	} else {
+		fmt.Println("Token Burned:")
+	}
+	fmt.Printf("Payment ID:   %s\n", response.PaymentHash)
+	fmt.Printf("Token ID:     %s\n", tokenID)
+	fmt.Printf("Amount:       %s base units\n", amount.String())
+	fmt.Printf("Status:       %s\n", response.Status)
+}
+
+func tokenReceive(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("token receive", flag.ExitOnError)
+	watch := fs.Bool("watch", false, "Wait for the token balance to increase")
+	tokenID := fs.String("token-id", "", "Token identifier to watch")
+	timeout := fs.Int("timeout", 300, "Seconds to wait for an incoming transfer")
+	parseArgs(fs, args)
+
+	if !*watch || *tokenID == "" {
+		fmt.Println("Usage: tiny-client token receive --watch --token-id <id> [--timeout 300]")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(*timeout)*time.Second)
+	defer cancel()
+
+	fmt.Printf("Waiting for incoming %s transfer (timeout %ds)...\n", *tokenID, *timeout)
+	received, total, err := w.WaitForTokenBalanceIncrease(ctx, *tokenID, 5*time.Second)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Println("Timed out waiting for incoming token transfer")
+		// Exit code 2 lets scripts tell a timeout apart from an error
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Failed to wait for incoming token transfer: %v", err)
+	}
+
+	fmt.Println("Token Transfer Received:")
+	fmt.Printf("Token:       %s\n", *tokenID)
+	fmt.Printf("Amount:      %s\n", received)
+	fmt.Printf("New Balance: %s\n", total)
+}
+
+func tokenApprove(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 3 {
+		fmt.Println("Usage: tiny-client token approve <spender> <token_id> <amount>")
+		return
+	}
+	spender, tokenID := args[0], args[1]
+
+	decimals, err := w.GetTokenDecimals(ctx, tokenID)
+	if err != nil {
+		log.Fatalf("Failed to get token decimals: %v", err)
+	}
+	amount, err := wallet.ParseTokenAmount(args[2], decimals)
+	if err != nil {
+		log.Fatalf("Invalid amount: %v", err)
+	}
+
+	if err := w.ApproveTokenSpender(ctx, tokenID, spender, amount); err != nil {
+		log.Fatalf("Failed to approve token spender: %v", err)
+	}
+	fmt.Printf("Approved %s to spend %s base units of %s\n", spender, amount, tokenID)
+}
+
+func tokenAllowance(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: tiny-client token allowance <spender> <token_id>")
+		return
+	}
+
+	allowance, err := w.TokenAllowance(ctx, args[1], args[0])
+	if err != nil {
+		log.Fatalf("Failed to get token allowance: %v", err)
+	}
+	fmt.Printf("Allowance: %s base units\n", allowance)
+}
+
+func tokenRevoke(ctx context.Context, w *wallet.Wallet, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: tiny-client token revoke <spender> <token_id>")
+		return
+	}
+
+	if err := w.RevokeTokenSpender(ctx, args[1], args[0]); err != nil {
+		log.Fatalf("Failed to revoke token spender: %v", err)
+	}
+	fmt.Printf("Revoked the allowance of %s for %s\n", args[0], args[1])
+}
+
+func tokenMetadata(cfg *config.Config, args []string) {
+	if len(args) < 2 || args[0] != "set" {
+		fmt.Println("Usage: tiny-client token metadata set <token_id> [--name N] [--ticker T] [--decimals D] [--logo-url U]")
+		return
+	}
+
+	fs := flag.NewFlagSet("token metadata set", flag.ExitOnError)
+	name := fs.String("name", "", "Token name")
+	ticker := fs.String("ticker", "", "Token ticker")
+	decimals := fs.Int("decimals", -1, "Number of decimals the token uses")
+	logoURL := fs.String("logo-url", "", "URL of the token logo")
+	positional := parseArgs(fs, args[1:])
+	if len(positional) < 1 {
+		log.Fatalf("Token ID is required")
+	}
+	tokenID := positional[0]
+
+	override := wallet.TokenMetadataOverride{
+		Name:    *name,
+		Ticker:  *ticker,
+		LogoURL: *logoURL,
+	}
+	if *decimals >= 0 {
+		override.Decimals = decimals
+	}
+
+	if err := wallet.SetTokenMetadata(accountDir(cfg), tokenID, override); err != nil {
+		log.Fatalf("Failed to set token metadata: %v", err)
+	}
+	fmt.Printf("Updated metadata for token %s\n", tokenID)
+}
+
+func showTokenHistory(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("token history", flag.ExitOnError)
+	limit := fs.Int("limit", 20, "Number of most recent transfers to show (50 with --all-tokens)")
+	allTokens := fs.Bool("all-tokens", false, "Show the transfers of every held token in one table")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	asCSV := fs.Bool("csv", false, "Output as CSV")
+	args = parseArgs(fs, args)
+
+	if *allTokens {
+		limitSet := false
+		fs.Visit(func(f *flag.Flag) { limitSet = limitSet || f.Name == "limit" })
+		if !limitSet {
+			*limit = 50
+		}
+		showAllTokenHistory(ctx, w, *limit, *asJSON, *asCSV)
+		return
+	}
+
+	if len(args) < 1 {
+		fmt.Println("Usage: tiny-client token history <token_id> [--limit 20] [--json|--csv]")
+		fmt.Println("       tiny-client token history --all-tokens [--limit 50] [--json|--csv]")
+		return
+	}
+	tokenID := args[0]
+
+	transfers, err := w.GetTokenHistory(ctx, tokenID)
+	if err != nil {
+		log.Fatalf("Failed to get token history: %v", err)
+	}
+
+	// History is oldest first so the running balance accumulates; show newest first
+	if *limit > 0 && len(transfers) > *limit {
+		transfers = transfers[len(transfers)-*limit:]
+	}
+	for i, j := 0, len(transfers)-1; i < j; i, j = i+1, j-1 {
+		transfers[i], transfers[j] = transfers[j], transfers[i]
+	}
+
+	switch {
+	case *asJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(transfers); err != nil {
+			log.Fatalf("Failed to encode token history: %v", err)
+		}
+		return
+	case *asCSV:
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"timestamp", "direction", "amount", "fee", "counterparty", "status", "running_balance", "id"})
+		for _, t := range transfers {
+			writer.Write([]string{
+				t.Timestamp.Format("2006-01-02T15:04:05Z07:00"), t.Direction, t.Amount.String(), t.Fee.String(),
+				t.Counterparty, t.Status, t.RunningBalance.String(), t.ID,
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			log.Fatalf("Failed to write token history: %v", err)
+		}
+		return
+	}
+
+	fmt.Printf("Token History: %s\n", tokenID)
+	fmt.Println("--------------")
+
+	if len(transfers) == 0 {
+		fmt.Println("No token transfers found")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "TIME\tDIRECTION\tAMOUNT\tCOUNTERPARTY\tSTATUS\tBALANCE")
+	fmt.Fprintln(tabWriter, "----\t---------\t------\t------------\t------\t-------")
+
+	for _, t := range transfers {
+		counterparty := truncateString(t.Counterparty, 20)
+		if counterparty == "" {
+			counterparty = "-"
+		}
+		amount := t.Amount.String()
+		if t.Direction == "out" {
+			amount = "-" + amount
+		} else {
+			amount = "+" + amount
+		}
+
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Timestamp.Format("2006-01-02 15:04"), t.Direction, amount, counterparty, t.Status, t.RunningBalance.String())
+	}
+	tabWriter.Flush()
+}
+
+// showAllTokenHistory merges the transfer history of every held token, newest
+// first, showing amounts in whole tokens since the tokens' decimals differ
+func showAllTokenHistory(ctx context.Context, w *wallet.Wallet, limit int, asJSON, asCSV bool) {
+	tokens, err := w.GetTokenBalances(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get token balances: %v", err)
+	}
+
+	metadata := make(map[string]*wallet.TokenBalance, len(tokens))
+	var transfers []*wallet.TokenTransfer
+	for _, token := range tokens {
+		metadata[token.TokenID] = token
+		history, err := w.GetTokenHistory(ctx, token.TokenID)
+		if err != nil {
+			log.Fatalf("Failed to get token history: %v", err)
+		}
+		transfers = append(transfers, history...)
+	}
+
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].Timestamp.After(transfers[j].Timestamp)
+	})
+	if limit > 0 && len(transfers) > limit {
+		transfers = transfers[:limit]
+	}
+
+	// formatAmount shows a base unit amount in whole tokens with the ticker
+	formatAmount := func(t *wallet.TokenTransfer, amount *big.Int) string {
+		token := metadata[t.TokenID]
+		formatted := wallet.FormatTokenAmount(amount, token.Decimals)
+		if token.Ticker != "" {
+			formatted += " " + token.Ticker
+		}
+		return formatted
+	}
+	tokenName := func(t *wallet.TokenTransfer) string {
+		if ticker := metadata[t.TokenID].Ticker; ticker != "" {
+			return ticker
+		}
+		return truncateString(t.TokenID, 12)
+	}
+
+	switch {
+	case asJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(transfers); err != nil {
+			log.Fatalf("Failed to encode token history: %v", err)
+		}
+		return
+	case asCSV:
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"timestamp", "token_id", "direction", "amount", "fee", "counterparty", "status", "running_balance", "id"})
+		for _, t := range transfers {
+			writer.Write([]string{
+				t.Timestamp.Format("2006-01-02T15:04:05Z07:00"), t.TokenID, t.Direction, formatAmount(t, t.Amount), formatAmount(t, t.Fee),
+				t.Counterparty, t.Status, formatAmount(t, t.RunningBalance), t.ID,
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			log.Fatalf("Failed to write token history: %v", err)
+		}
+		return
+	}
+
+	fmt.Println("Token History: all tokens")
+	fmt.Println("--------------")
+
+	if len(transfers) == 0 {
+		fmt.Println("No token transfers found")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "TIME\tTOKEN\tDIRECTION\tAMOUNT\tCOUNTERPARTY\tSTATUS\tBALANCE")
+	fmt.Fprintln(tabWriter, "----\t-----\t---------\t------\t------------\t------\t-------")
+
+	for _, t := range transfers {
+		counterparty := truncateString(t.Counterparty, 20)
+		if counterparty == "" {
+			counterparty = "-"
+		}
+		sign := "+"
+		if t.Direction == "out" {
+			sign = "-"
+		}
+
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Timestamp.Format("2006-01-02 15:04"), tokenName(t), t.Direction, sign+formatAmount(t, t.Amount),
+			counterparty, t.Status, formatAmount(t, t.RunningBalance))
+	}
+	tabWriter.Flush()
+}
+
+// tokenFreeze freezes or unfreezes a token in the local freeze registry
+func tokenFreeze(cfg *config.Config, action string, args []string) {
+	if len(args) < 1 {
+		fmt.Printf("Usage: tiny-client token %s <token_id>\n", action)
+		return
+	}
+	tokenID := args[0]
+
+	if action == "freeze" {
+		frozen, err := wallet.FreezeToken(accountDir(cfg), tokenID)
+		if err != nil {
+			log.Fatalf("Failed to freeze token: %v", err)
+		}
+		if !frozen {
+			fmt.Printf("Token %s is already frozen\n", tokenID)
+			return
+		}
+		fmt.Printf("Froze token %s\n", tokenID)
+		fmt.Println("Note: the freeze only applies to this wallet; incoming transfers can't be refused")
+		return
+	}
+
+	unfrozen, err := wallet.UnfreezeToken(accountDir(cfg), tokenID)
+	if err != nil {
+		log.Fatalf("Failed to unfreeze token: %v", err)
+	}
+	if !unfrozen {
+		fmt.Printf("Token %s is not frozen\n", tokenID)
+		return
+	}
+	fmt.Printf("Unfroze token %s\n", tokenID)
+}
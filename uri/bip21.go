@@ -0,0 +1,66 @@
+package uri
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// satsPerBTC is the number of satoshis in one bitcoin
+const satsPerBTC = 100_000_000
+
+// GenerateBIP21 builds a bitcoin: URI for an address with an optional amount and label
+func GenerateBIP21(address string, amountSats uint64, label string) string {
+	var params []string
+	if amountSats > 0 {
+		params = append(params, "amount="+FormatBTCAmount(amountSats, false))
+	}
+	if label != "" {
+		params = append(params, "label="+queryEscape(label))
+	}
+
+	uri := "bitcoin:" + address
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+	return uri
+}
+
+// BTCToSats converts a decimal BTC amount to satoshis, rounding to the nearest satoshi
+func BTCToSats(amountBTC string) (uint64, error) {
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(amountBTC))
+	if !ok || amount.Sign() < 0 {
+		return 0, fmt.Errorf("invalid BTC amount: %q", amountBTC)
+	}
+
+	// Round half up to 8 decimal places
+	sats := new(big.Rat).Mul(amount, new(big.Rat).SetInt64(satsPerBTC))
+	sats.Add(sats, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(sats.Num(), sats.Denom())
+	if !rounded.IsUint64() {
+		return 0, fmt.Errorf("BTC amount %s is too large", amountBTC)
+	}
+	return rounded.Uint64(), nil
+}
+
+// FormatBTCAmount formats a satoshi amount as BTC. With trailingZeros it always
+// has 8 decimal places (0.00010000); without, trailing zeros are stripped but at
+// least one decimal place is kept (0.0001, 1.0).
+func FormatBTCAmount(sats uint64, trailingZeros bool) string {
+	amount := fmt.Sprintf("%d.%08d", sats/satsPerBTC, sats%satsPerBTC)
+	if trailingZeros {
+		return amount
+	}
+
+	amount = strings.TrimRight(amount, "0")
+	if strings.HasSuffix(amount, ".") {
+		amount += "0"
+	}
+	return amount
+}
+
+// queryEscape escapes a URI parameter value using %20 for spaces as BIP21 expects
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
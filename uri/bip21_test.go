@@ -0,0 +1,27 @@
+package uri
+
+import "testing"
+
+func TestFormatBTCAmount(t *testing.T) {
+	tests := []struct {
+		sats          uint64
+		trailingZeros bool
+		want          string
+	}{
+		{0, false, "0.0"},
+		{0, true, "0.00000000"},
+		{1, false, "0.00000001"},
+		{1, true, "0.00000001"},
+		{100_000_000, false, "1.0"},
+		{100_000_000, true, "1.00000000"},
+		{10_000, false, "0.0001"},
+		{10_000, true, "0.00010000"},
+		{123_456_789, false, "1.23456789"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBTCAmount(tt.sats, tt.trailingZeros); got != tt.want {
+			t.Errorf("FormatBTCAmount(%d, %t) = %q, want %q", tt.sats, tt.trailingZeros, got, tt.want)
+		}
+	}
+}
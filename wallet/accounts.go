@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// accountsFile records the accounts created in the working directory and
+	// the current one
+	accountsFile = "accounts.json"
+	// accountsDir holds the SDK storage of each account but the default one
+	accountsDir = "accounts"
+	// DefaultAccountName is the account derived the way the SDK always has,
+	// stored in the working directory itself
+	DefaultAccountName = "default"
+	// firstAccountNumber is the account number of the first created account.
+	// The SDK derives the default account with account number 0 on regtest and
+	// 1 elsewhere, so created accounts start above both.
+	firstAccountNumber uint32 = 2
+)
+
+// accountNamePattern matches valid account names, which are also directory names
+var accountNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// accountsMu serializes updates of the accounts file
+var accountsMu sync.Mutex
+
+// Account is a separate wallet derived from the same mnemonic with its own
+// account number, balance, payments and addresses. The default account has no
+// account number.
+type Account struct {
+	Name      string    `json:"name"`
+	Number    *uint32   `json:"number,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"-"`
+}
+
+// storageDir returns the directory the SDK stores the account's wallet in
+func (a Account) storageDir(workingDir string) string {
+	if a.Number == nil {
+		return workingDir
+	}
+	return filepath.Join(workingDir, accountsDir, a.Name)
+}
+
+// accountsState is the content of the accounts file
+type accountsState struct {
+	Current  string     `json:"current"`
+	Accounts []*Account `json:"accounts"`
+}
+
+// ListAccounts returns the default account followed by the created ones, with
+// the current account marked. It and the other package level account
+// functions only use the accounts file, so the accounts commands, and switching
+// in particular, work before a wallet connects; the Wallet methods of the same
+// names wrap them.
+func ListAccounts(workingDir string) ([]*Account, error) {
+	state, err := loadAccounts(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := append([]*Account{{Name: DefaultAccountName}}, state.Accounts...)
+	for _, account := range accounts {
+		account.Current = account.Name == state.Current || (state.Current == "" && account.Number == nil)
+	}
+	return accounts, nil
+}
+
+// CreateAccount creates an account with the next free account number. It
+// doesn't switch to it.
+func CreateAccount(workingDir, name string) (*Account, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !accountNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid account name %q: use up to 32 lowercase letters, digits, '-' and '_'", name)
+	}
+	if name == DefaultAccountName {
+		return nil, fmt.Errorf("account %s already exists", name)
+	}
+
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	state, err := loadAccounts(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	number := firstAccountNumber
+	for _, account := range state.Accounts {
+		if account.Name == name {
+			return nil, fmt.Errorf("account %s already exists", name)
+		}
+		if account.Number != nil && *account.Number >= number {
+			number = *account.Number + 1
+		}
+	}
+
+	account := &Account{Name: name, Number: &number, CreatedAt: time.Now()}
+	state.Accounts = append(state.Accounts, account)
+	if err := saveJSON(filepath.Join(workingDir, accountsFile), state); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// SwitchAccount makes the account the current one. It takes effect the next
+// time the wallet connects.
+func SwitchAccount(workingDir, name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	state, err := loadAccounts(workingDir)
+	if err != nil {
+		return err
+	}
+	if name != DefaultAccountName && findAccount(state.Accounts, name) == nil {
+		return fmt.Errorf("account %s not found", name)
+	}
+
+	state.Current = name
+	return saveJSON(filepath.Join(workingDir, accountsFile), state)
+}
+
+// ListAccounts returns the accounts of the wallet's working directory, see
+// the package level ListAccounts
+func (w *Wallet) ListAccounts(ctx context.Context) ([]Account, error) {
+	accounts, err := ListAccounts(w.config.BreezWorkingDir)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]Account, len(accounts))
+	for i, account := range accounts {
+		values[i] = *account
+	}
+	return values, nil
+}
+
+// CreateAccount creates an account in the wallet's working directory, see
+// the package level CreateAccount
+func (w *Wallet) CreateAccount(ctx context.Context, name string) (*Account, error) {
+	return CreateAccount(w.config.BreezWorkingDir, name)
+}
+
+// SwitchAccount makes the account named accountID the current one. The wallet
+// stays connected to its account; the switch takes effect the next time a
+// wallet connects.
+func (w *Wallet) SwitchAccount(ctx context.Context, accountID string) error {
+	return SwitchAccount(w.config.BreezWorkingDir, accountID)
+}
+
+// GetCurrentAccount returns the account the wallet is connected to
+func (w *Wallet) GetCurrentAccount() Account {
+	return w.account
+}
+
+// DataDir returns the directory of the connected account's local files
+func (w *Wallet) DataDir() string {
+	return w.dataDir
+}
+
+// AccountDataDir returns the directory of the current account's local files,
+// for commands that run without connecting. The default account keeps them in
+// the working directory itself, created accounts in their SDK storage
+// directory.
+func AccountDataDir(workingDir string) (string, error) {
+	account, err := currentAccount(workingDir)
+	if err != nil {
+		return "", err
+	}
+	return account.storageDir(workingDir), nil
+}
+
+// currentAccount returns the account to connect to, the default one if none
+// was switched to
+func currentAccount(workingDir string) (Account, error) {
+	state, err := loadAccounts(workingDir)
+	if err != nil {
+		return Account{}, err
+	}
+	if state.Current == "" || state.Current == DefaultAccountName {
+		return Account{Name: DefaultAccountName, Current: true}, nil
+	}
+
+	account := findAccount(state.Accounts, state.Current)
+	if account == nil {
+		return Account{}, fmt.Errorf("current account %s not found in %s", state.Current, accountsFile)
+	}
+	account.Current = true
+	return *account, nil
+}
+
+// findAccount returns the created account called name, nil if there is none
+func findAccount(accounts []*Account, name string) *Account {
+	for _, account := range accounts {
+		if account.Name == name {
+			return account
+		}
+	}
+	return nil
+}
+
+func loadAccounts(workingDir string) (*accountsState, error) {
+	state := &accountsState{}
+	if err := loadJSON(filepath.Join(workingDir, accountsFile), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// annotationsFile stores local notes about payments, keyed by payment ID
+const annotationsFile = "annotations.json"
+
+// annotationsMu serializes updates of the annotations file
+var annotationsMu sync.Mutex
+
+// Annotation is a note kept locally about a payment, with optional spending
+// category labels. The SDK has nowhere to store it, so it only exists in the
+// account data directory it was written in.
+type Annotation struct {
+	Memo      string    `json:"memo"`
+	Labels    []string  `json:"labels,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetPaymentMemo records a memo for a payment, replacing any earlier one
+func (w *Wallet) SetPaymentMemo(paymentID, memo string) error {
+	memo = strings.TrimSpace(memo)
+	if memo == "" {
+		return fmt.Errorf("memo cannot be empty")
+	}
+
+	annotationsMu.Lock()
+	defer annotationsMu.Unlock()
+
+	path := filepath.Join(w.dataDir, annotationsFile)
+	annotations, err := loadAnnotations(path)
+	if err != nil {
+		return err
+	}
+	annotation := &Annotation{
+		Memo:      memo,
+		CreatedAt: time.Now(),
+	}
+	if previous, ok := annotations[paymentID]; ok {
+		annotation.Labels = previous.Labels
+	}
+	annotations[paymentID] = annotation
+	return saveJSON(path, annotations)
+}
+
+// annotate marks probes, sets the memo and labels of the transactions that
+// have them, and uses the memo as the description of those that have no
+// other. The file is read once for all transactions; if it can't be read the
+// memos and labels are left out.
+func (w *Wallet) annotate(transactions ...*Transaction) {
+	w.markProbes(transactions...)
+
+	annotations, err := loadAnnotations(filepath.Join(w.dataDir, annotationsFile))
+	if err != nil {
+		slog.Warn("Failed to load payment annotations", "error", err)
+		return
+	}
+	if len(annotations) == 0 {
+		return
+	}
+
+	for _, tx := range transactions {
+		if annotation, ok := annotations[tx.ID]; ok {
+			tx.Memo = annotation.Memo
+			tx.Labels = annotation.Labels
+			if tx.Description == defaultDescription && annotation.Memo != "" {
+				tx.Description = annotation.Memo
+			}
+		}
+	}
+}
+
+// DisplayDescription returns the memo of a transaction if it has one, which
+// says more than the generic description the SDK reports for sent payments
+func (tx *Transaction) DisplayDescription() string {
+	if tx.Memo != "" {
+		return tx.Memo
+	}
+	return tx.Description
+}
+
+// annotationFields returns the note and labels of a payment for exports: an
+// empty note and no labels when it has no annotation
+func annotationFields(annotations map[string]*Annotation, paymentID string) (note string, labels []string) {
+	labels = []string{}
+	if annotation, ok := annotations[paymentID]; ok {
+		note = annotation.Memo
+		if annotation.Labels != nil {
+			labels = annotation.Labels
+		}
+	}
+	return note, labels
+}
+
+func loadAnnotations(path string) (map[string]*Annotation, error) {
+	annotations := make(map[string]*Annotation)
+	if err := loadJSON(path, &annotations); err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
@@ -0,0 +1,128 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// autopilotWindow is the history GenerateAutopilotSuggestions compares the
+// channel capacity against
+const autopilotWindow = 30 * 24 * time.Hour
+
+// AutopilotSuggestion is a channel management action suggested by
+// GenerateAutopilotSuggestions. Nothing is done automatically.
+type AutopilotSuggestion struct {
+	// Action is "open" or "close"
+	Action     string `json:"action"`
+	PeerPubkey string `json:"peer_pubkey,omitempty"`
+	ChannelID  string `json:"channel_id,omitempty"`
+	AmountSats int64  `json:"amount_sats,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// String describes the suggestion as a sentence
+func (s AutopilotSuggestion) String() string {
+	if s.Action == "close" {
+		return fmt.Sprintf("Consider closing inactive channel %s: %s", s.ChannelID, s.Reason)
+	}
+	peer := s.PeerPubkey
+	if peer == "" {
+		peer = "a well connected peer"
+	}
+	return fmt.Sprintf("Open channel with %s for %d sats to %s", peer, s.AmountSats, s.Reason)
+}
+
+// GenerateAutopilotSuggestions suggests channel actions from the channels and
+// the last 30 days of transaction history:
+//   - failed receives, or receives larger than the inbound capacity, suggest a
+//     channel for inbound capacity
+//   - sends larger than the outbound capacity suggest a channel for outbound
+//     capacity
+//   - channels open for more than 30 days without a payment in the last 30
+//     days are suggested for closing
+//
+// New channels are suggested with the peer of the largest reachable channel.
+func GenerateAutopilotSuggestions(ctx context.Context, channels []*Channel, txHistory []*Transaction) ([]AutopilotSuggestion, error) {
+	return generateAutopilotSuggestionsAt(channels, txHistory, time.Now()), nil
+}
+
+func generateAutopilotSuggestionsAt(channels []*Channel, txHistory []*Transaction, now time.Time) []AutopilotSuggestion {
+	var inbound, outbound int64
+	var peer *Channel
+	for _, ch := range channels {
+		if !ch.PeerReachable {
+			continue
+		}
+		outbound += ch.LocalBalanceSats
+		inbound += ch.CapacitySats - ch.LocalBalanceSats
+		if peer == nil || ch.CapacitySats > peer.CapacitySats {
+			peer = ch
+		}
+	}
+	peerPubkey := ""
+	if peer != nil {
+		peerPubkey = peer.PeerPubkey
+	}
+
+	var sent, received, failedReceived int64
+	for _, tx := range txHistory {
+		if now.Sub(tx.Timestamp) > autopilotWindow || tx.Type == probeType {
+			continue
+		}
+		amount := tx.AmountSats
+		if amount < 0 {
+			amount = -amount
+		}
+		switch {
+		case tx.Type == "send" && tx.Status == "Complete":
+			sent += amount
+		case tx.Type != "send" && tx.Status == "Failed":
+			failedReceived += amount
+		case tx.Type != "send" && tx.Status == "Complete":
+			received += amount
+		}
+	}
+
+	var suggestions []AutopilotSuggestion
+	// Failed receives ask for at least their amount even when the receives
+	// fit the inbound capacity in total
+	needed := max(received+failedReceived-inbound, failedReceived)
+	if needed > 0 {
+		suggestions = append(suggestions, AutopilotSuggestion{
+			Action:     "open",
+			PeerPubkey: peerPubkey,
+			AmountSats: needed,
+			Reason:     "improve inbound capacity",
+		})
+	}
+	if sent > outbound {
+		suggestions = append(suggestions, AutopilotSuggestion{
+			Action:     "open",
+			PeerPubkey: peerPubkey,
+			AmountSats: sent - outbound,
+			Reason:     "improve outbound capacity",
+		})
+	}
+	var inactive []*Channel
+	for _, ch := range channels {
+		if now.Sub(ch.OpenedAt) > autopilotWindow && now.Sub(ch.LastPaymentAt) > autopilotWindow {
+			inactive = append(inactive, ch)
+		}
+	}
+	sort.Slice(inactive, func(i, j int) bool { return inactive[i].LastPaymentAt.Before(inactive[j].LastPaymentAt) })
+	for _, ch := range inactive {
+		reason := "no payment in the last 30 days"
+		if !ch.LastPaymentAt.IsZero() {
+			reason = fmt.Sprintf("no payment since %s", ch.LastPaymentAt.Format("2006-01-02"))
+		}
+		suggestions = append(suggestions, AutopilotSuggestion{
+			Action:     "close",
+			PeerPubkey: ch.PeerPubkey,
+			ChannelID:  ch.ChannelID,
+			Reason:     reason,
+		})
+	}
+	return suggestions
+}
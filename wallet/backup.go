@@ -0,0 +1,28 @@
+package wallet
+
+import (
+	"fmt"
+	"os"
+)
+
+// VerifyBackup checks that a backup file can be decrypted with passphrase and
+// restored without touching the working directory. tiny-spark has no backup
+// file format to check against and can't open the SDK database without an
+// SQLite driver, so after checking that the file is readable this always
+// returns ErrNotSupported.
+func VerifyBackup(path, passphrase string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a backup file", path)
+	}
+	return ErrNotSupported
+}
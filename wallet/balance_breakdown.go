@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// BalanceBreakdown splits the wallet balance into its components. Spark
+// wallets are nodeless, so there is no Lightning channel balance.
+type BalanceBreakdown struct {
+	Balance
+	// SparkBalanceSats is the spendable balance held on Spark
+	SparkBalanceSats int64 `json:"spark_balance_sats"`
+	// ConfirmedDepositSats is confirmed on-chain deposits not claimed yet
+	ConfirmedDepositSats int64 `json:"confirmed_deposit_sats"`
+	// PendingDepositSats is on-chain deposits still waiting for confirmations
+	PendingDepositSats int64 `json:"pending_deposit_sats"`
+	// InFlightSats is the amount of outgoing payments that are still pending
+	InFlightSats int64           `json:"in_flight_sats"`
+	Tokens       []*TokenBalance `json:"tokens"`
+	// TotalSats is the Spark balance plus all on-chain deposits. Tokens are
+	// left out because the SDK has no token prices.
+	TotalSats int64 `json:"total_sats"`
+}
+
+// GetBalanceBreakdown retrieves the balance with its Spark, on-chain deposit,
+// in-flight and token components
+func (w *Wallet) GetBalanceBreakdown(ctx context.Context) (*BalanceBreakdown, error) {
+	defer logCall("GetBalanceBreakdown", time.Now())
+	balance, err := w.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := w.GetTokenBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	infoReq := breez_sdk_spark.GetInfoRequest{}
+	info, err := w.sdk.GetInfo(infoReq)
+	traceSDK("GetInfo", infoReq, info, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to get wallet info: %w", err)
+	}
+
+	breakdown := &BalanceBreakdown{
+		Balance:          *balance,
+		SparkBalanceSats: int64(info.BalanceSats),
+		Tokens:           tokens,
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	depositsReq := breez_sdk_spark.ListUnclaimedDepositsRequest{}
+	deposits, err := w.sdk.ListUnclaimedDeposits(depositsReq)
+	traceSDK("ListUnclaimedDeposits", depositsReq, deposits, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to list unclaimed deposits: %w", err)
+	}
+	for _, deposit := range deposits.Deposits {
+		if deposit.IsMature {
+			breakdown.ConfirmedDepositSats += int64(deposit.AmountSats)
+		} else {
+			breakdown.PendingDepositSats += int64(deposit.AmountSats)
+		}
+	}
+
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeSend}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusPending}
+	pending, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+		TypeFilter:   &typeFilter,
+		StatusFilter: &statusFilter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending payments: %w", err)
+	}
+	for _, payment := range pending {
+		// Token payments are in token base units
+		if payment.Method == breez_sdk_spark.PaymentMethodToken {
+			continue
+		}
+		breakdown.InFlightSats += payment.Amount.Int64()
+	}
+
+	breakdown.TotalSats = breakdown.SparkBalanceSats + breakdown.ConfirmedDepositSats + breakdown.PendingDepositSats
+	return breakdown, nil
+}
@@ -0,0 +1,146 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+const (
+	// balanceCacheTTL is how long a fetched balance is reused by the send pre-check
+	balanceCacheTTL = 5 * time.Second
+	// minFeeEstimateSats is the lower bound of the fee estimate used when the
+	// SDK doesn't quote a fee
+	minFeeEstimateSats = 1000
+)
+
+// ErrInsufficientFunds is returned by the send methods, before sending, when
+// the amount plus the fee quoted by the SDK is more than the wallet balance
+type ErrInsufficientFunds struct {
+	Available int64
+	Required  int64
+}
+
+func (e *ErrInsufficientFunds) Error() string {
+	return fmt.Sprintf("insufficient funds: %d sats available, %d sats required including the fee",
+		e.Available, e.Required)
+}
+
+// estimateFeeSats is a conservative upper bound of the fee of a payment:
+// 1% of the amount or 1000 sats, whichever is greater
+func estimateFeeSats(amountSats int64) int64 {
+	return max(amountSats/100, minFeeEstimateSats)
+}
+
+// checkBalance returns ErrInsufficientFunds if amountSats plus feeSats cannot
+// be paid from the wallet balance
+func (w *Wallet) checkBalance(ctx context.Context, amountSats, feeSats int64) error {
+	balance, err := w.cachedBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	required := amountSats + feeSats
+	if required > balance.MaxPayableSats {
+		return &ErrInsufficientFunds{Available: balance.MaxPayableSats, Required: required}
+	}
+	return nil
+}
+
+// cachedBalance returns the last fetched balance if it is less than
+// balanceCacheTTL old, fetching it otherwise
+func (w *Wallet) cachedBalance(ctx context.Context) (*Balance, error) {
+	w.balanceMu.Lock()
+	balance, fetchedAt := w.balance, w.balanceFetchedAt
+	w.balanceMu.Unlock()
+
+	if balance != nil && time.Since(fetchedAt) < balanceCacheTTL {
+		return balance, nil
+	}
+	return w.GetBalance(ctx)
+}
+
+// preparedFeeSats returns the fee the SDK quoted for a prepared payment. For
+// Lightning it is the routing fee, for on-chain payments the medium speed fee.
+func preparedFeeSats(prepareResp breez_sdk_spark.PrepareSendPaymentResponse) int64 {
+	switch method := prepareResp.PaymentMethod.(type) {
+	case breez_sdk_spark.SendPaymentMethodBolt11Invoice:
+		return int64(method.LightningFeeSats)
+	case breez_sdk_spark.SendPaymentMethodBitcoinAddress:
+		return speedFeeSats(method.FeeQuote.SpeedMedium)
+	case breez_sdk_spark.SendPaymentMethodSparkAddress:
+		return method.Fee.Int64()
+	case breez_sdk_spark.SendPaymentMethodSparkInvoice:
+		return method.Fee.Int64()
+	default:
+		return estimateFeeSats(prepareResp.Amount.Int64())
+	}
+}
+
+// invalidateBalance drops the cached balance after a send, so the next
+// pre-check sees the balance the send left
+func (w *Wallet) invalidateBalance() {
+	w.balanceMu.Lock()
+	defer w.balanceMu.Unlock()
+	w.balance = nil
+}
+
+// storeBalance records a fetched balance for the send pre-check
+func (w *Wallet) storeBalance(balance *Balance) {
+	w.balanceMu.Lock()
+	defer w.balanceMu.Unlock()
+	w.balance = balance
+	w.balanceFetchedAt = time.Now()
+}
+
+// InvoiceAmountSats decodes the amount of a BOLT11 invoice from its
+// human-readable part, returning false for invoices without an amount
+func InvoiceAmountSats(bolt11 string) (int64, bool) {
+	invoice := strings.ToLower(strings.TrimSpace(bolt11))
+	invoice = strings.TrimPrefix(invoice, "lightning:")
+	separator := strings.LastIndex(invoice, "1")
+	if separator < 0 {
+		return 0, false
+	}
+	hrp := invoice[:separator]
+
+	var amount string
+	for _, prefix := range []string{"lnbcrt", "lntbs", "lnsb", "lntb", "lnbc"} {
+		if strings.HasPrefix(hrp, prefix) {
+			amount = hrp[len(prefix):]
+			break
+		}
+	}
+	if amount == "" {
+		return 0, false
+	}
+
+	// The amount is in BTC, optionally scaled by a multiplier; picobitcoin
+	// amounts are rounded up to the next millisatoshi and every amount to the
+	// next satoshi, so the check never underestimates
+	var msatsPerUnit, divisor int64 = 100_000_000_000, 1
+	switch amount[len(amount)-1] {
+	case 'm':
+		msatsPerUnit = 100_000_000
+	case 'u':
+		msatsPerUnit = 100_000
+	case 'n':
+		msatsPerUnit = 100
+	case 'p':
+		msatsPerUnit, divisor = 1, 10
+	}
+	if amount[len(amount)-1] > '9' {
+		amount = amount[:len(amount)-1]
+	}
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	msats := (value*msatsPerUnit + divisor - 1) / divisor
+	return (msats + 999) / 1000, true
+}
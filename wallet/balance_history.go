@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// ErrNoBalanceHistory is returned by GetBalanceAt when the wallet had no
+// completed payments before the requested date
+var ErrNoBalanceHistory = errors.New("no payment history before this date")
+
+// GetBalanceAt reconstructs the balance in sats at asOfDate by summing the
+// completed Bitcoin payments made before it: receives are added, and sends are
+// subtracted together with their fees. Token payments and probes are left out.
+func (w *Wallet) GetBalanceAt(ctx context.Context, asOfDate time.Time) (int64, error) {
+	defer logCall("GetBalanceAt", time.Now())
+	until := uint64(asOfDate.Unix())
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	var assetFilter breez_sdk_spark.AssetFilter = breez_sdk_spark.AssetFilterBitcoin{}
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+		StatusFilter: &statusFilter,
+		AssetFilter:  &assetFilter,
+		ToTimestamp:  &until,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get payment history: %w", err)
+	}
+
+	var balance int64
+	found := false
+	for _, payment := range payments {
+		// The SDK filter is inclusive, but a payment at asOfDate is after it
+		if payment.Timestamp >= until {
+			continue
+		}
+		found = true
+		if w.isProbe(payment) {
+			continue
+		}
+		if payment.PaymentType == breez_sdk_spark.PaymentTypeSend {
+			balance -= payment.Amount.Int64() + payment.Fees.Int64()
+		} else {
+			balance += payment.Amount.Int64()
+		}
+	}
+	if !found {
+		return 0, ErrNoBalanceHistory
+	}
+	return balance, nil
+}
@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// blacklistFile is the file in the account data directory holding blacklisted node pubkeys
+const blacklistFile = "blacklist.json"
+
+// LoadBlacklist returns the blacklisted node public keys
+func LoadBlacklist(dataDir string) ([]string, error) {
+	var pubkeys []string
+	if err := loadJSON(filepath.Join(dataDir, blacklistFile), &pubkeys); err != nil {
+		return nil, err
+	}
+	return pubkeys, nil
+}
+
+// AddToBlacklist adds a node public key to the blacklist. It reports false if
+// the key was already blacklisted.
+func AddToBlacklist(dataDir, pubkey string) (bool, error) {
+	pubkey = strings.ToLower(strings.TrimSpace(pubkey))
+	if err := validateNodePubkey(pubkey); err != nil {
+		return false, err
+	}
+
+	pubkeys, err := LoadBlacklist(dataDir)
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(pubkeys, pubkey) {
+		return false, nil
+	}
+
+	pubkeys = append(pubkeys, pubkey)
+	return true, saveJSON(filepath.Join(dataDir, blacklistFile), pubkeys)
+}
+
+// RemoveFromBlacklist removes a node public key from the blacklist. It reports
+// false if the key was not blacklisted.
+func RemoveFromBlacklist(dataDir, pubkey string) (bool, error) {
+	pubkey = strings.ToLower(strings.TrimSpace(pubkey))
+
+	pubkeys, err := LoadBlacklist(dataDir)
+	if err != nil {
+		return false, err
+	}
+
+	index := slices.Index(pubkeys, pubkey)
+	if index < 0 {
+		return false, nil
+	}
+
+	pubkeys = slices.Delete(pubkeys, index, index+1)
+	return true, saveJSON(filepath.Join(dataDir, blacklistFile), pubkeys)
+}
+
+// validateNodePubkey checks that a string is a hex encoded compressed public key
+func validateNodePubkey(pubkey string) error {
+	decoded, err := hex.DecodeString(pubkey)
+	if err != nil || len(decoded) != 33 || (decoded[0] != 0x02 && decoded[0] != 0x03) {
+		return fmt.Errorf("invalid node public key: %s", pubkey)
+	}
+	return nil
+}
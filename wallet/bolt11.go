@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+const (
+	// bolt11TimestampWords is the length of the invoice timestamp in 5 bit words
+	bolt11TimestampWords = 7
+	// bolt11SignatureWords is the length of the signature ending the invoice
+	bolt11SignatureWords = 104
+	// bolt11DescriptionTag is the type of the tagged field holding the description
+	bolt11DescriptionTag = 13
+	// bolt11PaymentHashTag is the type of the tagged field holding the payment hash
+	bolt11PaymentHashTag = 1
+)
+
+// bolt11Description returns the description (d field) of a BOLT11 invoice.
+// Invoices that commit to a description hash have none.
+func bolt11Description(invoice string) (string, bool) {
+	description, ok := bolt11Field(invoice, bolt11DescriptionTag)
+	if !ok || len(description) == 0 {
+		return "", false
+	}
+	return string(description), true
+}
+
+// bolt11PaymentHash returns the hex payment hash (p field) of a BOLT11 invoice
+func bolt11PaymentHash(invoice string) (string, bool) {
+	hash, ok := bolt11Field(invoice, bolt11PaymentHashTag)
+	if !ok || len(hash) != 32 {
+		return "", false
+	}
+	return hex.EncodeToString(hash), true
+}
+
+// bolt11Field returns the first tagged field of a type in a BOLT11 invoice.
+// It decodes the invoice locally, without the SDK, so it works on any stored
+// invoice. The signature isn't checked.
+func bolt11Field(invoice string, tag byte) ([]byte, bool) {
+	invoice = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(invoice)), "lightning:")
+	if !strings.HasPrefix(invoice, "ln") {
+		return nil, false
+	}
+
+	_, data, err := bech32.DecodeNoLimit(invoice)
+	if err != nil || len(data) < bolt11TimestampWords+bolt11SignatureWords {
+		return nil, false
+	}
+
+	fields := data[bolt11TimestampWords : len(data)-bolt11SignatureWords]
+	for len(fields) >= 3 {
+		fieldTag := fields[0]
+		length := int(fields[1])<<5 | int(fields[2])
+		if len(fields) < 3+length {
+			return nil, false
+		}
+		value := fields[3 : 3+length]
+		fields = fields[3+length:]
+
+		if fieldTag != tag {
+			continue
+		}
+		decoded, err := bech32.ConvertBits(value, 5, 8, false)
+		if err != nil {
+			return nil, false
+		}
+		return decoded, true
+	}
+	return nil, false
+}
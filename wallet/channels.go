@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// ChannelOpenResponse describes a channel open that was initiated
+type ChannelOpenResponse struct {
+	ChannelID    string `json:"channel_id"`
+	FundingTxid  string `json:"funding_txid"`
+	PeerPubkey   string `json:"peer_pubkey"`
+	CapacitySats int64  `json:"capacity_sats"`
+	Private      bool   `json:"private"`
+}
+
+// OpenChannel opens a Lightning channel of amountSats with a peer. Spark
+// wallets are nodeless and the Spark operators provide liquidity, so the SDK
+// has no channel API and after validating the arguments this always returns
+// ErrNotSupported.
+func (w *Wallet) OpenChannel(ctx context.Context, peerPubkey string, amountSats int64, private bool) (*ChannelOpenResponse, error) {
+	defer logCall("OpenChannel", time.Now())
+	if decoded, err := hex.DecodeString(peerPubkey); err != nil || len(decoded) != 33 {
+		return nil, fmt.Errorf("invalid peer public key: %q", peerPubkey)
+	}
+	if amountSats <= 0 {
+		return nil, fmt.Errorf("invalid channel amount: %d sats", amountSats)
+	}
+	return nil, ErrNotSupported
+}
+
+// CloseChannel closes a Lightning channel cooperatively, or unilaterally with
+// force. Like OpenChannel it always returns ErrNotSupported.
+func (w *Wallet) CloseChannel(ctx context.Context, channelID string, force bool) error {
+	defer logCall("CloseChannel", time.Now())
+	if channelID == "" {
+		return fmt.Errorf("channel id is required")
+	}
+	return ErrNotSupported
+}
+
+// RebalanceResult describes a circular payment that moved liquidity between
+// channels
+type RebalanceResult struct {
+	AmountSats  int64  `json:"amount_sats"`
+	FeeSats     int64  `json:"fee_sats"`
+	PaymentHash string `json:"payment_hash"`
+	Executed    bool   `json:"executed"`
+}
+
+// RebalanceChannels moves amountSats of liquidity between channels with a
+// payment to self. Without channels there is no liquidity to rebalance, and
+// the SDK can't pay its own invoices, so after validating the amount this
+// always returns ErrNotSupported.
+func (w *Wallet) RebalanceChannels(ctx context.Context, amountSats int64) (*RebalanceResult, error) {
+	defer logCall("RebalanceChannels", time.Now())
+	if amountSats <= 0 {
+		return nil, fmt.Errorf("invalid rebalance amount: %d sats", amountSats)
+	}
+	return nil, ErrNotSupported
+}
+
+// ChannelHealth is the result of CheckChannelHealth
+type ChannelHealth string
+
+const (
+	ChannelHealthGood          ChannelHealth = "GOOD"
+	ChannelHealthLowLiquidity  ChannelHealth = "LOW_LIQUIDITY"
+	ChannelHealthHighLiquidity ChannelHealth = "HIGH_LIQUIDITY"
+	ChannelHealthInactive      ChannelHealth = "INACTIVE"
+	ChannelHealthUnreachable   ChannelHealth = "UNREACHABLE"
+)
+
+const (
+	// channelInactiveAfter is how long a channel can go without a payment
+	// before it is reported inactive
+	channelInactiveAfter = 24 * time.Hour
+	// minChannelLocalRatio and maxChannelLocalRatio bound a healthy share of
+	// the capacity on the local side
+	minChannelLocalRatio = 0.10
+	maxChannelLocalRatio = 0.90
+)
+
+// Channel is a Lightning channel with a peer
+type Channel struct {
+	ChannelID        string    `json:"channel_id"`
+	PeerPubkey       string    `json:"peer_pubkey"`
+	CapacitySats     int64     `json:"capacity_sats"`
+	LocalBalanceSats int64     `json:"local_balance_sats"`
+	PeerReachable    bool      `json:"peer_reachable"`
+	LastPaymentAt    time.Time `json:"last_payment_at"`
+	OpenedAt         time.Time `json:"opened_at"`
+}
+
+// ListChannels returns the wallet's Lightning channels. Spark wallets have no
+// channels of their own, so this always returns ErrNotSupported.
+func (w *Wallet) ListChannels(ctx context.Context) ([]*Channel, error) {
+	defer logCall("ListChannels", time.Now())
+	return nil, ErrNotSupported
+}
+
+// CheckChannelHealth computes the health of a channel from its fields. An
+// unreachable peer is reported first, then a channel without a payment in the
+// last 24 hours, then a local balance below 10% or above 90% of the capacity.
+func CheckChannelHealth(ch *Channel) ChannelHealth {
+	return checkChannelHealthAt(ch, time.Now())
+}
+
+func checkChannelHealthAt(ch *Channel, now time.Time) ChannelHealth {
+	switch {
+	case !ch.PeerReachable:
+		return ChannelHealthUnreachable
+	case now.Sub(ch.LastPaymentAt) > channelInactiveAfter:
+		return ChannelHealthInactive
+	}
+
+	if ch.CapacitySats <= 0 {
+		return ChannelHealthLowLiquidity
+	}
+	ratio := float64(ch.LocalBalanceSats) / float64(ch.CapacitySats)
+	switch {
+	case ratio < minChannelLocalRatio:
+		return ChannelHealthLowLiquidity
+	case ratio > maxChannelLocalRatio:
+		return ChannelHealthHighLiquidity
+	default:
+		return ChannelHealthGood
+	}
+}
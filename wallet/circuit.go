@@ -0,0 +1,143 @@
+package wallet
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// ErrCircuitOpen is returned without calling the SDK while the circuit is open
+var ErrCircuitOpen = errors.New("circuit open: too many consecutive SDK failures, try again later")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all calls through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all calls until the reset timeout elapses
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial call through to test recovery
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops calls to the SDK after repeated consecutive failures
+type CircuitBreaker struct {
+	mu           sync.Mutex
+	state        CircuitState
+	failures     int
+	threshold    int
+	resetTimeout time.Duration
+	changedAt    time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker that opens after threshold
+// consecutive failures and tests recovery once resetTimeout has elapsed
+func NewCircuitBreaker(threshold int, resetTimeout time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		state:        CircuitClosed,
+		threshold:    threshold,
+		resetTimeout: resetTimeout,
+		changedAt:    time.Now(),
+	}
+}
+
+// State returns the current state of the circuit
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Allow returns ErrCircuitOpen if a call should not be attempted
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.changedAt) < cb.resetTimeout {
+			return ErrCircuitOpen
+		}
+		// This caller becomes the trial call
+		cb.setState(CircuitHalfOpen)
+		return nil
+	case CircuitHalfOpen:
+		// Only one trial at a time, unless the trial never reported back
+		if time.Since(cb.changedAt) < cb.resetTimeout {
+			return ErrCircuitOpen
+		}
+		cb.changedAt = time.Now()
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record updates the circuit with the outcome of a call
+func (cb *CircuitBreaker) Record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	// User errors show the SDK is responding, so they count as successes
+	if err == nil || isUserError(err) {
+		cb.failures = 0
+		if cb.state != CircuitClosed {
+			cb.setState(CircuitClosed)
+		}
+		return
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || (cb.state == CircuitClosed && cb.failures >= cb.threshold) {
+		cb.setState(CircuitOpen)
+	}
+}
+
+// setState transitions the circuit; the caller must hold the lock
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	slog.Warn("Wallet circuit breaker state changed",
+		"from", cb.state.String(),
+		"to", state.String(),
+		"consecutive_failures", cb.failures)
+	cb.state = state
+	cb.changedAt = time.Now()
+}
+
+// isUserError reports whether an SDK error was caused by the request rather
+// than by a broken SDK connection
+func isUserError(err error) bool {
+	return errors.Is(err, breez_sdk_spark.ErrSdkErrorInsufficientFunds) ||
+		errors.Is(err, breez_sdk_spark.ErrSdkErrorInvalidInput) ||
+		errors.Is(err, breez_sdk_spark.ErrSdkErrorInvalidUuid)
+}
+
+// failed records the outcome of an SDK call on the circuit breaker and reports
+// whether the call returned an SDK error
+func (w *Wallet) failed(err error) bool {
+	if !isSdkError(err) {
+		w.breaker.Record(nil)
+		return false
+	}
+	w.breaker.Record(err)
+	return true
+}
@@ -0,0 +1,20 @@
+package wallet
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SendLightningInvoiceWithComment pays a BOLT11 invoice with a message for the
+// recipient. The SDK can't attach a keysend TLV record (type 34349334) or a
+// BOLT12 payer note to a payment, so the comment is dropped with a warning and
+// the invoice is still paid. LNURL-pay requests carry comments through LnUrlPay.
+func (w *Wallet) SendLightningInvoiceWithComment(ctx context.Context, bolt11, comment string) (*PaymentResponse, error) {
+	defer logCall("SendLightningInvoiceWithComment", time.Now())
+	if comment != "" {
+		slog.Warn("Payment comments are not supported for this invoice type, sending without it",
+			"comment_length", len(comment))
+	}
+	return w.SendLightningInvoice(ctx, bolt11)
+}
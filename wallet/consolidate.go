@@ -0,0 +1,21 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SendBitcoinAddressConsolidating sends Bitcoin to an on-chain address while
+// preferring to spend many small inputs, paying more now to save on later
+// transactions. Spark wallets hold no UTXOs of their own: withdrawals are
+// built and funded by the Spark operators, and the SDK has no coin selection
+// options. After validating the amount this always returns ErrNotSupported,
+// until the SDK exposes input selection for withdrawals.
+func (w *Wallet) SendBitcoinAddressConsolidating(ctx context.Context, address string, amountSats int64, speed ConfirmationSpeed) (*PaymentResponse, error) {
+	defer logCall("SendBitcoinAddressConsolidating", time.Now())
+	if amountSats <= 0 {
+		return nil, fmt.Errorf("invalid amount: %d sats", amountSats)
+	}
+	return nil, ErrNotSupported
+}
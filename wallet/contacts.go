@@ -0,0 +1,174 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// ContactsExportVersion is the version of the contacts export format
+const ContactsExportVersion = 1
+
+// Contact is an entry of the SDK contact book
+type Contact struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ContactsExport is the portable contacts format. The version lets future
+// releases add contact fields while still reading older files.
+type ContactsExport struct {
+	Version  int             `json:"version"`
+	Contacts []ContactExport `json:"contacts"`
+}
+
+// ContactExport is a single contact in a ContactsExport
+type ContactExport struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Type    string `json:"type"`
+}
+
+// ListContacts returns all contacts
+func (w *Wallet) ListContacts(ctx context.Context) ([]*Contact, error) {
+	defer logCall("ListContacts", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	const pageSize = 100
+	var contacts []*Contact
+
+	for offset := uint32(0); ; offset += pageSize {
+		pageOffset := offset
+		limit := uint32(pageSize)
+		request := breez_sdk_spark.ListContactsRequest{
+			Offset: &pageOffset,
+			Limit:  &limit,
+		}
+		response, err := w.sdk.ListContacts(request)
+		traceSDK("ListContacts", request, response, err)
+		if w.failed(err) {
+			return nil, fmt.Errorf("failed to list contacts: %w", err)
+		}
+
+		for _, contact := range response {
+			contacts = append(contacts, &Contact{
+				ID:        contact.Id,
+				Name:      contact.Name,
+				Address:   contact.PaymentIdentifier,
+				Type:      contactType(contact.PaymentIdentifier),
+				CreatedAt: time.Unix(int64(contact.CreatedAt), 0),
+				UpdatedAt: time.Unix(int64(contact.UpdatedAt), 0),
+			})
+		}
+
+		if len(response) < pageSize {
+			return contacts, nil
+		}
+	}
+}
+
+// ExportContacts returns all contacts in the portable contacts format
+func (w *Wallet) ExportContacts(ctx context.Context) (*ContactsExport, error) {
+	defer logCall("ExportContacts", time.Now())
+	contacts, err := w.ListContacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ContactsExport{
+		Version:  ContactsExportVersion,
+		Contacts: make([]ContactExport, len(contacts)),
+	}
+	for i, contact := range contacts {
+		export.Contacts[i] = ContactExport{
+			Name:    contact.Name,
+			Address: contact.Address,
+			Type:    contact.Type,
+		}
+	}
+	return export, nil
+}
+
+// ImportContacts adds the contacts of an export. With replace every existing
+// contact is deleted first; otherwise contacts whose name or address is
+// already in the contact book are skipped. It returns the number added and skipped.
+func (w *Wallet) ImportContacts(ctx context.Context, export *ContactsExport, replace bool) (added, skipped int, err error) {
+	defer logCall("ImportContacts", time.Now())
+	if export.Version > ContactsExportVersion {
+		return 0, 0, fmt.Errorf("unsupported contacts export version %d", export.Version)
+	}
+
+	existing, err := w.ListContacts(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	known := make(map[string]bool)
+	if replace {
+		for _, contact := range existing {
+			if err := w.breaker.Allow(); err != nil {
+				return 0, 0, err
+			}
+			err := w.sdk.DeleteContact(contact.ID)
+			traceSDK("DeleteContact", contact.ID, nil, err)
+			if w.failed(err) {
+				return 0, 0, fmt.Errorf("failed to delete contact %s: %w", contact.Name, err)
+			}
+		}
+	} else {
+		for _, contact := range existing {
+			known["name:"+strings.ToLower(contact.Name)] = true
+			known["address:"+strings.ToLower(contact.Address)] = true
+		}
+	}
+
+	for _, contact := range export.Contacts {
+		nameKey := "name:" + strings.ToLower(contact.Name)
+		addressKey := "address:" + strings.ToLower(contact.Address)
+		if known[nameKey] || known[addressKey] {
+			skipped++
+			continue
+		}
+
+		if err := w.breaker.Allow(); err != nil {
+			return added, skipped, err
+		}
+		request := breez_sdk_spark.AddContactRequest{
+			Name:              contact.Name,
+			PaymentIdentifier: contact.Address,
+		}
+		_, err := w.sdk.AddContact(request)
+		traceSDK("AddContact", request, nil, err)
+		if w.failed(err) {
+			return added, skipped, fmt.Errorf("failed to add contact %s: %w", contact.Name, err)
+		}
+
+		known[nameKey] = true
+		known[addressKey] = true
+		added++
+	}
+
+	return added, skipped, nil
+}
+
+// contactType classifies a contact's payment identifier
+func contactType(address string) string {
+	lower := strings.ToLower(address)
+	switch {
+	case strings.Contains(lower, "@"):
+		return "lightning"
+	case strings.HasPrefix(lower, "spark1"), strings.HasPrefix(lower, "sp1"), strings.HasPrefix(lower, "sparkrt1"):
+		return "spark"
+	default:
+		return "unknown"
+	}
+}
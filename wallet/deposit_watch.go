@@ -0,0 +1,106 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// DepositWatchInterval is how often WaitForOnchainDeposit polls for deposits
+const DepositWatchInterval = 30 * time.Second
+
+// OnchainDeposit is an incoming on-chain payment found by WaitForOnchainDeposit
+type OnchainDeposit struct {
+	AmountSats int64
+	// Txid is only known once the SDK has claimed the deposit
+	Txid string
+	// Claimed reports whether the deposit was already added to the balance
+	Claimed bool
+}
+
+// WaitForOnchainDeposit polls until an on-chain deposit arrives or the context
+// is done. A deposit is detected when the confirmed deposit balance grows or a
+// new claimed deposit shows up in the payment history. The SDK doesn't record
+// the address a deposit was sent to, but it uses a single deposit address per
+// wallet, so any deposit is to the address ReceiveBitcoinAddress returned.
+func (w *Wallet) WaitForOnchainDeposit(ctx context.Context, interval time.Duration) (*OnchainDeposit, error) {
+	breakdown, err := w.GetBalanceBreakdown(ctx)
+	if err != nil {
+		return nil, err
+	}
+	previous := breakdown.ConfirmedDepositSats
+
+	known, err := w.claimedDeposits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		claimed, err := w.claimedDeposits(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for id, deposit := range claimed {
+			if _, ok := known[id]; !ok {
+				return deposit, nil
+			}
+		}
+
+		breakdown, err := w.GetBalanceBreakdown(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if current := breakdown.ConfirmedDepositSats; current > previous {
+			return &OnchainDeposit{AmountSats: current - previous}, nil
+		}
+		// Claimed deposits leave the confirmed balance, so track decreases too
+		previous = breakdown.ConfirmedDepositSats
+	}
+}
+
+// claimedDeposits returns the recent completed deposit payments by payment ID
+func (w *Wallet) claimedDeposits(ctx context.Context) (map[string]*OnchainDeposit, error) {
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	limit := uint32(100)
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeReceive}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	request := breez_sdk_spark.ListPaymentsRequest{
+		TypeFilter:   &typeFilter,
+		StatusFilter: &statusFilter,
+		Limit:        &limit,
+	}
+	response, err := w.sdk.ListPayments(request)
+	traceSDK("ListPayments", request, response, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to list deposits: %w", err)
+	}
+
+	deposits := make(map[string]*OnchainDeposit)
+	for _, payment := range response.Payments {
+		if payment.Method != breez_sdk_spark.PaymentMethodDeposit {
+			continue
+		}
+		deposit := &OnchainDeposit{AmountSats: payment.Amount.Int64(), Claimed: true}
+		if payment.Details != nil {
+			if details, ok := (*payment.Details).(breez_sdk_spark.PaymentDetailsDeposit); ok {
+				deposit.Txid = details.TxId
+			}
+		}
+		deposits[payment.Id] = deposit
+	}
+	return deposits, nil
+}
@@ -0,0 +1,21 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// ReceiveLightningInvoiceWithDescriptionHash creates a Lightning invoice that
+// commits to the SHA256 hash of a description (BOLT11 h field) instead of
+// embedding the description itself. The SDK's Bolt11 receive method only takes
+// a plaintext description, so after validating the hash this always returns
+// ErrNotSupported.
+func (w *Wallet) ReceiveLightningInvoiceWithDescriptionHash(ctx context.Context, amountSats uint64, descriptionHash string) (*ReceivePaymentResponse, error) {
+	defer logCall("ReceiveLightningInvoiceWithDescriptionHash", time.Now())
+	if decoded, err := hex.DecodeString(descriptionHash); err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("invalid description hash %q: must be a hex encoded SHA256 hash", descriptionHash)
+	}
+	return nil, ErrNotSupported
+}
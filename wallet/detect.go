@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+	"github.com/breez/tiny-spark/detect"
+)
+
+// DetectInputType classifies a payment destination, by its format where
+// possible and otherwise by parsing it with the SDK
+func (w *Wallet) DetectInputType(ctx context.Context, input string) (detect.InputTypeHint, error) {
+	defer logCall("DetectInputType", time.Now())
+	if hint, err := detect.Detect(input); err == nil {
+		return hint, nil
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return detect.Unknown, err
+	}
+	parsed, err := w.sdk.Parse(input)
+	traceSDK("Parse", input, parsed, err)
+	if w.failed(err) {
+		return detect.Unknown, fmt.Errorf("failed to parse payment destination: %w", err)
+	}
+
+	switch parsed.(type) {
+	case breez_sdk_spark.InputTypeBolt11Invoice:
+		return detect.Bolt11, nil
+	case breez_sdk_spark.InputTypeBolt12Offer, breez_sdk_spark.InputTypeBolt12Invoice:
+		return detect.Bolt12, nil
+	case breez_sdk_spark.InputTypeBitcoinAddress:
+		return detect.BitcoinAddress, nil
+	case breez_sdk_spark.InputTypeSparkAddress, breez_sdk_spark.InputTypeSparkInvoice:
+		return detect.SparkAddress, nil
+	case breez_sdk_spark.InputTypeLightningAddress:
+		return detect.LightningAddress, nil
+	case breez_sdk_spark.InputTypeLnurlPay:
+		return detect.LNURL, nil
+	case breez_sdk_spark.InputTypeBip21:
+		return detect.BIP21, nil
+	}
+	return detect.Unknown, detect.ErrUnknownInput
+}
@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// ErrRetryUnavailable is returned when a payment can't be retried
+var ErrRetryUnavailable = errors.New("payment can't be retried")
+
+// ListFailedPayments returns up to limit failed payments created at or after
+// since, newest first. The SDK doesn't record why a payment failed.
+func (w *Wallet) ListFailedPayments(ctx context.Context, since time.Time, limit int) ([]*Transaction, error) {
+	defer logCall("ListFailedPayments", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusFailed}
+	offset := uint32(0)
+	pageLimit := uint32(limit)
+	req := breez_sdk_spark.ListPaymentsRequest{
+		StatusFilter: &statusFilter,
+		Offset:       &offset,
+		Limit:        &pageLimit,
+	}
+	if !since.IsZero() {
+		fromTimestamp := uint64(since.Unix())
+		req.FromTimestamp = &fromTimestamp
+	}
+
+	response, err := w.sdk.ListPayments(req)
+	traceSDK("ListPayments", req, response, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to list failed payments: %w", err)
+	}
+
+	transactions := make([]*Transaction, len(response.Payments))
+	for i, payment := range response.Payments {
+		transactions[i] = transactionFromPayment(payment)
+	}
+	return transactions, nil
+}
+
+// RetryPayment pays the destination of a failed payment again. Only Lightning
+// payments can be retried: the SDK keeps their invoice, but not the address of
+// failed Spark or on-chain payments.
+func (w *Wallet) RetryPayment(ctx context.Context, paymentID string) (*PaymentResponse, error) {
+	defer logCall("RetryPayment", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	req := breez_sdk_spark.GetPaymentRequest{PaymentId: paymentID}
+	response, err := w.sdk.GetPayment(req)
+	traceSDK("GetPayment", req, response, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	payment := response.Payment
+	if payment.PaymentType != breez_sdk_spark.PaymentTypeSend || payment.Status != breez_sdk_spark.PaymentStatusFailed {
+		return nil, fmt.Errorf("%w: %s is not a failed outgoing payment", ErrRetryUnavailable, paymentID)
+	}
+
+	if payment.Details != nil {
+		if details, ok := (*payment.Details).(breez_sdk_spark.PaymentDetailsLightning); ok && details.Invoice != "" {
+			return w.SendLightningInvoice(ctx, details.Invoice)
+		}
+	}
+	return nil, fmt.Errorf("%w: the destination of %s is not known", ErrRetryUnavailable, paymentID)
+}
@@ -0,0 +1,23 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// BumpFee replaces an unconfirmed on-chain transaction sent with RBF signaling
+// by one spending the same inputs at newFeeRateSatPerVbyte. The SDK builds
+// on-chain withdrawals itself and has no fee bump API, so after validating the
+// arguments this always returns ErrNotSupported.
+func (w *Wallet) BumpFee(ctx context.Context, txid string, newFeeRateSatPerVbyte int64) (*PaymentResponse, error) {
+	defer logCall("BumpFee", time.Now())
+	if decoded, err := hex.DecodeString(txid); err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("invalid transaction id: %q", txid)
+	}
+	if newFeeRateSatPerVbyte < 1 {
+		return nil, fmt.Errorf("invalid fee rate: %d sat/vbyte", newFeeRateSatPerVbyte)
+	}
+	return nil, ErrNotSupported
+}
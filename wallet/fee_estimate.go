@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// Fee estimate confidence levels
+const (
+	// FeeConfidenceExact is a fee quoted by the SDK for the route it found
+	FeeConfidenceExact = "exact"
+	// FeeConfidenceApproximate is the conservative upper bound of the balance
+	// pre-check, used when the SDK doesn't quote a Lightning fee
+	FeeConfidenceApproximate = "approximate"
+)
+
+// FeeEstimate is the expected fee of a payment
+type FeeEstimate struct {
+	AmountSats int64  `json:"amount_sats"`
+	FeeSats    int64  `json:"fee_sats"`
+	Confidence string `json:"confidence"`
+}
+
+// EstimateLightningFee prepares a Lightning payment without sending it and
+// returns its fee. amountSats is only needed for invoices without an amount.
+func (w *Wallet) EstimateLightningFee(ctx context.Context, bolt11 string, amountSats *uint64) (*FeeEstimate, error) {
+	defer logCall("EstimateLightningFee", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	prepareReq := breez_sdk_spark.PrepareSendPaymentRequest{
+		PaymentRequest: bolt11,
+	}
+	if amountSats != nil {
+		amount := new(big.Int).SetUint64(*amountSats)
+		prepareReq.Amount = &amount
+	}
+
+	prepareResp, err := w.sdk.PrepareSendPayment(prepareReq)
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to prepare lightning payment: %w", err)
+	}
+
+	amount := prepareResp.Amount.Int64()
+	if method, ok := prepareResp.PaymentMethod.(breez_sdk_spark.SendPaymentMethodBolt11Invoice); ok {
+		return &FeeEstimate{
+			AmountSats: amount,
+			FeeSats:    int64(method.LightningFeeSats),
+			Confidence: FeeConfidenceExact,
+		}, nil
+	}
+
+	return &FeeEstimate{
+		AmountSats: amount,
+		FeeSats:    estimateFeeSats(amount),
+		Confidence: FeeConfidenceApproximate,
+	}, nil
+}
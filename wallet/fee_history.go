@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// FeeHistoryBucket summarizes the fees of completed payments in a period
+type FeeHistoryBucket struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Transactions  int       `json:"transactions"`
+	TotalFeesSats int64     `json:"total_fees_sats"`
+	MaxFeeSats    int64     `json:"max_fee_sats"`
+	TotalSentSats int64     `json:"total_sent_sats"`
+}
+
+// AverageFeeSats returns the average fee per transaction
+func (b *FeeHistoryBucket) AverageFeeSats() float64 {
+	if b.Transactions == 0 {
+		return 0
+	}
+	return float64(b.TotalFeesSats) / float64(b.Transactions)
+}
+
+// FeePercent returns the fees as a percentage of the amount sent
+func (b *FeeHistoryBucket) FeePercent() float64 {
+	if b.TotalSentSats == 0 {
+		return 0
+	}
+	return float64(b.TotalFeesSats) / float64(b.TotalSentSats) * 100
+}
+
+// ComputeFeeHistory summarizes the fees of completed bitcoin payments between
+// since and until, oldest first. A zero since or until leaves that end of the
+// range open. groupBy is "day", "week", "month", or empty for a single bucket
+// covering the whole range.
+func (w *Wallet) ComputeFeeHistory(ctx context.Context, since, until time.Time, groupBy string) ([]FeeHistoryBucket, error) {
+	defer logCall("ComputeFeeHistory", time.Now())
+	switch groupBy {
+	case "", "day", "week", "month":
+	default:
+		return nil, fmt.Errorf("invalid grouping %q: must be day, week or month", groupBy)
+	}
+
+	sortAscending := true
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	var assetFilter breez_sdk_spark.AssetFilter = breez_sdk_spark.AssetFilterBitcoin{}
+	req := breez_sdk_spark.ListPaymentsRequest{
+		StatusFilter:  &statusFilter,
+		AssetFilter:   &assetFilter,
+		SortAscending: &sortAscending,
+	}
+	if !since.IsZero() {
+		from := uint64(since.Unix())
+		req.FromTimestamp = &from
+	}
+	if !until.IsZero() {
+		to := uint64(until.Unix())
+		req.ToTimestamp = &to
+	}
+
+	payments, err := w.listAllPayments(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment history: %w", err)
+	}
+
+	var buckets []FeeHistoryBucket
+	if groupBy == "" {
+		buckets = append(buckets, FeeHistoryBucket{Start: since, End: until})
+	}
+
+	for _, payment := range payments {
+		timestamp := time.Unix(int64(payment.Timestamp), 0)
+
+		if groupBy != "" {
+			start, end := bucketRange(timestamp, groupBy)
+			if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+				buckets = append(buckets, FeeHistoryBucket{Start: start, End: end})
+			}
+		}
+		bucket := &buckets[len(buckets)-1]
+
+		fee := payment.Fees.Int64()
+		bucket.Transactions++
+		bucket.TotalFeesSats += fee
+		if fee > bucket.MaxFeeSats {
+			bucket.MaxFeeSats = fee
+		}
+		if payment.PaymentType == breez_sdk_spark.PaymentTypeSend {
+			bucket.TotalSentSats += payment.Amount.Int64()
+		}
+	}
+
+	return buckets, nil
+}
+
+// bucketRange returns the local day, week (starting Monday) or month holding t
+func bucketRange(t time.Time, groupBy string) (time.Time, time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch groupBy {
+	case "week":
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 7)
+	case "month":
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return day, day.AddDate(0, 0, 1)
+	}
+}
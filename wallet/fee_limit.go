@@ -0,0 +1,38 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrFeeTooHigh is returned without paying when the prepared fee of a payment
+// is above the caller's FeeLimit
+var ErrFeeTooHigh = errors.New("fee too high")
+
+// FeeLimit is the most a payment may cost in fees. Zero values mean no limit;
+// when both are set the fee must be within both.
+type FeeLimit struct {
+	// MaxFeePercent is a percentage of the amount, 0.5 for 0.5%
+	MaxFeePercent float64
+	MaxFeeSats    int64
+}
+
+// check returns ErrFeeTooHigh if feeSats is above the limit for amountSats.
+// A fee exactly at the limit is allowed.
+func (l FeeLimit) check(feeSats, amountSats uint64) error {
+	if l.MaxFeeSats > 0 && feeSats > uint64(l.MaxFeeSats) {
+		return fmt.Errorf("%w: %d sats is above the limit of %d sats", ErrFeeTooHigh, feeSats, l.MaxFeeSats)
+	}
+	if l.MaxFeePercent > 0 {
+		// Compare fee*100 to percent*amount, with room for the rounding of
+		// percentages like 0.3 that have no exact binary form
+		limit := l.MaxFeePercent * float64(amountSats)
+		if float64(feeSats)*100 > limit*(1+1e-9) {
+			return fmt.Errorf("%w: %d sats is %.2f%% of %d sats, above the limit of %s%%", ErrFeeTooHigh,
+				feeSats, float64(feeSats)*100/float64(amountSats), amountSats,
+				strconv.FormatFloat(l.MaxFeePercent, 'f', -1, 64))
+		}
+	}
+	return nil
+}
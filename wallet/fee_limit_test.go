@@ -0,0 +1,39 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFeeLimitCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      FeeLimit
+		feeSats    uint64
+		amountSats uint64
+		wantErr    bool
+	}{
+		{"no limit", FeeLimit{}, 1_000_000, 1, false},
+		{"absolute limit exactly reached", FeeLimit{MaxFeeSats: 10}, 10, 1_000, false},
+		{"absolute limit one sat over", FeeLimit{MaxFeeSats: 10}, 11, 1_000, true},
+		{"percent limit exactly reached", FeeLimit{MaxFeePercent: 1}, 10, 1_000, false},
+		{"percent limit one sat over", FeeLimit{MaxFeePercent: 1}, 11, 1_000, true},
+		{"inexact percent exactly reached", FeeLimit{MaxFeePercent: 0.3}, 3, 1_000, false},
+		{"inexact percent one sat over", FeeLimit{MaxFeePercent: 0.3}, 4, 1_000, true},
+		{"both limits reached", FeeLimit{MaxFeePercent: 1, MaxFeeSats: 10}, 10, 1_000, false},
+		{"within the percent but over the sats limit", FeeLimit{MaxFeePercent: 5, MaxFeeSats: 10}, 11, 1_000, true},
+		{"within the sats but over the percent limit", FeeLimit{MaxFeePercent: 1, MaxFeeSats: 100}, 11, 1_000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limit.check(tt.feeSats, tt.amountSats)
+			if tt.wantErr && !errors.Is(err, ErrFeeTooHigh) {
+				t.Errorf("check(%d, %d) = %v, want ErrFeeTooHigh", tt.feeSats, tt.amountSats, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("check(%d, %d) = %v, want nil", tt.feeSats, tt.amountSats, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// NotableFeePercent is the fee, as a percentage of the amount sent, above
+// which ComputeFeeReport lists a payment as notable
+const NotableFeePercent = 1.0
+
+// FeeReportMethods are the payment methods a fee report groups sends by, in
+// the order they are reported
+var FeeReportMethods = []string{"lightning", "bitcoin", "spark"}
+
+// FeeReportGroup summarizes the completed sends of one payment method
+type FeeReportGroup struct {
+	Method   string `json:"method"`
+	Payments int    `json:"payments"`
+	SentSats int64  `json:"sent_sats"`
+	FeesSats int64  `json:"fees_sats"`
+}
+
+// FeePercent returns the fees as a percentage of the amount sent
+func (g *FeeReportGroup) FeePercent() float64 {
+	if g.SentSats == 0 {
+		return 0
+	}
+	return float64(g.FeesSats) / float64(g.SentSats) * 100
+}
+
+// NotableFee is a send whose fee is above NotableFeePercent of its amount
+type NotableFee struct {
+	PaymentID  string    `json:"payment_id"`
+	Method     string    `json:"method"`
+	AmountSats int64     `json:"amount_sats"`
+	FeeSats    int64     `json:"fee_sats"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// FeePercent returns the fee as a percentage of the amount sent
+func (n *NotableFee) FeePercent() float64 {
+	if n.AmountSats == 0 {
+		return 0
+	}
+	return float64(n.FeeSats) / float64(n.AmountSats) * 100
+}
+
+// FeeReport summarizes the fees of the completed sends in a period, by
+// payment method and in total
+type FeeReport struct {
+	Since   time.Time         `json:"since"`
+	Until   time.Time         `json:"until"`
+	Groups  []*FeeReportGroup `json:"groups"`
+	Total   FeeReportGroup    `json:"total"`
+	Notable []*NotableFee     `json:"notable"`
+}
+
+// ComputeFeeReport summarizes the fees of the completed bitcoin sends between
+// since and until, grouped by lightning, bitcoin (on-chain) and spark.
+// Payments with a fee above NotableFeePercent of their amount are listed as
+// notable, oldest first.
+func (w *Wallet) ComputeFeeReport(ctx context.Context, since, until time.Time) (*FeeReport, error) {
+	defer logCall("ComputeFeeReport", time.Now())
+	if !until.After(since) {
+		return nil, fmt.Errorf("invalid period: %s is not before %s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	sortAscending := true
+	from, to := uint64(since.Unix()), uint64(until.Unix())
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeSend}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	var assetFilter breez_sdk_spark.AssetFilter = breez_sdk_spark.AssetFilterBitcoin{}
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+		TypeFilter:    &typeFilter,
+		StatusFilter:  &statusFilter,
+		AssetFilter:   &assetFilter,
+		FromTimestamp: &from,
+		ToTimestamp:   &to,
+		SortAscending: &sortAscending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment history: %w", err)
+	}
+
+	report := &FeeReport{Since: since, Until: until, Total: FeeReportGroup{Method: "total"}}
+	groups := make(map[string]*FeeReportGroup, len(FeeReportMethods))
+	for _, method := range FeeReportMethods {
+		groups[method] = &FeeReportGroup{Method: method}
+		report.Groups = append(report.Groups, groups[method])
+	}
+
+	for _, payment := range payments {
+		// The SDK filter is inclusive, but a payment at until is after the period
+		if payment.Timestamp >= to {
+			continue
+		}
+		method := feeReportMethod(payment.Method)
+		group, ok := groups[method]
+		if !ok {
+			continue
+		}
+
+		amount, fee := payment.Amount.Int64(), payment.Fees.Int64()
+		for _, g := range []*FeeReportGroup{group, &report.Total} {
+			g.Payments++
+			g.SentSats += amount
+			g.FeesSats += fee
+		}
+
+		notable := &NotableFee{
+			PaymentID:  payment.Id,
+			Method:     method,
+			AmountSats: amount,
+			FeeSats:    fee,
+			Timestamp:  time.Unix(int64(payment.Timestamp), 0),
+		}
+		if notable.FeePercent() > NotableFeePercent {
+			report.Notable = append(report.Notable, notable)
+		}
+	}
+
+	return report, nil
+}
+
+// feeReportMethod names the fee report group of an SDK payment method
+func feeReportMethod(method breez_sdk_spark.PaymentMethod) string {
+	switch method {
+	case breez_sdk_spark.PaymentMethodLightning:
+		return "lightning"
+	case breez_sdk_spark.PaymentMethodWithdraw:
+		return "bitcoin"
+	case breez_sdk_spark.PaymentMethodSpark:
+		return "spark"
+	default:
+		return ""
+	}
+}
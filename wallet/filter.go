@@ -0,0 +1,47 @@
+package wallet
+
+import "strings"
+
+// ListTransactionsOptions selects transactions; every set field must match.
+// Amounts are compared by absolute value, so they apply to sends as well.
+type ListTransactionsOptions struct {
+	// Type is "send", "receive" or "probe"
+	Type string
+	// Status is Pending, Complete or Failed, matched case-insensitively
+	Status          string
+	AmountAboveSats *int64
+	AmountBelowSats *int64
+}
+
+// FilterTransactions returns the transactions matching opts. The SDK can't
+// filter by amount, so all filters run in memory.
+func FilterTransactions(txs []*Transaction, opts ListTransactionsOptions) []*Transaction {
+	filtered := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if opts.matches(tx) {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
+func (opts ListTransactionsOptions) matches(tx *Transaction) bool {
+	if opts.Type != "" && !strings.EqualFold(tx.Type, opts.Type) {
+		return false
+	}
+	if opts.Status != "" && !strings.EqualFold(tx.Status, opts.Status) {
+		return false
+	}
+
+	amount := tx.AmountSats
+	if amount < 0 {
+		amount = -amount
+	}
+	if opts.AmountAboveSats != nil && amount <= *opts.AmountAboveSats {
+		return false
+	}
+	if opts.AmountBelowSats != nil && amount >= *opts.AmountBelowSats {
+		return false
+	}
+	return true
+}
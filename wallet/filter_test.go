@@ -0,0 +1,49 @@
+package wallet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterTransactions(t *testing.T) {
+	txs := []*Transaction{
+		{ID: "send-small", AmountSats: -500, Type: "send", Status: "Complete"},
+		{ID: "send-large", AmountSats: -50_000, Type: "send", Status: "Complete"},
+		{ID: "send-failed", AmountSats: -2_000, Type: "send", Status: "Failed"},
+		{ID: "receive-small", AmountSats: 1_000, Type: "receive", Status: "Complete"},
+		{ID: "receive-pending", AmountSats: 10_000, Type: "receive", Status: "Pending"},
+		{ID: "probe", AmountSats: 1, Type: "probe", Status: "Complete"},
+	}
+	sats := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name string
+		opts ListTransactionsOptions
+		want []string
+	}{
+		{"no filter", ListTransactionsOptions{},
+			[]string{"send-small", "send-large", "send-failed", "receive-small", "receive-pending", "probe"}},
+		{"type", ListTransactionsOptions{Type: "receive"}, []string{"receive-small", "receive-pending"}},
+		{"type is case-insensitive", ListTransactionsOptions{Type: "PROBE"}, []string{"probe"}},
+		{"status is case-insensitive", ListTransactionsOptions{Status: "failed"}, []string{"send-failed"}},
+		{"sends are compared by absolute amount", ListTransactionsOptions{AmountAboveSats: sats(1_000)},
+			[]string{"send-large", "send-failed", "receive-pending"}},
+		{"amount bounds are exclusive", ListTransactionsOptions{AmountAboveSats: sats(500), AmountBelowSats: sats(10_000)},
+			[]string{"send-failed", "receive-small"}},
+		{"every filter must match", ListTransactionsOptions{Type: "send", Status: "Complete", AmountBelowSats: sats(1_000)},
+			[]string{"send-small"}},
+		{"no match", ListTransactionsOptions{Type: "send", Status: "Pending"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, tx := range FilterTransactions(txs, tt.opts) {
+				got = append(got, tx.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterTransactions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"sort"
+	"time"
+)
+
+// DayGroup summarizes the transactions of one calendar day
+type DayGroup struct {
+	Date          time.Time      `json:"date"`
+	ReceivedCount int            `json:"received_count"`
+	ReceivedSats  int64          `json:"received_sats"`
+	SentCount     int            `json:"sent_count"`
+	SentSats      int64          `json:"sent_sats"`
+	FeesSats      int64          `json:"fees_sats"`
+	NetSats       int64          `json:"net_sats"`
+	Transactions  []*Transaction `json:"transactions"`
+}
+
+// GroupByDay groups transactions by the local calendar day they happened on,
+// newest day first. Every transaction is listed in its day, but failed ones
+// moved no funds and probes are noise, so both are left out of the counts and
+// totals.
+func GroupByDay(txs []*Transaction) []DayGroup {
+	var groups []DayGroup
+	index := make(map[time.Time]int)
+
+	for _, tx := range txs {
+		year, month, day := tx.Timestamp.Local().Date()
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+
+		i, ok := index[date]
+		if !ok {
+			i = len(groups)
+			index[date] = i
+			groups = append(groups, DayGroup{Date: date})
+		}
+		group := &groups[i]
+		group.Transactions = append(group.Transactions, tx)
+
+		if tx.Status == "Failed" || tx.Type == probeType {
+			continue
+		}
+		amount := tx.AmountSats
+		if amount < 0 {
+			amount = -amount
+		}
+		if tx.Type == "send" {
+			group.SentCount++
+			group.SentSats += amount
+		} else {
+			group.ReceivedCount++
+			group.ReceivedSats += amount
+		}
+		group.FeesSats += tx.FeeSats
+		group.NetSats = group.ReceivedSats - group.SentSats - group.FeesSats
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Date.After(groups[j].Date) })
+	return groups
+}
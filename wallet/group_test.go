@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupByDay(t *testing.T) {
+	at := func(day, hour, min, sec int) time.Time {
+		return time.Date(2026, time.March, day, hour, min, sec, 0, time.Local)
+	}
+	txs := []*Transaction{
+		{ID: "first-receive", AmountSats: 10_000, Type: "receive", Status: "Complete", Timestamp: at(1, 0, 0, 0)},
+		{ID: "first-send", AmountSats: -3_000, FeeSats: 20, Type: "send", Status: "Complete", Timestamp: at(1, 12, 0, 0)},
+		{ID: "first-failed", AmountSats: -5_000, FeeSats: 10, Type: "send", Status: "Failed", Timestamp: at(1, 18, 0, 0)},
+		{ID: "first-probe", AmountSats: 1, Type: probeType, Status: "Complete", Timestamp: at(1, 23, 59, 59)},
+		{ID: "second-receive", AmountSats: 2_000, Type: "receive", Status: "Pending", Timestamp: at(2, 0, 0, 0)},
+		{ID: "third-failed", AmountSats: -1_000, Type: "send", Status: "Failed", Timestamp: at(3, 9, 0, 0)},
+	}
+
+	groups := GroupByDay(txs)
+
+	type summary struct {
+		Date          time.Time
+		ReceivedCount int
+		ReceivedSats  int64
+		SentCount     int
+		SentSats      int64
+		FeesSats      int64
+		NetSats       int64
+		IDs           []string
+	}
+	want := []summary{
+		{Date: at(3, 0, 0, 0), IDs: []string{"third-failed"}},
+		{Date: at(2, 0, 0, 0), ReceivedCount: 1, ReceivedSats: 2_000, NetSats: 2_000, IDs: []string{"second-receive"}},
+		{Date: at(1, 0, 0, 0), ReceivedCount: 1, ReceivedSats: 10_000, SentCount: 1, SentSats: 3_000, FeesSats: 20, NetSats: 6_980,
+			IDs: []string{"first-receive", "first-send", "first-failed", "first-probe"}},
+	}
+
+	var got []summary
+	for _, g := range groups {
+		s := summary{g.Date, g.ReceivedCount, g.ReceivedSats, g.SentCount, g.SentSats, g.FeesSats, g.NetSats, nil}
+		for _, tx := range g.Transactions {
+			s.IDs = append(s.IDs, tx.ID)
+		}
+		got = append(got, s)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByDay() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// InvoicePaymentInterval is how often WaitForInvoicePayment checks for the payment
+const InvoicePaymentInterval = 5 * time.Second
+
+// webhookTimeout bounds a single webhook delivery
+const webhookTimeout = 10 * time.Second
+
+// WaitForInvoicePayment polls the received payments until one pays bolt11 or
+// the context is done. Received payments are matched by invoice, like
+// CheckInvoices does, since the SDK looks payments up by payment ID rather
+// than by payment hash. Probes are skipped while probing is auto accepted.
+func (w *Wallet) WaitForInvoicePayment(ctx context.Context, bolt11 string, interval time.Duration) (*PaymentResponse, error) {
+	defer logCall("WaitForInvoicePayment", time.Now())
+
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeReceive}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	fromTimestamp := uint64(time.Now().Add(-time.Minute).Unix())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+			TypeFilter:    &typeFilter,
+			StatusFilter:  &statusFilter,
+			FromTimestamp: &fromTimestamp,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up invoice payment: %w", err)
+		}
+
+		for _, payment := range payments {
+			if payment.Details == nil || (w.autoAcceptProbing && w.isProbe(payment)) {
+				continue
+			}
+			if details, ok := (*payment.Details).(breez_sdk_spark.PaymentDetailsLightning); ok && details.Invoice == bolt11 {
+				return &PaymentResponse{
+					PaymentHash: details.HtlcDetails.PaymentHash,
+					AmountSats:  payment.Amount.Int64(),
+					FeeSats:     payment.Fees.Int64(),
+					Status:      paymentStatusString(payment.Status),
+					CompletedAt: time.Unix(int64(payment.Timestamp), 0),
+				}, nil
+			}
+		}
+	}
+}
+
+// NotifyWebhook POSTs payload as JSON to url, failing on a non-2xx status
+func NotifyWebhook(ctx context.Context, url string, payload any) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+const (
+	// invoicesFile stores the invoices created with CreateInvoice and
+	// ReceiveLightningInvoice
+	invoicesFile = "invoices.json"
+	// defaultInvoiceExpiry is used when CreateInvoice is called without an expiry
+	defaultInvoiceExpiry = 24 * time.Hour
+	// InvoiceExpiryInterval is how often WatchInvoiceExpiry checks the invoices
+	InvoiceExpiryInterval = 24 * time.Hour
+)
+
+// Invoice statuses
+const (
+	InvoiceStatusUnpaid  = "Unpaid"
+	InvoiceStatusPaid    = "Paid"
+	InvoiceStatusExpired = "Expired"
+)
+
+// ErrInvoiceNotFound is returned by GetInvoice for unknown invoice IDs
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// invoicesMu serializes updates of the invoices file
+var invoicesMu sync.Mutex
+
+// Invoice is a Lightning invoice created with CreateInvoice. Its ID is the
+// payment hash.
+type Invoice struct {
+	ID                 string     `json:"id"`
+	Bolt11             string     `json:"bolt11"`
+	AmountSats         uint64     `json:"amount_sats"`
+	Description        string     `json:"description"`
+	CreatedAt          time.Time  `json:"created_at"`
+	ExpiresAt          time.Time  `json:"expires_at"`
+	Status             string     `json:"status"`
+	PaidAt             *time.Time `json:"paid_at,omitempty"`
+	AmountReceivedSats int64      `json:"amount_received_sats,omitempty"`
+}
+
+// CreateInvoice creates a Lightning invoice and records it so its status can
+// be looked up by ID with GetInvoice
+func (w *Wallet) CreateInvoice(ctx context.Context, amountSats uint64, description string, expiry time.Duration) (*Invoice, error) {
+	defer logCall("CreateInvoice", time.Now())
+	if expiry <= 0 {
+		expiry = defaultInvoiceExpiry
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	expirySecs := uint32(expiry / time.Second)
+	request := breez_sdk_spark.ReceivePaymentRequest{
+		PaymentMethod: breez_sdk_spark.ReceivePaymentMethodBolt11Invoice{
+			Description: description,
+			AmountSats:  &amountSats,
+			ExpirySecs:  &expirySecs,
+		},
+	}
+	response, err := w.sdk.ReceivePayment(request)
+	traceSDK("ReceivePayment", request, response, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to create lightning invoice: %w", err)
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	input, err := w.sdk.Parse(response.PaymentRequest)
+	traceSDK("Parse", response.PaymentRequest, input, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to parse created invoice: %w", err)
+	}
+	details, ok := input.(breez_sdk_spark.InputTypeBolt11Invoice)
+	if !ok {
+		return nil, fmt.Errorf("created payment request is not a BOLT11 invoice")
+	}
+
+	now := time.Now()
+	invoice := &Invoice{
+		ID:          details.Field0.PaymentHash,
+		Bolt11:      response.PaymentRequest,
+		AmountSats:  amountSats,
+		Description: description,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(expiry),
+		Status:      InvoiceStatusUnpaid,
+	}
+	if err := w.recordInvoice(invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// recordInvoice adds an invoice created by the wallet to the invoices file
+func (w *Wallet) recordInvoice(invoice *Invoice) error {
+	invoicesMu.Lock()
+	defer invoicesMu.Unlock()
+
+	path := filepath.Join(w.dataDir, invoicesFile)
+	invoices, err := loadInvoices(path)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, append(invoices, invoice))
+}
+
+// GetInvoice returns an invoice created with CreateInvoice with its current
+// status, looking up a matching received payment while it is unpaid
+func (w *Wallet) GetInvoice(ctx context.Context, id string) (*Invoice, error) {
+	defer logCall("GetInvoice", time.Now())
+	path := filepath.Join(w.dataDir, invoicesFile)
+
+	invoicesMu.Lock()
+	invoices, err := loadInvoices(path)
+	invoicesMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice *Invoice
+	for _, candidate := range invoices {
+		if candidate.ID == id {
+			invoice = candidate
+			break
+		}
+	}
+	if invoice == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, id)
+	}
+	if invoice.Status != InvoiceStatusUnpaid {
+		return invoice, nil
+	}
+
+	updated, err := w.updateInvoiceStatuses(ctx, []*Invoice{invoice})
+	if err != nil {
+		return nil, err
+	}
+	if updated > 0 {
+		if err := w.saveInvoiceStatuses([]*Invoice{invoice}); err != nil {
+			return nil, err
+		}
+	}
+	return invoice, nil
+}
+
+// ExpireInvoices marks the unpaid invoices past their expiry as expired, or as
+// paid if a matching payment arrived meanwhile. It returns the number expired.
+func (w *Wallet) ExpireInvoices(ctx context.Context) (int, error) {
+	defer logCall("ExpireInvoices", time.Now())
+	invoicesMu.Lock()
+	invoices, err := loadInvoices(filepath.Join(w.dataDir, invoicesFile))
+	invoicesMu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var candidates []*Invoice
+	for _, invoice := range invoices {
+		if invoice.Status == InvoiceStatusUnpaid && now.After(invoice.ExpiresAt) {
+			candidates = append(candidates, invoice)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	if _, err := w.updateInvoiceStatuses(ctx, candidates); err != nil {
+		return 0, err
+	}
+	if err := w.saveInvoiceStatuses(candidates); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, invoice := range candidates {
+		if invoice.Status == InvoiceStatusExpired {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// CheckInvoices looks up payments for all unpaid invoices, marking them paid
+// or, past their expiry, expired. It returns the number whose status changed.
+func (w *Wallet) CheckInvoices(ctx context.Context) (int, error) {
+	defer logCall("CheckInvoices", time.Now())
+	invoicesMu.Lock()
+	invoices, err := loadInvoices(filepath.Join(w.dataDir, invoicesFile))
+	invoicesMu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	var unpaid []*Invoice
+	for _, invoice := range invoices {
+		if invoice.Status == InvoiceStatusUnpaid {
+			unpaid = append(unpaid, invoice)
+		}
+	}
+	if len(unpaid) == 0 {
+		return 0, nil
+	}
+
+	updated, err := w.updateInvoiceStatuses(ctx, unpaid)
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		if err := w.saveInvoiceStatuses(unpaid); err != nil {
+			return 0, err
+		}
+	}
+	return updated, nil
+}
+
+// WatchInvoiceExpiry runs ExpireInvoices now and then every interval until ctx
+// is done, logging the number of invoices expired
+func (w *Wallet) WatchInvoiceExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := w.ExpireInvoices(ctx)
+		if err != nil {
+			slog.Warn("Failed to expire invoices", "error", err)
+		} else if expired > 0 {
+			slog.Info("Marked invoices as expired", "count", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateInvoiceStatuses sets the status of unpaid invoices to paid if a
+// matching payment was received, or to expired once they are past their
+// expiry. It returns the number of invoices whose status changed.
+func (w *Wallet) updateInvoiceStatuses(ctx context.Context, invoices []*Invoice) (int, error) {
+	from := invoices[0].CreatedAt
+	for _, invoice := range invoices {
+		if invoice.CreatedAt.Before(from) {
+			from = invoice.CreatedAt
+		}
+	}
+
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeReceive}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	fromTimestamp := uint64(from.Add(-time.Minute).Unix())
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+		TypeFilter:    &typeFilter,
+		StatusFilter:  &statusFilter,
+		FromTimestamp: &fromTimestamp,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up invoice payments: %w", err)
+	}
+
+	paid := make(map[string]breez_sdk_spark.Payment)
+	for _, payment := range payments {
+		if payment.Details == nil {
+			continue
+		}
+		if details, ok := (*payment.Details).(breez_sdk_spark.PaymentDetailsLightning); ok {
+			paid[details.Invoice] = payment
+		}
+	}
+
+	now := time.Now()
+	updated := 0
+	for _, invoice := range invoices {
+		if payment, ok := paid[invoice.Bolt11]; ok {
+			paidAt := time.Unix(int64(payment.Timestamp), 0)
+			invoice.Status = InvoiceStatusPaid
+			invoice.PaidAt = &paidAt
+			invoice.AmountReceivedSats = payment.Amount.Int64()
+			updated++
+		} else if now.After(invoice.ExpiresAt) {
+			invoice.Status = InvoiceStatusExpired
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// saveInvoiceStatuses writes the status of the given invoices to the invoices
+// file, keeping the invoices created in the meantime
+func (w *Wallet) saveInvoiceStatuses(updated []*Invoice) error {
+	byID := make(map[string]*Invoice, len(updated))
+	for _, invoice := range updated {
+		byID[invoice.ID] = invoice
+	}
+
+	invoicesMu.Lock()
+	defer invoicesMu.Unlock()
+
+	path := filepath.Join(w.dataDir, invoicesFile)
+	invoices, err := loadInvoices(path)
+	if err != nil {
+		return err
+	}
+	for i, invoice := range invoices {
+		if update, ok := byID[invoice.ID]; ok {
+			invoices[i] = update
+		}
+	}
+	return saveJSON(path, invoices)
+}
+
+// ListInvoices returns the invoices created with CreateInvoice, oldest first,
+// with the status they were last seen with. Unpaid invoices past their expiry
+// are returned as expired, even if WatchInvoiceExpiry hasn't marked them yet.
+func ListInvoices(dataDir string) ([]*Invoice, error) {
+	invoicesMu.Lock()
+	defer invoicesMu.Unlock()
+	invoices, err := loadInvoices(filepath.Join(dataDir, invoicesFile))
+	if err != nil {
+		return nil, err
+	}
+	markExpired(invoices, time.Now())
+	return invoices, nil
+}
+
+// IsOwnInvoice reports whether a BOLT11 invoice was created by this wallet,
+// returning its record if it was. The payment hash is decoded locally and
+// looked up in the invoices recorded by CreateInvoice and
+// ReceiveLightningInvoice.
+func IsOwnInvoice(dataDir, bolt11 string) (bool, *Invoice, error) {
+	bolt11 = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(bolt11)), "lightning:")
+	hash, ok := bolt11PaymentHash(bolt11)
+	if !ok {
+		return false, nil, fmt.Errorf("invalid bolt11 invoice")
+	}
+
+	invoices, err := ListInvoices(dataDir)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, invoice := range invoices {
+		if strings.EqualFold(invoice.ID, hash) || strings.EqualFold(invoice.Bolt11, bolt11) {
+			return true, invoice, nil
+		}
+	}
+	return false, nil, nil
+}
+
+// CleanupInvoices deletes the paid and expired invoices created before the
+// given time and returns the number deleted. Unpaid invoices past their expiry
+// count as expired.
+func CleanupInvoices(dataDir string, before time.Time) (int, error) {
+	invoicesMu.Lock()
+	defer invoicesMu.Unlock()
+
+	path := filepath.Join(dataDir, invoicesFile)
+	invoices, err := loadInvoices(path)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	kept := invoices[:0]
+	for _, invoice := range invoices {
+		settled := invoice.Status != InvoiceStatusUnpaid || now.After(invoice.ExpiresAt)
+		if settled && invoice.CreatedAt.Before(before) {
+			continue
+		}
+		kept = append(kept, invoice)
+	}
+
+	deleted := len(invoices) - len(kept)
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, saveJSON(path, kept)
+}
+
+// markExpired sets the status of the unpaid invoices past their expiry to
+// expired. The change must not be saved, so CheckInvoices can still find a
+// payment that arrived just before the expiry.
+func markExpired(invoices []*Invoice, now time.Time) {
+	for _, invoice := range invoices {
+		if invoice.Status == InvoiceStatusUnpaid && now.After(invoice.ExpiresAt) {
+			invoice.Status = InvoiceStatusExpired
+		}
+	}
+}
+
+// loadInvoices reads the invoices file; the caller must hold invoicesMu
+func loadInvoices(path string) ([]*Invoice, error) {
+	var invoices []*Invoice
+	if err := loadJSON(path, &invoices); err != nil {
+		return nil, err
+	}
+	for _, invoice := range invoices {
+		if invoice.Status == "" {
+			invoice.Status = InvoiceStatusUnpaid
+		}
+	}
+	return invoices, nil
+}
@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/breez/tiny-spark/lightningaddress"
+)
+
+// LightningAddressInfo describes who a Lightning address pays
+type LightningAddressInfo struct {
+	Address         string `json:"address"`
+	URL             string `json:"url"`
+	Description     string `json:"description"`
+	LongDescription string `json:"long_description,omitempty"`
+	Identifier      string `json:"identifier,omitempty"`
+	Email           string `json:"email,omitempty"`
+	ImageType       string `json:"image_type,omitempty"`
+	MinSendableSats uint64 `json:"min_sendable_sats"`
+	MaxSendableSats uint64 `json:"max_sendable_sats"`
+	CommentAllowed  uint16 `json:"comment_allowed"`
+	Metadata        string `json:"metadata"`
+}
+
+// ResolveLightningAddress fetches the pay request of a user@domain Lightning
+// address without paying it
+func ResolveLightningAddress(ctx context.Context, address string) (*LightningAddressInfo, error) {
+	payRequest, err := lightningaddress.Resolve(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := lightningaddress.URL(address)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &LightningAddressInfo{
+		Address: address,
+		URL:     endpoint,
+		// Round the minimum up so it is always accepted
+		MinSendableSats: (payRequest.MinSendable + 999) / 1000,
+		MaxSendableSats: payRequest.MaxSendable / 1000,
+		CommentAllowed:  payRequest.CommentAllowed,
+		Metadata:        payRequest.Metadata,
+	}
+
+	if metadata, err := lightningaddress.ParseMetadata(payRequest.Metadata); err == nil {
+		info.Description = metadata.Description
+		info.LongDescription = metadata.LongDescription
+		info.Identifier = metadata.Identifier
+		info.Email = metadata.Email
+		info.ImageType = metadata.ImageType
+	}
+
+	return info, nil
+}
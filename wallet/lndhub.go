@@ -0,0 +1,301 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+const (
+	// lndhubImportFile is the file in the account data directory holding imported LNDHub history
+	lndhubImportFile = "lndhub_import.json"
+	// satsPerBTC is the number of satoshis in one bitcoin
+	satsPerBTC = 100_000_000
+)
+
+// LNDHubExport is wallet history in the LNDHub export format
+type LNDHubExport struct {
+	Invoices     []InvoiceExport     `json:"invoices"`
+	Payments     []PaymentExport     `json:"payments"`
+	Transactions []TransactionExport `json:"transactions"`
+}
+
+// InvoiceExport is a received Lightning invoice in the LNDHub user invoice schema
+type InvoiceExport struct {
+	Type           string `json:"type"`
+	PaymentRequest string `json:"payment_request"`
+	PaymentHash    string `json:"payment_hash"`
+	Description    string `json:"description"`
+	Amount         int64  `json:"amt"`
+	Timestamp      int64  `json:"timestamp"`
+	ExpireTime     int64  `json:"expire_time"`
+	IsPaid         bool   `json:"ispaid"`
+	// Note and Labels are the local annotation of the payment, not LNDHub fields
+	Note   string   `json:"note"`
+	Labels []string `json:"labels"`
+}
+
+// PaymentExport is a sent Lightning payment in the LNDHub paid invoice schema
+type PaymentExport struct {
+	Type            string   `json:"type"`
+	PaymentRequest  string   `json:"payment_request"`
+	PaymentHash     string   `json:"payment_hash"`
+	PaymentPreimage string   `json:"payment_preimage"`
+	Memo            string   `json:"memo"`
+	Value           int64    `json:"value"`
+	Fee             int64    `json:"fee"`
+	Timestamp       int64    `json:"timestamp"`
+	Note            string   `json:"note"`
+	Labels          []string `json:"labels"`
+}
+
+// TransactionExport is an on-chain deposit or withdrawal in the LNDHub
+// transaction schema, with the amount in BTC
+type TransactionExport struct {
+	Category string   `json:"category"`
+	TxID     string   `json:"txid"`
+	Amount   float64  `json:"amount"`
+	Fee      int64    `json:"fee"`
+	Time     int64    `json:"time"`
+	Note     string   `json:"note"`
+	Labels   []string `json:"labels"`
+}
+
+// ExportLNDHub exports the completed Lightning and on-chain history in the
+// LNDHub format. Spark and token transfers have no LNDHub equivalent and are
+// counted in skipped instead.
+func (w *Wallet) ExportLNDHub(ctx context.Context) (export *LNDHubExport, skipped int, err error) {
+	defer logCall("ExportLNDHub", time.Now())
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get payment history: %w", err)
+	}
+
+	annotations, err := loadAnnotations(filepath.Join(w.dataDir, annotationsFile))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	export = &LNDHubExport{
+		Invoices:     []InvoiceExport{},
+		Payments:     []PaymentExport{},
+		Transactions: []TransactionExport{},
+	}
+	for _, payment := range payments {
+		if payment.Details == nil {
+			skipped++
+			continue
+		}
+
+		completed := payment.Status == breez_sdk_spark.PaymentStatusCompleted
+		amount := payment.Amount.Int64()
+		fee := payment.Fees.Int64()
+		timestamp := int64(payment.Timestamp)
+		note, labels := annotationFields(annotations, payment.Id)
+
+		switch details := (*payment.Details).(type) {
+		case breez_sdk_spark.PaymentDetailsLightning:
+			var description string
+			if details.Description != nil {
+				description = *details.Description
+			}
+			// A local memo says more than the invoice's generic description
+			if note != "" {
+				description = note
+			}
+
+			if payment.PaymentType == breez_sdk_spark.PaymentTypeReceive {
+				var expireTime int64
+				if details.HtlcDetails.ExpiryTime > payment.Timestamp {
+					expireTime = int64(details.HtlcDetails.ExpiryTime - payment.Timestamp)
+				}
+				export.Invoices = append(export.Invoices, InvoiceExport{
+					Type:           "user_invoice",
+					PaymentRequest: details.Invoice,
+					PaymentHash:    details.HtlcDetails.PaymentHash,
+					Description:    description,
+					Amount:         amount,
+					Timestamp:      timestamp,
+					ExpireTime:     expireTime,
+					IsPaid:         completed,
+					Note:           note,
+					Labels:         labels,
+				})
+				continue
+			}
+
+			// LNDHub only lists payments that went through
+			if !completed {
+				continue
+			}
+			var preimage string
+			if details.HtlcDetails.Preimage != nil {
+				preimage = *details.HtlcDetails.Preimage
+			}
+			export.Payments = append(export.Payments, PaymentExport{
+				Type:            "paid_invoice",
+				PaymentRequest:  details.Invoice,
+				PaymentHash:     details.HtlcDetails.PaymentHash,
+				PaymentPreimage: preimage,
+				Memo:            description,
+				Value:           amount,
+				Fee:             fee,
+				Timestamp:       timestamp,
+				Note:            note,
+				Labels:          labels,
+			})
+		case breez_sdk_spark.PaymentDetailsDeposit:
+			if completed {
+				export.Transactions = append(export.Transactions, onchainExport("receive", details.TxId, amount, fee, timestamp, note, labels))
+			}
+		case breez_sdk_spark.PaymentDetailsWithdraw:
+			if completed {
+				export.Transactions = append(export.Transactions, onchainExport("send", details.TxId, -amount, fee, timestamp, note, labels))
+			}
+		default:
+			skipped++
+		}
+	}
+
+	return export, skipped, nil
+}
+
+// ImportLNDHub reads an LNDHub export and stores its history in the working
+// directory without replaying any payment. Records that were already imported
+// are skipped; it returns the number of new records.
+func ImportLNDHub(dataDir, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var incoming LNDHubExport
+	if err := json.Unmarshal(data, &incoming); err != nil {
+		return 0, fmt.Errorf("failed to parse LNDHub export %s: %w", path, err)
+	}
+
+	importPath := filepath.Join(dataDir, lndhubImportFile)
+	var stored LNDHubExport
+	if err := loadJSON(importPath, &stored); err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool)
+	for _, invoice := range stored.Invoices {
+		seen["invoice:"+invoice.PaymentHash] = true
+	}
+	for _, payment := range stored.Payments {
+		seen["payment:"+payment.PaymentHash] = true
+	}
+	for _, tx := range stored.Transactions {
+		seen["tx:"+tx.Category+":"+tx.TxID] = true
+	}
+
+	added := 0
+	for _, invoice := range incoming.Invoices {
+		if key := "invoice:" + invoice.PaymentHash; !seen[key] {
+			seen[key] = true
+			stored.Invoices = append(stored.Invoices, invoice)
+			added++
+		}
+	}
+	for _, payment := range incoming.Payments {
+		if key := "payment:" + payment.PaymentHash; !seen[key] {
+			seen[key] = true
+			stored.Payments = append(stored.Payments, payment)
+			added++
+		}
+	}
+	for _, tx := range incoming.Transactions {
+		if key := "tx:" + tx.Category + ":" + tx.TxID; !seen[key] {
+			seen[key] = true
+			stored.Transactions = append(stored.Transactions, tx)
+			added++
+		}
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	if err := saveJSON(importPath, &stored); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// ImportedTransactions returns the imported LNDHub history as transactions,
+// newest first
+func ImportedTransactions(dataDir string) ([]*Transaction, error) {
+	var stored LNDHubExport
+	if err := loadJSON(filepath.Join(dataDir, lndhubImportFile), &stored); err != nil {
+		return nil, err
+	}
+
+	var transactions []*Transaction
+	for _, invoice := range stored.Invoices {
+		status := "Pending"
+		if invoice.IsPaid {
+			status = "Complete"
+		}
+		transactions = append(transactions, &Transaction{
+			ID:          invoice.PaymentHash,
+			AmountSats:  invoice.Amount,
+			Status:      status,
+			Type:        "receive",
+			Description: invoice.Description,
+			Timestamp:   time.Unix(invoice.Timestamp, 0),
+			PaymentHash: invoice.PaymentHash,
+		})
+	}
+	for _, payment := range stored.Payments {
+		transactions = append(transactions, &Transaction{
+			ID:          payment.PaymentHash,
+			AmountSats:  -payment.Value,
+			FeeSats:     payment.Fee,
+			Status:      "Complete",
+			Type:        "send",
+			Description: payment.Memo,
+			Timestamp:   time.Unix(payment.Timestamp, 0),
+			PaymentHash: payment.PaymentHash,
+		})
+	}
+	for _, tx := range stored.Transactions {
+		amount := int64(math.Round(tx.Amount * satsPerBTC))
+		if tx.Category == "send" && amount > 0 {
+			amount = -amount
+		}
+		transactions = append(transactions, &Transaction{
+			ID:          tx.TxID,
+			AmountSats:  amount,
+			FeeSats:     tx.Fee,
+			Status:      "Complete",
+			Type:        tx.Category,
+			Description: "On-chain " + tx.Category,
+			Timestamp:   time.Unix(tx.Time, 0),
+		})
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Timestamp.After(transactions[j].Timestamp)
+	})
+	return transactions, nil
+}
+
+func onchainExport(category, txID string, amountSats, fee, timestamp int64, note string, labels []string) TransactionExport {
+	return TransactionExport{
+		Category: category,
+		TxID:     txID,
+		Amount:   float64(amountSats) / satsPerBTC,
+		Fee:      fee,
+		Time:     timestamp,
+		Note:     note,
+		Labels:   labels,
+	}
+}
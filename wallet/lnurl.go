@@ -0,0 +1,215 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/breez/tiny-spark/lightningaddress"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// LnurlInfo describes the service behind an LNURL
+type LnurlInfo struct {
+	Type        string `json:"type"`
+	URL         string `json:"url"`
+	Domain      string `json:"domain"`
+	Description string `json:"description,omitempty"`
+
+	// Pay requests
+	MinSendableMsat uint64 `json:"min_sendable_msat,omitempty"`
+	MaxSendableMsat uint64 `json:"max_sendable_msat,omitempty"`
+	CommentAllowed  uint16 `json:"comment_allowed,omitempty"`
+
+	// Withdraw requests
+	MinWithdrawableMsat uint64 `json:"min_withdrawable_msat,omitempty"`
+	MaxWithdrawableMsat uint64 `json:"max_withdrawable_msat,omitempty"`
+
+	// Auth requests
+	Action string `json:"action,omitempty"`
+
+	// Channel requests
+	NodeURI string `json:"node_uri,omitempty"`
+
+	Callback string `json:"callback,omitempty"`
+}
+
+// lnurlResponse is the union of the LNURL service responses
+type lnurlResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	Tag    string `json:"tag"`
+
+	Callback string `json:"callback"`
+
+	MinSendable    uint64 `json:"minSendable"`
+	MaxSendable    uint64 `json:"maxSendable"`
+	Metadata       string `json:"metadata"`
+	CommentAllowed uint16 `json:"commentAllowed"`
+
+	MinWithdrawable    uint64 `json:"minWithdrawable"`
+	MaxWithdrawable    uint64 `json:"maxWithdrawable"`
+	DefaultDescription string `json:"defaultDescription"`
+
+	URI string `json:"uri"`
+}
+
+// InspectLnurl decodes an LNURL, a LUD-17 lnurlp://, lnurlw:// or keyauth://
+// URL, or a Lightning address, and fetches the service description behind it
+func InspectLnurl(ctx context.Context, lnurl string) (*LnurlInfo, error) {
+	endpoint, err := decodeLnurl(lnurl)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &LnurlInfo{
+		URL:    endpoint.String(),
+		Domain: endpoint.Hostname(),
+	}
+
+	// Auth requests carry everything in the URL and must not be fetched
+	if endpoint.Query().Get("tag") == "login" {
+		info.Type = "auth"
+		info.Action = endpoint.Query().Get("action")
+		return info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lnurl request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lnurl: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response lnurlResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("lnurl service returned %s", resp.Status)
+		}
+		return nil, fmt.Errorf("failed to parse lnurl response: %w", err)
+	}
+	if strings.EqualFold(response.Status, "ERROR") {
+		return nil, fmt.Errorf("lnurl service returned an error: %s", response.Reason)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lnurl service returned %s", resp.Status)
+	}
+
+	info.Callback = response.Callback
+	switch response.Tag {
+	case "payRequest":
+		info.Type = "pay"
+		info.MinSendableMsat = response.MinSendable
+		info.MaxSendableMsat = response.MaxSendable
+		info.CommentAllowed = response.CommentAllowed
+		info.Description = lnurlPayDescription(response.Metadata)
+	case "withdrawRequest":
+		info.Type = "withdraw"
+		info.MinWithdrawableMsat = response.MinWithdrawable
+		info.MaxWithdrawableMsat = response.MaxWithdrawable
+		info.Description = response.DefaultDescription
+	case "channelRequest":
+		info.Type = "channel"
+		info.NodeURI = response.URI
+	default:
+		return nil, fmt.Errorf("unsupported lnurl tag: %q", response.Tag)
+	}
+
+	return info, nil
+}
+
+// EncodeLnurl encodes a URL as a bech32 LNURL. It is upper case, which makes
+// for a smaller QR code.
+func EncodeLnurl(rawURL string) (string, error) {
+	data, err := bech32.ConvertBits([]byte(rawURL), 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode lnurl: %w", err)
+	}
+	lnurl, err := bech32.Encode("lnurl", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode lnurl: %w", err)
+	}
+	return strings.ToUpper(lnurl), nil
+}
+
+// decodeLnurl returns the URL an LNURL points to
+func decodeLnurl(lnurl string) (*url.URL, error) {
+	lnurl = strings.TrimSpace(lnurl)
+	if len(lnurl) > len("lightning:") && strings.EqualFold(lnurl[:len("lightning:")], "lightning:") {
+		lnurl = lnurl[len("lightning:"):]
+	}
+
+	var raw string
+	lud17 := false
+	switch {
+	case strings.HasPrefix(strings.ToLower(lnurl), "lnurl1"):
+		hrp, data, err := bech32.DecodeNoLimit(lnurl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lnurl: %w", err)
+		}
+		if hrp != "lnurl" {
+			return nil, fmt.Errorf("invalid lnurl prefix: %q", hrp)
+		}
+		decoded, err := bech32.ConvertBits(data, 5, 8, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lnurl: %w", err)
+		}
+		raw = string(decoded)
+	case strings.Contains(lnurl, "@") && !strings.Contains(lnurl, "/"):
+		address, err := lightningaddress.URL(lnurl)
+		if err != nil {
+			return nil, err
+		}
+		raw = address
+		lud17 = strings.HasPrefix(address, "http://")
+	default:
+		raw = lnurl
+		for _, scheme := range []string{"lnurlp://", "lnurlw://", "lnurlc://", "keyauth://"} {
+			if strings.HasPrefix(strings.ToLower(lnurl), scheme) {
+				raw = "https://" + lnurl[len(scheme):]
+				lud17 = true
+				break
+			}
+		}
+	}
+
+	endpoint, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lnurl url: %w", err)
+	}
+	if endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid lnurl: %q", lnurl)
+	}
+
+	// LNURL requires https, except for onion services which use http
+	if strings.HasSuffix(endpoint.Hostname(), ".onion") {
+		if lud17 {
+			endpoint.Scheme = "http"
+		}
+	} else if endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("lnurl must use https: %s", endpoint)
+	}
+
+	return endpoint, nil
+}
+
+// lnurlPayDescription extracts the text/plain entry of LNURL-pay metadata
+func lnurlPayDescription(metadata string) string {
+	parsed, err := lightningaddress.ParseMetadata(metadata)
+	if err != nil {
+		return ""
+	}
+	return parsed.Description
+}
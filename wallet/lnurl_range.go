@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// LnurlPayRange is the amount range an LNURL-pay service accepts, in whole sats
+type LnurlPayRange struct {
+	MinSats uint64 `json:"min_sats"`
+	MaxSats uint64 `json:"max_sats"`
+}
+
+// ErrAmountBelowMinimum is returned when an LNURL-pay amount is below the
+// service's minimum
+type ErrAmountBelowMinimum struct {
+	LnurlPayRange
+	AmountSats uint64
+}
+
+func (e *ErrAmountBelowMinimum) Error() string {
+	return fmt.Sprintf("amount %d sats is below the minimum of %d sats", e.AmountSats, e.MinSats)
+}
+
+// ErrAmountAboveMaximum is returned when an LNURL-pay amount is above the
+// service's maximum
+type ErrAmountAboveMaximum struct {
+	LnurlPayRange
+	AmountSats uint64
+}
+
+func (e *ErrAmountAboveMaximum) Error() string {
+	return fmt.Sprintf("amount %d sats is above the maximum of %d sats", e.AmountSats, e.MaxSats)
+}
+
+// GetLnurlPayRange returns the amount range of an LNURL-pay request or
+// Lightning address
+func (w *Wallet) GetLnurlPayRange(ctx context.Context, lnurlAddress string) (*LnurlPayRange, error) {
+	defer logCall("GetLnurlPayRange", time.Now())
+	payRequest, err := w.parseLnurlPay(lnurlAddress)
+	if err != nil {
+		return nil, err
+	}
+	payRange := lnurlPayRange(payRequest)
+	return &payRange, nil
+}
+
+// ValidateLnurlPayAmount checks that amountSats is within the range the
+// LNURL-pay service accepts, returning ErrAmountBelowMinimum or
+// ErrAmountAboveMaximum otherwise
+func (w *Wallet) ValidateLnurlPayAmount(ctx context.Context, lnurlAddress string, amountSats uint64) error {
+	defer logCall("ValidateLnurlPayAmount", time.Now())
+	payRequest, err := w.parseLnurlPay(lnurlAddress)
+	if err != nil {
+		return err
+	}
+	return checkLnurlPayAmount(payRequest, amountSats)
+}
+
+// parseLnurlPay resolves an LNURL or Lightning address to its pay request
+func (w *Wallet) parseLnurlPay(lnurlAddress string) (breez_sdk_spark.LnurlPayRequestDetails, error) {
+	if err := w.breaker.Allow(); err != nil {
+		return breez_sdk_spark.LnurlPayRequestDetails{}, err
+	}
+
+	input, err := w.sdk.Parse(lnurlAddress)
+	traceSDK("Parse", lnurlAddress, input, err)
+	if w.failed(err) {
+		return breez_sdk_spark.LnurlPayRequestDetails{}, fmt.Errorf("failed to parse lnurl address: %w", err)
+	}
+
+	switch inputType := input.(type) {
+	case breez_sdk_spark.InputTypeLightningAddress:
+		return inputType.Field0.PayRequest, nil
+	case breez_sdk_spark.InputTypeLnurlPay:
+		return inputType.Field0, nil
+	}
+	return breez_sdk_spark.LnurlPayRequestDetails{}, fmt.Errorf("%s is not an LNURL-pay request or Lightning address", lnurlAddress)
+}
+
+// lnurlPayRange converts the millisat limits of a pay request to whole sats,
+// rounding the minimum up and the maximum down
+func lnurlPayRange(payRequest breez_sdk_spark.LnurlPayRequestDetails) LnurlPayRange {
+	return LnurlPayRange{
+		MinSats: (payRequest.MinSendable + 999) / 1000,
+		MaxSats: payRequest.MaxSendable / 1000,
+	}
+}
+
+// checkLnurlPayAmount checks amountSats against the limits of a pay request
+func checkLnurlPayAmount(payRequest breez_sdk_spark.LnurlPayRequestDetails, amountSats uint64) error {
+	payRange := lnurlPayRange(payRequest)
+	if amountSats < payRange.MinSats {
+		return &ErrAmountBelowMinimum{LnurlPayRange: payRange, AmountSats: amountSats}
+	}
+	if amountSats > payRange.MaxSats {
+		return &ErrAmountAboveMaximum{LnurlPayRange: payRange, AmountSats: amountSats}
+	}
+	return nil
+}
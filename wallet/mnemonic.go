@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ValidateMnemonic checks the word count, words and checksum of a BIP39
+// mnemonic without touching the network, so a bad mnemonic is reported before
+// spending time connecting to the SDK
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	if len(words) != 12 && len(words) != 24 {
+		return fmt.Errorf("mnemonic has %d words, expected 12 or 24", len(words))
+	}
+
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			return fmt.Errorf("unknown word %q at position %d", word, i+1)
+		}
+	}
+
+	if _, err := bip39.EntropyFromMnemonic(strings.Join(words, " ")); err != nil {
+		if errors.Is(err, bip39.ErrChecksumIncorrect) {
+			return errors.New("bad checksum: the words are valid but one of them is wrong or out of order")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// OnchainFeeQuote is the fee of an on-chain payment at each confirmation
+// speed, including the L1 broadcast fee
+type OnchainFeeQuote struct {
+	Address    string `json:"address"`
+	AmountSats int64  `json:"amount_sats"`
+	FastSats   int64  `json:"fast_sats"`
+	MediumSats int64  `json:"medium_sats"`
+	SlowSats   int64  `json:"slow_sats"`
+}
+
+// EstimateBitcoinFee prepares an on-chain payment without sending it and
+// returns its fee at each confirmation speed
+func (w *Wallet) EstimateBitcoinFee(ctx context.Context, address string, amountSats int64) (*OnchainFeeQuote, error) {
+	defer logCall("EstimateBitcoinFee", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	amount := big.NewInt(amountSats)
+	prepareReq := breez_sdk_spark.PrepareSendPaymentRequest{
+		PaymentRequest: address,
+		Amount:         &amount,
+	}
+
+	prepareResp, err := w.sdk.PrepareSendPayment(prepareReq)
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to prepare onchain payment: %w", err)
+	}
+
+	method, ok := prepareResp.PaymentMethod.(breez_sdk_spark.SendPaymentMethodBitcoinAddress)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a bitcoin address", address)
+	}
+
+	quote := method.FeeQuote
+	return &OnchainFeeQuote{
+		Address:    address,
+		AmountSats: amountSats,
+		FastSats:   speedFeeSats(quote.SpeedFast),
+		MediumSats: speedFeeSats(quote.SpeedMedium),
+		SlowSats:   speedFeeSats(quote.SpeedSlow),
+	}, nil
+}
+
+// speedFeeSats is the total fee of a speed: the service fee plus the L1
+// broadcast fee
+func speedFeeSats(quote breez_sdk_spark.SendOnchainSpeedFeeQuote) int64 {
+	return int64(quote.UserFeeSat + quote.L1BroadcastFeeSat)
+}
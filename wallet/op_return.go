@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// maxOpReturnBytes is the largest OP_RETURN payload relayed by standard nodes
+const maxOpReturnBytes = 80
+
+// ErrOpReturnTooLarge is returned for OP_RETURN data of more than 80 bytes
+var ErrOpReturnTooLarge = errors.New("OP_RETURN data is larger than 80 bytes")
+
+// ConfirmationSpeed is the fee level of an on-chain payment
+type ConfirmationSpeed = breez_sdk_spark.OnchainConfirmationSpeed
+
+const (
+	ConfirmationSpeedFast   = breez_sdk_spark.OnchainConfirmationSpeedFast
+	ConfirmationSpeedMedium = breez_sdk_spark.OnchainConfirmationSpeedMedium
+	ConfirmationSpeedSlow   = breez_sdk_spark.OnchainConfirmationSpeedSlow
+)
+
+// ParseConfirmationSpeed parses a confirmation speed name: fast, medium or slow
+func ParseConfirmationSpeed(name string) (ConfirmationSpeed, error) {
+	switch strings.ToLower(name) {
+	case "fast":
+		return ConfirmationSpeedFast, nil
+	case "medium":
+		return ConfirmationSpeedMedium, nil
+	case "slow":
+		return ConfirmationSpeedSlow, nil
+	default:
+		return 0, fmt.Errorf("invalid confirmation speed %q: must be fast, medium or slow", name)
+	}
+}
+
+// SendBitcoinAddressWithMemo sends Bitcoin to an on-chain address with an
+// OP_RETURN output carrying opReturnHex. The SDK builds on-chain withdrawals
+// itself and can't add outputs to them, so after validating the data this
+// always returns ErrNotSupported.
+func (w *Wallet) SendBitcoinAddressWithMemo(ctx context.Context, address string, amountSats int64, opReturnHex string, speed ConfirmationSpeed) (*PaymentResponse, error) {
+	defer logCall("SendBitcoinAddressWithMemo", time.Now())
+	data, err := hex.DecodeString(opReturnHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OP_RETURN data: %w", err)
+	}
+	if len(data) > maxOpReturnBytes {
+		return nil, fmt.Errorf("%w: got %d bytes", ErrOpReturnTooLarge, len(data))
+	}
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,128 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// paymentsFile is the file in the account data directory holding the payment cache
+const paymentsFile = "payments.json"
+
+// rescanPageSize is the number of payments fetched per SDK call during a rescan
+const rescanPageSize = 100
+
+// paymentsMu serializes reads and writes of the payment cache
+var paymentsMu sync.Mutex
+
+// RescanResult summarizes a rescan of the payment history
+type RescanResult struct {
+	Added           int
+	Updated         int
+	InvoicesUpdated int
+}
+
+// LoadPaymentCache returns the cached transactions keyed by payment ID
+func LoadPaymentCache(dataDir string) (map[string]*Transaction, error) {
+	paymentsMu.Lock()
+	defer paymentsMu.Unlock()
+	return loadPaymentCache(filepath.Join(dataDir, paymentsFile))
+}
+
+// loadPaymentCache reads the payment cache; the caller must hold paymentsMu
+func loadPaymentCache(path string) (map[string]*Transaction, error) {
+	var transactions []*Transaction
+	if err := loadJSON(path, &transactions); err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]*Transaction, len(transactions))
+	for _, tx := range transactions {
+		cache[tx.ID] = tx
+	}
+	return cache, nil
+}
+
+// Rescan fetches every payment created at or after from (all payments if from
+// is zero) and upserts them into the payment cache by payment ID. progress is
+// called after each page with the number of payments imported so far. The
+// invoice statuses are checked against the payments afterwards.
+func (w *Wallet) Rescan(ctx context.Context, from time.Time, progress func(imported int)) (*RescanResult, error) {
+	defer logCall("Rescan", time.Now())
+	path := filepath.Join(w.dataDir, paymentsFile)
+
+	paymentsMu.Lock()
+	defer paymentsMu.Unlock()
+
+	cache, err := loadPaymentCache(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req := breez_sdk_spark.ListPaymentsRequest{}
+	if !from.IsZero() {
+		fromTimestamp := uint64(from.Unix())
+		req.FromTimestamp = &fromTimestamp
+	}
+
+	result := &RescanResult{}
+	imported := 0
+	for offset := uint32(0); ; offset += rescanPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := w.breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		pageOffset := offset
+		limit := uint32(rescanPageSize)
+		req.Offset = &pageOffset
+		req.Limit = &limit
+		response, err := w.sdk.ListPayments(req)
+		traceSDK("ListPayments", req, response, err)
+		if w.failed(err) {
+			return nil, fmt.Errorf("failed to list payments: %w", err)
+		}
+
+		for _, payment := range response.Payments {
+			if _, ok := cache[payment.Id]; ok {
+				result.Updated++
+			} else {
+				result.Added++
+			}
+			cache[payment.Id] = transactionFromPayment(payment)
+		}
+		imported += len(response.Payments)
+		if progress != nil {
+			progress(imported)
+		}
+
+		if len(response.Payments) < rescanPageSize {
+			break
+		}
+	}
+
+	// Newest first, like the SDK's payment listing
+	transactions := make([]*Transaction, 0, len(cache))
+	for _, tx := range cache {
+		transactions = append(transactions, tx)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Timestamp.After(transactions[j].Timestamp)
+	})
+	if err := saveJSON(path, transactions); err != nil {
+		return nil, err
+	}
+
+	result.InvoicesUpdated, err = w.CheckInvoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// paymentHashLength is the length of a full hex payment hash
+const paymentHashLength = 64
+
+// ErrPaymentNotFound is returned by FindPaymentByHash when no payment matches
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// FindPaymentByHash returns the payment whose payment hash is hash or starts
+// with it. The payment cache written by Rescan is searched first, then the
+// SDK, first by payment ID for a full hash and then through the whole
+// history. A prefix matching several payments is an error.
+func (w *Wallet) FindPaymentByHash(ctx context.Context, hash string) (*Transaction, error) {
+	defer logCall("FindPaymentByHash", time.Now())
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" || len(hash) > paymentHashLength {
+		return nil, fmt.Errorf("invalid payment hash: %q", hash)
+	}
+	// An odd length prefix isn't valid hex on its own, so check it padded
+	if _, err := hex.DecodeString(hash + strings.Repeat("0", len(hash)%2)); err != nil {
+		return nil, fmt.Errorf("invalid payment hash: %q", hash)
+	}
+
+	cache, err := LoadPaymentCache(w.dataDir)
+	if err != nil {
+		return nil, err
+	}
+	cached := make([]*Transaction, 0, len(cache))
+	for _, tx := range cache {
+		cached = append(cached, tx)
+	}
+	match, err := matchPaymentHash(cached, hash)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil {
+		// The cache may be older than the last status change
+		if tx, err := w.GetPayment(ctx, match.ID); err == nil {
+			return tx, nil
+		}
+		slog.Warn("Failed to refresh cached payment, showing the cached status", "payment_id", match.ID)
+		w.annotate(match)
+		return match, nil
+	}
+
+	if len(hash) == paymentHashLength {
+		if tx, err := w.GetPayment(ctx, hash); err == nil {
+			return tx, nil
+		}
+	}
+
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search payments: %w", err)
+	}
+	transactions := make([]*Transaction, len(payments))
+	for i, payment := range payments {
+		transactions[i] = transactionFromPayment(payment)
+	}
+	match, err = matchPaymentHash(transactions, hash)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: no payment with hash %s", ErrPaymentNotFound, hash)
+	}
+	w.annotate(match)
+	return match, nil
+}
+
+// matchPaymentHash returns the transaction whose payment hash starts with
+// prefix, nil if there is none
+func matchPaymentHash(transactions []*Transaction, prefix string) (*Transaction, error) {
+	var match *Transaction
+	for _, tx := range transactions {
+		if !strings.HasPrefix(strings.ToLower(tx.PaymentHash), prefix) {
+			continue
+		}
+		if match != nil && match.ID != tx.ID {
+			return nil, fmt.Errorf("payment hash prefix %s matches more than one payment", prefix)
+		}
+		match = tx
+	}
+	return match, nil
+}
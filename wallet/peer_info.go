@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrPeerNotFound is returned when the graph API doesn't know a node
+var ErrPeerNotFound = errors.New("node not found in the Lightning network graph")
+
+// PeerInfo describes a Lightning node as seen in the public network graph
+type PeerInfo struct {
+	Pubkey       string   `json:"pubkey"`
+	Alias        string   `json:"alias"`
+	Color        string   `json:"color"`
+	Addresses    []string `json:"addresses"`
+	CapacitySats int64    `json:"capacity_sats"`
+	ChannelCount int      `json:"channel_count"`
+	// Source is the graph API the information came from
+	Source string `json:"source"`
+}
+
+// graphNode is the node JSON returned by 1ml.com and compatible graph APIs
+type graphNode struct {
+	Alias     string `json:"alias"`
+	Color     string `json:"color"`
+	Addresses []struct {
+		Addr string `json:"addr"`
+	} `json:"addresses"`
+	Capacity     int64 `json:"capacity"`
+	ChannelCount int   `json:"channelcount"`
+}
+
+// GetNodePeerInfo looks up a Lightning node for routing diagnostics. Spark
+// wallets are nodeless and the SDK has no graph API, so the node is queried
+// from the graph API configured in BREEZ_LN_GRAPH_API.
+func (w *Wallet) GetNodePeerInfo(ctx context.Context, pubkey string) (*PeerInfo, error) {
+	defer logCall("GetNodePeerInfo", time.Now())
+	pubkey = strings.ToLower(strings.TrimSpace(pubkey))
+	if err := validateNodePubkey(pubkey); err != nil {
+		return nil, err
+	}
+	if w.config.BreezLNGraphAPI == "" {
+		return nil, fmt.Errorf("no Lightning graph API configured, set BREEZ_LN_GRAPH_API")
+	}
+	return fetchPeerInfo(ctx, w.config.BreezLNGraphAPI, pubkey)
+}
+
+// fetchPeerInfo fetches a node from the graph API. A {pubkey} placeholder in
+// apiURL is replaced with the node's public key, otherwise it is appended as
+// <url>/<pubkey>.
+func fetchPeerInfo(ctx context.Context, apiURL, pubkey string) (*PeerInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	endpoint := strings.ReplaceAll(apiURL, "{pubkey}", pubkey)
+	if endpoint == apiURL {
+		endpoint = strings.TrimRight(apiURL, "/") + "/" + pubkey
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create graph request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query graph API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPeerNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("graph API returned %s", resp.Status)
+	}
+
+	var node graphNode
+	if err := json.NewDecoder(resp.Body).Decode(&node); err != nil {
+		return nil, fmt.Errorf("failed to parse graph API response: %w", err)
+	}
+
+	info := &PeerInfo{
+		Pubkey:       pubkey,
+		Alias:        node.Alias,
+		Color:        node.Color,
+		Addresses:    make([]string, 0, len(node.Addresses)),
+		CapacitySats: node.Capacity,
+		ChannelCount: node.ChannelCount,
+		Source:       req.URL.Host,
+	}
+	for _, address := range node.Addresses {
+		if address.Addr != "" {
+			info.Addresses = append(info.Addresses, address.Addr)
+		}
+	}
+	return info, nil
+}
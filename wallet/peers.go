@@ -0,0 +1,55 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Peer is a Lightning node the wallet is connected to
+type Peer struct {
+	Pubkey         string    `json:"pubkey"`
+	Alias          string    `json:"alias"`
+	ConnectedSince time.Time `json:"connected_since"`
+}
+
+// ConnectPeer connects to the Lightning node pubkey at host:port. The Spark
+// operators route the wallet's Lightning payments and the SDK opens no peer
+// connections of its own, so after validating the arguments this always
+// returns ErrNotSupported.
+func (w *Wallet) ConnectPeer(ctx context.Context, pubkey, host string, port uint16) error {
+	defer logCall("ConnectPeer", time.Now())
+	if err := validatePubkey(pubkey); err != nil {
+		return err
+	}
+	if host == "" || port == 0 {
+		return fmt.Errorf("invalid peer address: %q", fmt.Sprintf("%s:%d", host, port))
+	}
+	return ErrNotSupported
+}
+
+// DisconnectPeer disconnects from a Lightning node. Like ConnectPeer it
+// always returns ErrNotSupported.
+func (w *Wallet) DisconnectPeer(ctx context.Context, pubkey string) error {
+	defer logCall("DisconnectPeer", time.Now())
+	if err := validatePubkey(pubkey); err != nil {
+		return err
+	}
+	return ErrNotSupported
+}
+
+// ListPeers returns the connected Lightning peers. The SDK doesn't expose the
+// connections of the Spark operators, so this always returns ErrNotSupported.
+func (w *Wallet) ListPeers(ctx context.Context) ([]*Peer, error) {
+	defer logCall("ListPeers", time.Now())
+	return nil, ErrNotSupported
+}
+
+// validatePubkey checks that pubkey is a hex encoded compressed public key
+func validatePubkey(pubkey string) error {
+	if decoded, err := hex.DecodeString(pubkey); err != nil || len(decoded) != 33 {
+		return fmt.Errorf("invalid node public key: %q", pubkey)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// pingTimeout bounds how long Ping waits for the SDK to answer
+const pingTimeout = 2 * time.Second
+
+// Ping checks that the SDK is responding with a GetInfo call that does not
+// wait for a sync. It fails if the SDK does not answer within two seconds.
+func (w *Wallet) Ping(ctx context.Context) error {
+	defer logCall("Ping", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	ensureSynced := false
+	request := breez_sdk_spark.GetInfoRequest{
+		EnsureSynced: &ensureSynced,
+	}
+
+	// The SDK call can't be cancelled, so it runs in the background and a
+	// late answer is dropped
+	done := make(chan error, 1)
+	go func() {
+		response, err := w.sdk.GetInfo(request)
+		traceSDK("GetInfo", request, response, err)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if w.failed(err) {
+			return fmt.Errorf("failed to ping SDK: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		w.breaker.Record(ctx.Err())
+		return fmt.Errorf("SDK did not respond within %s: %w", pingTimeout, ctx.Err())
+	}
+}
@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPreimageMismatch is returned by VerifyPreimage when the preimage doesn't
+// hash to the invoice's payment hash
+var ErrPreimageMismatch = errors.New("preimage does not match the payment hash")
+
+// PreimagePayment is an invoice to pay together with the preimage the payer
+// already knows, so the payment hash can be checked before paying
+type PreimagePayment struct {
+	Invoice    string `json:"invoice"`
+	Preimage   string `json:"preimage"`
+	AmountSats int64  `json:"amount_sats"`
+}
+
+// Verify checks the preimage against the invoice's payment hash and the
+// amount against the invoice amount. Amountless invoices are refused, since
+// they can't be paid with SendLightningInvoice.
+func (p *PreimagePayment) Verify() error {
+	if err := VerifyPreimage(p.Invoice, p.Preimage); err != nil {
+		return err
+	}
+
+	invoiceAmount, ok := InvoiceAmountSats(p.Invoice)
+	if !ok {
+		return fmt.Errorf("amountless invoices are not supported")
+	}
+	if p.AmountSats != 0 && p.AmountSats != invoiceAmount {
+		return fmt.Errorf("amount_sats %d does not match the invoice amount of %d sats", p.AmountSats, invoiceAmount)
+	}
+	return nil
+}
+
+// VerifyPreimage checks that SHA256(preimage) is the payment hash of a BOLT11
+// invoice. The invoice is decoded locally.
+func VerifyPreimage(bolt11, preimageHex string) error {
+	hash, ok := bolt11PaymentHash(bolt11)
+	if !ok {
+		return fmt.Errorf("invalid bolt11 invoice")
+	}
+	preimage, err := hex.DecodeString(strings.TrimSpace(preimageHex))
+	if err != nil || len(preimage) != 32 {
+		return fmt.Errorf("invalid preimage %q: must be 32 hex encoded bytes", preimageHex)
+	}
+
+	digest := sha256.Sum256(preimage)
+	if hex.EncodeToString(digest[:]) != hash {
+		return fmt.Errorf("%w %s", ErrPreimageMismatch, hash)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// probeType is the type of receives below BREEZ_MIN_RECEIVE_SATS. Nodes
+// discovering routes send such tiny payments, which would otherwise show up as
+// mysterious zero value receives.
+const probeType = "probe"
+
+// SetAutoAcceptProbing sets whether probes are accepted silently: without
+// being logged, emitted as payment events or taken for the payment of an
+// invoice being waited for. It starts as BREEZ_AUTO_ACCEPT_PROBING.
+func (w *Wallet) SetAutoAcceptProbing(enabled bool) {
+	w.autoAcceptProbing = enabled
+}
+
+// markProbes sets the type of the receives below the minimum receive amount
+// to "probe"
+func (w *Wallet) markProbes(transactions ...*Transaction) {
+	for _, tx := range transactions {
+		if tx.Type == "receive" && tx.AmountSats < int64(w.config.BreezMinReceiveSats) {
+			tx.Type = probeType
+		}
+	}
+}
+
+// isProbe reports whether an SDK payment is a receive below the minimum
+// receive amount
+func (w *Wallet) isProbe(payment breez_sdk_spark.Payment) bool {
+	return payment.PaymentType == breez_sdk_spark.PaymentTypeReceive && payment.Amount.Int64() < int64(w.config.BreezMinReceiveSats)
+}
@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// SearchTransactions returns all transactions, newest first, whose description
+// or local memo matches pattern
+func (w *Wallet) SearchTransactions(ctx context.Context, pattern *regexp.Regexp) ([]*Transaction, error) {
+	defer logCall("SearchTransactions", time.Now())
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+
+	var matches []*Transaction
+	for _, payment := range payments {
+		tx := transactionFromPayment(payment)
+		w.annotate(tx)
+		if pattern.MatchString(tx.Description) || (tx.Memo != "" && pattern.MatchString(tx.Memo)) {
+			matches = append(matches, tx)
+		}
+	}
+	return matches, nil
+}
+
+// GetAllTransactions returns every transaction in the payment history, newest first
+func (w *Wallet) GetAllTransactions(ctx context.Context) ([]*Transaction, error) {
+	defer logCall("GetAllTransactions", time.Now())
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction history: %w", err)
+	}
+
+	transactions := make([]*Transaction, len(payments))
+	for i, payment := range payments {
+		transactions[i] = transactionFromPayment(payment)
+	}
+	w.annotate(transactions...)
+	return transactions, nil
+}
+
+// listAllPayments pages through all payments matching the filters in req,
+// newest first unless req sorts ascending
+func (w *Wallet) listAllPayments(ctx context.Context, req breez_sdk_spark.ListPaymentsRequest) ([]breez_sdk_spark.Payment, error) {
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	const pageSize = 100
+	var payments []breez_sdk_spark.Payment
+
+	for offset := uint32(0); ; offset += pageSize {
+		pageOffset := offset
+		limit := uint32(pageSize)
+		req.Offset = &pageOffset
+		req.Limit = &limit
+		response, err := w.sdk.ListPayments(req)
+		traceSDK("ListPayments", req, response, err)
+		if w.failed(err) {
+			return nil, err
+		}
+
+		payments = append(payments, response.Payments...)
+		if len(response.Payments) < pageSize {
+			return payments, nil
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package wallet
+
+// IsSelfPayment reports whether tx is a payment of one of the wallet's own
+// invoices, given the payment hashes of the invoices created locally. The SDK
+// doesn't report the sender of a payment, so invoices created elsewhere, such
+// as by another copy of the wallet, aren't recognized.
+func IsSelfPayment(tx *Transaction, localInvoices []string) bool {
+	if tx.Type != "send" {
+		return false
+	}
+	for _, hash := range localInvoices {
+		if tx.PaymentHash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitSelfPayments separates self-payments from the other transactions,
+// keeping the order of both. The receive with the same payment hash as a
+// self-payment is its other half and counts as a self-payment too.
+func SplitSelfPayments(txs []*Transaction, localInvoices []string) (self, others []*Transaction) {
+	selfHashes := make(map[string]bool)
+	for _, tx := range txs {
+		if IsSelfPayment(tx, localInvoices) {
+			selfHashes[tx.PaymentHash] = true
+		}
+	}
+
+	for _, tx := range txs {
+		if selfHashes[tx.PaymentHash] {
+			self = append(self, tx)
+		} else {
+			others = append(others, tx)
+		}
+	}
+	return self, others
+}
+
+// LocalInvoiceHashes returns the payment hashes of the invoices created with
+// CreateInvoice
+func (w *Wallet) LocalInvoiceHashes() ([]string, error) {
+	invoices, err := ListInvoices(w.dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(invoices))
+	for i, invoice := range invoices {
+		hashes[i] = invoice.ID
+	}
+	return hashes, nil
+}
@@ -0,0 +1,168 @@
+package wallet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+const (
+	// sparkAddressFile caches the Spark address in the account data directory
+	sparkAddressFile = "spark_address.json"
+	// legacySparkAddressFile and legacyStaticSparkAddressFile are the plain
+	// text caches of earlier versions, which didn't record the network or
+	// wallet of the address
+	legacySparkAddressFile       = "spark_address.txt"
+	legacyStaticSparkAddressFile = "static_spark_address.txt"
+	// staticSparkAddressTTL is how old a cached address GetStaticSparkAddress
+	// returns before fetching it again
+	staticSparkAddressTTL = 24 * time.Hour
+)
+
+// sparkAddressCache is the cached Spark address with the network and wallet
+// it belongs to. The seed fingerprint identifies the wallet without an SDK
+// call; the identity public key is recorded when the address is fetched.
+type sparkAddressCache struct {
+	Address         string    `json:"address"`
+	Network         string    `json:"network"`
+	SeedFingerprint string    `json:"seed_fingerprint"`
+	IdentityPubkey  string    `json:"identity_pubkey"`
+	CachedAt        time.Time `json:"cached_at"`
+}
+
+// GetSparkAddress returns the wallet's reusable Spark address. The SDK derives
+// it from the wallet identity key, so it doesn't change: the first call fetches
+// it from the SDK and caches it in the account data directory, later calls
+// return the cached address without an SDK call. The cache is only used for
+// the network and mnemonic it was fetched with, so switching either on the
+// same working directory fetches the address again.
+func (w *Wallet) GetSparkAddress(ctx context.Context) (string, error) {
+	defer logCall("GetSparkAddress", time.Now())
+	return w.sparkAddress(0)
+}
+
+// GetStaticSparkAddress returns the reusable Spark address like
+// GetSparkAddress, but fetches it from the SDK again once the cached address
+// is older than 24 hours
+func (w *Wallet) GetStaticSparkAddress(ctx context.Context) (string, error) {
+	defer logCall("GetStaticSparkAddress", time.Now())
+	return w.sparkAddress(staticSparkAddressTTL)
+}
+
+// GetCachedSparkAddress returns the cached Spark address and whether there is
+// one for this network and mnemonic. It never calls the SDK.
+func (w *Wallet) GetCachedSparkAddress() (string, bool) {
+	cache, ok := w.loadSparkAddressCache()
+	if !ok {
+		return "", false
+	}
+	return cache.Address, true
+}
+
+// sparkAddress returns the cached Spark address, fetching it from the SDK when
+// there is none or, if maxAge is set, when it was cached longer ago than that
+func (w *Wallet) sparkAddress(maxAge time.Duration) (string, error) {
+	if cache, ok := w.loadSparkAddressCache(); ok && (maxAge == 0 || time.Since(cache.CachedAt) < maxAge) {
+		return cache.Address, nil
+	}
+
+	identity, err := w.identityPubkey()
+	if err != nil {
+		return "", err
+	}
+	if err := w.breaker.Allow(); err != nil {
+		return "", err
+	}
+
+	request := breez_sdk_spark.ReceivePaymentRequest{
+		PaymentMethod: breez_sdk_spark.ReceivePaymentMethodSparkAddress{},
+	}
+	response, err := w.sdk.ReceivePayment(request)
+	traceSDK("ReceivePayment", request, response, err)
+	if w.failed(err) {
+		return "", fmt.Errorf("failed to get spark address: %w", err)
+	}
+
+	cache := sparkAddressCache{
+		Address:         response.PaymentRequest,
+		Network:         w.config.BreezNetwork,
+		SeedFingerprint: seedFingerprint(w.config.BreezMnemonic),
+		IdentityPubkey:  identity,
+		CachedAt:        time.Now(),
+	}
+	if err := saveJSON(filepath.Join(w.dataDir, sparkAddressFile), cache); err != nil {
+		slog.Warn("Failed to cache Spark address", "error", err)
+	}
+
+	return response.PaymentRequest, nil
+}
+
+// loadSparkAddressCache returns the cached Spark address if it belongs to
+// this network and mnemonic
+func (w *Wallet) loadSparkAddressCache() (sparkAddressCache, bool) {
+	var cache sparkAddressCache
+	if err := loadJSON(filepath.Join(w.dataDir, sparkAddressFile), &cache); err != nil {
+		slog.Warn("Failed to read Spark address cache", "error", err)
+		return cache, false
+	}
+
+	switch {
+	case cache.Address == "":
+		return cache, false
+	case cache.Network != w.config.BreezNetwork || cache.SeedFingerprint != seedFingerprint(w.config.BreezMnemonic):
+		slog.Info("Ignoring Spark address cached for another wallet or network", "network", cache.Network)
+		return cache, false
+	}
+	return cache, true
+}
+
+// seedFingerprint identifies a mnemonic in the Spark address cache. It is a
+// domain separated hash, so the cache reveals nothing about the mnemonic.
+func seedFingerprint(mnemonic string) string {
+	digest := sha256.Sum256([]byte("tiny-spark spark address cache\x00" + mnemonic))
+	return hex.EncodeToString(digest[:16])
+}
+
+// identityPubkey returns the wallet's identity public key, asking the SDK the
+// first time. It is only needed when the Spark address is fetched.
+func (w *Wallet) identityPubkey() (string, error) {
+	w.identityMu.Lock()
+	defer w.identityMu.Unlock()
+	if w.identity != "" {
+		return w.identity, nil
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return "", err
+	}
+	ensureSynced := false
+	request := breez_sdk_spark.GetInfoRequest{EnsureSynced: &ensureSynced}
+	info, err := w.sdk.GetInfo(request)
+	traceSDK("GetInfo", request, info, err)
+	if w.failed(err) {
+		return "", fmt.Errorf("failed to get wallet info: %w", err)
+	}
+	w.identity = info.IdentityPubkey
+	return w.identity, nil
+}
+
+// ClearSparkAddressCache deletes the cached Spark address so the next
+// GetSparkAddress fetches it from the SDK again
+func (w *Wallet) ClearSparkAddressCache() error {
+	for _, name := range []string{sparkAddressFile, legacySparkAddressFile, legacyStaticSparkAddressFile} {
+		err := os.Remove(filepath.Join(w.dataDir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete Spark address cache: %w", err)
+		}
+	}
+	return nil
+}
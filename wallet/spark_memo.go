@@ -0,0 +1,22 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SendSparkAddressWithMemo sends Bitcoin to a Spark address with a memo for
+// the recipient. Spark transfers to an address carry no metadata and the SDK
+// has no way to attach any, so this always returns ErrNotSupported without
+// sending.
+func (w *Wallet) SendSparkAddressWithMemo(ctx context.Context, sparkAddress string, amountSats int64, memo string) (*PaymentResponse, error) {
+	defer logCall("SendSparkAddressWithMemo", time.Now())
+	if memo == "" {
+		return w.SendSparkAddress(ctx, sparkAddress, amountSats)
+	}
+	if amountSats <= 0 {
+		return nil, fmt.Errorf("invalid amount: %d sats", amountSats)
+	}
+	return nil, fmt.Errorf("spark payment memos are %w", ErrNotSupported)
+}
@@ -0,0 +1,39 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SendSparkAddressSplit sends totalSats to a Spark address as parts sequential
+// payments of equal size, the first one also carrying the remainder. It stops
+// at the first failed part and returns the responses of the parts sent so far
+// along with the error.
+func (w *Wallet) SendSparkAddressSplit(ctx context.Context, sparkAddress string, totalSats int64, parts int) ([]*PaymentResponse, error) {
+	defer logCall("SendSparkAddressSplit", time.Now())
+	if parts < 1 {
+		return nil, fmt.Errorf("invalid number of parts: %d", parts)
+	}
+	if totalSats < int64(parts) {
+		return nil, fmt.Errorf("cannot split %d sats into %d parts", totalSats, parts)
+	}
+
+	partSats := totalSats / int64(parts)
+	remainder := totalSats % int64(parts)
+
+	responses := make([]*PaymentResponse, 0, parts)
+	for i := 0; i < parts; i++ {
+		amount := partSats
+		if i == 0 {
+			amount += remainder
+		}
+
+		response, err := w.SendSparkAddress(ctx, sparkAddress, amount)
+		if err != nil {
+			return responses, fmt.Errorf("part %d of %d (%d sats): %w", i+1, parts, amount, err)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
@@ -0,0 +1,36 @@
+package wallet
+
+import "path/filepath"
+
+// stateFile records facts about the working directory itself
+const stateFile = ".state.json"
+
+// workingDirState is the content of the state file
+type workingDirState struct {
+	Network string `json:"network"`
+}
+
+// StoredNetwork returns the network the working directory was last used
+// with, or "" if it hasn't been recorded yet
+func StoredNetwork(workingDir string) (string, error) {
+	var state workingDirState
+	if err := loadJSON(filepath.Join(workingDir, stateFile), &state); err != nil {
+		return "", err
+	}
+	return state.Network, nil
+}
+
+// storeNetwork records the network the working directory is used with
+func storeNetwork(workingDir, network string) error {
+	path := filepath.Join(workingDir, stateFile)
+
+	var state workingDirState
+	if err := loadJSON(path, &state); err != nil {
+		return err
+	}
+	if state.Network == network {
+		return nil
+	}
+	state.Network = network
+	return saveJSON(path, &state)
+}
@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// onchainDustLimitSats is the smallest on-chain output standard nodes relay
+const onchainDustLimitSats = 546
+
+// ErrSweepBelowDust is returned when the balance left after the on-chain fee
+// is too small to be an on-chain output
+type ErrSweepBelowDust struct {
+	BalanceSats int64
+	FeeSats     int64
+}
+
+func (e *ErrSweepBelowDust) Error() string {
+	return fmt.Sprintf("balance of %d sats leaves %d sats after the %d sat fee, below the on-chain dust limit of %d sats",
+		e.BalanceSats, e.BalanceSats-e.FeeSats, e.FeeSats, onchainDustLimitSats)
+}
+
+// SweepQuote is what sweeping the whole balance to an on-chain address costs
+type SweepQuote struct {
+	Address     string `json:"address"`
+	BalanceSats int64  `json:"balance_sats"`
+	FeeSats     int64  `json:"fee_sats"`
+	ReceiveSats int64  `json:"receive_sats"`
+}
+
+// QuoteLightningSweep prepares sending the whole balance to an on-chain
+// address at a confirmation speed without sending it
+func (w *Wallet) QuoteLightningSweep(ctx context.Context, destAddress string, speed ConfirmationSpeed) (*SweepQuote, error) {
+	defer logCall("QuoteLightningSweep", time.Now())
+	quote, _, err := w.prepareSweep(ctx, destAddress, speed)
+	return quote, err
+}
+
+// SweepLightningToOnchain sends the whole balance to an on-chain address, the
+// fee of the confirmation speed taken out of the amount. A Spark wallet has a
+// single balance, used for Lightning and on-chain payments alike, so this
+// empties the wallet.
+func (w *Wallet) SweepLightningToOnchain(ctx context.Context, destAddress string, speed ConfirmationSpeed) (*PaymentResponse, error) {
+	defer logCall("SweepLightningToOnchain", time.Now())
+	_, prepareResp, err := w.prepareSweep(ctx, destAddress, speed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	var options breez_sdk_spark.SendPaymentOptions = breez_sdk_spark.SendPaymentOptionsBitcoinAddress{
+		ConfirmationSpeed: speed,
+	}
+	sendReq := breez_sdk_spark.SendPaymentRequest{
+		PrepareResponse: prepareResp,
+		Options:         &options,
+	}
+
+	response, err := w.sdk.SendPayment(sendReq)
+	traceSDK("SendPayment", sendReq, response, err)
+	w.invalidateBalance()
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to send sweep payment: %w", err)
+	}
+
+	return &PaymentResponse{
+		PaymentHash: response.Payment.Id,
+		AmountSats:  response.Payment.Amount.Int64(),
+		FeeSats:     response.Payment.Fees.Int64(),
+		Status:      paymentStatusString(response.Payment.Status),
+		CompletedAt: time.Unix(int64(response.Payment.Timestamp), 0),
+	}, nil
+}
+
+// prepareSweep prepares an on-chain payment of the whole balance with the fees
+// included in the amount, refusing it if what is left is dust
+func (w *Wallet) prepareSweep(ctx context.Context, destAddress string, speed ConfirmationSpeed) (*SweepQuote, breez_sdk_spark.PrepareSendPaymentResponse, error) {
+	var prepareResp breez_sdk_spark.PrepareSendPaymentResponse
+	balance, err := w.GetBalance(ctx)
+	if err != nil {
+		return nil, prepareResp, err
+	}
+	if balance.MaxPayableSats <= onchainDustLimitSats {
+		return nil, prepareResp, &ErrSweepBelowDust{BalanceSats: balance.MaxPayableSats}
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, prepareResp, err
+	}
+	amount := big.NewInt(balance.MaxPayableSats)
+	feePolicy := breez_sdk_spark.FeePolicyFeesIncluded
+	prepareReq := breez_sdk_spark.PrepareSendPaymentRequest{
+		PaymentRequest: destAddress,
+		Amount:         &amount,
+		FeePolicy:      &feePolicy,
+	}
+
+	prepareResp, err = w.sdk.PrepareSendPayment(prepareReq)
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
+		return nil, prepareResp, fmt.Errorf("failed to prepare sweep payment: %w", err)
+	}
+
+	method, ok := prepareResp.PaymentMethod.(breez_sdk_spark.SendPaymentMethodBitcoinAddress)
+	if !ok {
+		return nil, prepareResp, fmt.Errorf("%s is not a bitcoin address", destAddress)
+	}
+
+	var fee int64
+	switch speed {
+	case ConfirmationSpeedFast:
+		fee = speedFeeSats(method.FeeQuote.SpeedFast)
+	case ConfirmationSpeedSlow:
+		fee = speedFeeSats(method.FeeQuote.SpeedSlow)
+	default:
+		fee = speedFeeSats(method.FeeQuote.SpeedMedium)
+	}
+
+	quote := &SweepQuote{
+		Address:     destAddress,
+		BalanceSats: balance.MaxPayableSats,
+		FeeSats:     fee,
+		ReceiveSats: balance.MaxPayableSats - fee,
+	}
+	if quote.ReceiveSats < onchainDustLimitSats {
+		return nil, prepareResp, &ErrSweepBelowDust{BalanceSats: quote.BalanceSats, FeeSats: fee}
+	}
+	return quote, prepareResp, nil
+}
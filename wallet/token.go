@@ -0,0 +1,274 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// GetTokenDecimals looks up the number of decimals a token uses
+func (w *Wallet) GetTokenDecimals(ctx context.Context, tokenID string) (int, error) {
+	defer logCall("GetTokenDecimals", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return 0, err
+	}
+
+	request := breez_sdk_spark.GetTokensMetadataRequest{
+		TokenIdentifiers: []string{tokenID},
+	}
+	response, err := w.sdk.GetTokensMetadata(request)
+	traceSDK("GetTokensMetadata", request, response, err)
+	if w.failed(err) {
+		return 0, fmt.Errorf("failed to get token metadata: %w", err)
+	}
+
+	for _, metadata := range response.TokensMetadata {
+		if metadata.Identifier == tokenID {
+			return int(metadata.Decimals), nil
+		}
+	}
+
+	return 0, fmt.Errorf("token %s not found", tokenID)
+}
+
+// SendToken sends an amount of token base units to a Spark address. Frozen
+// tokens return ErrTokenFrozen without calling the SDK.
+func (w *Wallet) SendToken(ctx context.Context, address, tokenID string, amount *big.Int) (*PaymentResponse, error) {
+	defer logCall("SendToken", time.Now())
+	if err := w.checkTokenFrozen(tokenID); err != nil {
+		return nil, err
+	}
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	prepareReq := breez_sdk_spark.PrepareSendPaymentRequest{
+		PaymentRequest:  address,
+		Amount:          &amount,
+		TokenIdentifier: &tokenID,
+	}
+
+	prepareResp, err := w.sdk.PrepareSendPayment(prepareReq)
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to prepare token payment: %w", err)
+	}
+
+	sendReq := breez_sdk_spark.SendPaymentRequest{
+		PrepareResponse: prepareResp,
+	}
+
+	response, err := w.sdk.SendPayment(sendReq)
+	traceSDK("SendPayment", sendReq, response, err)
+	w.invalidateBalance()
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to send token payment: %w", err)
+	}
+
+	return &PaymentResponse{
+		PaymentHash: response.Payment.Id,
+		AmountSats:  response.Payment.Amount.Int64(),
+		FeeSats:     response.Payment.Fees.Int64(),
+		Status:      paymentStatusString(response.Payment.Status),
+		CompletedAt: time.Unix(int64(response.Payment.Timestamp), 0),
+	}, nil
+}
+
+// ParseTokenAmount converts a human readable decimal amount such as "1.5" into
+// token base units. The conversion uses big.Int arithmetic so it is exact for
+// any number of decimals.
+func ParseTokenAmount(amountStr string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid token decimals: %d", decimals)
+	}
+
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(amountStr), ".")
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("invalid token amount: %q", amountStr)
+	}
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", amountStr, decimals)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", decimals-len(fraction))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid token amount: %q", amountStr)
+		}
+	}
+
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token amount: %q", amountStr)
+	}
+	return amount, nil
+}
+
+// FormatTokenAmount formats an amount of token base units as a decimal amount,
+// the inverse of ParseTokenAmount. Trailing fractional zeros are dropped.
+func FormatTokenAmount(amount *big.Int, decimals int) string {
+	digits := new(big.Int).Abs(amount).String()
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// TokenTransfer is a single token payment with the wallet balance after it
+type TokenTransfer struct {
+	ID             string    `json:"id"`
+	TokenID        string    `json:"token_id"`
+	Direction      string    `json:"direction"`
+	Amount         *big.Int  `json:"amount"`
+	Fee            *big.Int  `json:"fee"`
+	Counterparty   string    `json:"counterparty"`
+	Status         string    `json:"status"`
+	Timestamp      time.Time `json:"timestamp"`
+	RunningBalance *big.Int  `json:"running_balance"`
+}
+
+// GetTokenHistory retrieves all transfers of a token, oldest first, with the
+// running balance computed cumulatively over completed transfers
+func (w *Wallet) GetTokenHistory(ctx context.Context, tokenID string) ([]*TokenTransfer, error) {
+	defer logCall("GetTokenHistory", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	const pageSize = 100
+	sortAscending := true
+	var assetFilter breez_sdk_spark.AssetFilter = breez_sdk_spark.AssetFilterToken{
+		TokenIdentifier: &tokenID,
+	}
+
+	var transfers []*TokenTransfer
+	balance := new(big.Int)
+
+	for offset := uint32(0); ; offset += pageSize {
+		pageOffset := offset
+		limit := uint32(pageSize)
+		request := breez_sdk_spark.ListPaymentsRequest{
+			AssetFilter:   &assetFilter,
+			Offset:        &pageOffset,
+			Limit:         &limit,
+			SortAscending: &sortAscending,
+		}
+		response, err := w.sdk.ListPayments(request)
+		traceSDK("ListPayments", request, response, err)
+		if w.failed(err) {
+			return nil, fmt.Errorf("failed to get token history: %w", err)
+		}
+
+		for _, payment := range response.Payments {
+			transfer := &TokenTransfer{
+				ID:        payment.Id,
+				TokenID:   tokenID,
+				Amount:    new(big.Int).Set(payment.Amount),
+				Fee:       new(big.Int).Set(payment.Fees),
+				Status:    paymentStatusString(payment.Status),
+				Timestamp: time.Unix(int64(payment.Timestamp), 0),
+			}
+
+			// The SDK does not expose the counterparty address of token transfers,
+			// only the Spark invoice when one was used
+			if payment.Details != nil {
+				if details, ok := (*payment.Details).(breez_sdk_spark.PaymentDetailsToken); ok && details.InvoiceDetails != nil {
+					transfer.Counterparty = details.InvoiceDetails.Invoice
+				}
+			}
+
+			completed := payment.Status == breez_sdk_spark.PaymentStatusCompleted
+			if payment.PaymentType == breez_sdk_spark.PaymentTypeSend {
+				transfer.Direction = "out"
+				if completed {
+					balance.Sub(balance, payment.Amount)
+					balance.Sub(balance, payment.Fees)
+				}
+			} else {
+				transfer.Direction = "in"
+				if completed {
+					balance.Add(balance, payment.Amount)
+				}
+			}
+			transfer.RunningBalance = new(big.Int).Set(balance)
+
+			transfers = append(transfers, transfer)
+		}
+
+		if len(response.Payments) < pageSize {
+			return transfers, nil
+		}
+	}
+}
+
+// WaitForTokenBalanceIncrease polls the token balances until the balance of
+// tokenID grows or the context is done, returning the amount received and the
+// new balance in base units. Frozen tokens return ErrTokenFrozen.
+func (w *Wallet) WaitForTokenBalanceIncrease(ctx context.Context, tokenID string, interval time.Duration) (received, total *big.Int, err error) {
+	if err := w.checkTokenFrozen(tokenID); err != nil {
+		return nil, nil, err
+	}
+
+	previous, err := w.tokenBalance(ctx, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := w.tokenBalance(ctx, tokenID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if current.Cmp(previous) > 0 {
+			return new(big.Int).Sub(current, previous), current, nil
+		}
+		// Track decreases so an outgoing transfer isn't mistaken for a later receive
+		previous = current
+	}
+}
+
+// tokenBalance returns the wallet balance of a token, zero if it holds none
+func (w *Wallet) tokenBalance(ctx context.Context, tokenID string) (*big.Int, error) {
+	balances, err := w.GetTokenBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, balance := range balances {
+		if balance.TokenID != tokenID {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(balance.Balance, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %q for token %s", balance.Balance, tokenID)
+		}
+		return amount, nil
+	}
+	return new(big.Int), nil
+}
@@ -0,0 +1,32 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// ErrNotSupported is returned for operations the Breez SDK doesn't provide
+var ErrNotSupported = errors.New("not supported by the Breez SDK")
+
+// ApproveTokenSpender grants spenderAddress an allowance to pull up to amount
+// base units of a token from the wallet. Spark tokens have no allowance
+// mechanism, so this always returns ErrNotSupported.
+func (w *Wallet) ApproveTokenSpender(ctx context.Context, tokenID, spenderAddress string, amount *big.Int) error {
+	defer logCall("ApproveTokenSpender", time.Now())
+	return ErrNotSupported
+}
+
+// TokenAllowance returns the amount of a token spenderAddress is allowed to
+// pull from the wallet. It always returns ErrNotSupported.
+func (w *Wallet) TokenAllowance(ctx context.Context, tokenID, spenderAddress string) (*big.Int, error) {
+	defer logCall("TokenAllowance", time.Now())
+	return nil, ErrNotSupported
+}
+
+// RevokeTokenSpender sets the allowance of spenderAddress for a token to zero
+func (w *Wallet) RevokeTokenSpender(ctx context.Context, tokenID, spenderAddress string) error {
+	defer logCall("RevokeTokenSpender", time.Now())
+	return w.ApproveTokenSpender(ctx, tokenID, spenderAddress, new(big.Int))
+}
@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// frozenTokensFile is the file in the account data directory holding frozen token IDs
+const frozenTokensFile = "frozen_tokens.json"
+
+// ErrTokenFrozen is returned without calling the SDK for tokens frozen with FreezeToken
+var ErrTokenFrozen = errors.New("token is frozen")
+
+// LoadFrozenTokens returns the IDs of the frozen tokens
+func LoadFrozenTokens(dataDir string) ([]string, error) {
+	var tokenIDs []string
+	if err := loadJSON(filepath.Join(dataDir, frozenTokensFile), &tokenIDs); err != nil {
+		return nil, err
+	}
+	return tokenIDs, nil
+}
+
+// FreezeToken stops the wallet from sending or watching for transfers of a
+// token. The freeze is local to this account: the token itself is
+// unaffected and other wallets can still send it to this one. It reports false
+// if the token was already frozen.
+func FreezeToken(dataDir, tokenID string) (bool, error) {
+	tokenID = strings.TrimSpace(tokenID)
+	if tokenID == "" {
+		return false, fmt.Errorf("token id is required")
+	}
+
+	tokenIDs, err := LoadFrozenTokens(dataDir)
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(tokenIDs, tokenID) {
+		return false, nil
+	}
+
+	tokenIDs = append(tokenIDs, tokenID)
+	return true, saveJSON(filepath.Join(dataDir, frozenTokensFile), tokenIDs)
+}
+
+// UnfreezeToken lifts the freeze of a token. It reports false if the token
+// was not frozen.
+func UnfreezeToken(dataDir, tokenID string) (bool, error) {
+	tokenID = strings.TrimSpace(tokenID)
+
+	tokenIDs, err := LoadFrozenTokens(dataDir)
+	if err != nil {
+		return false, err
+	}
+
+	index := slices.Index(tokenIDs, tokenID)
+	if index < 0 {
+		return false, nil
+	}
+
+	tokenIDs = slices.Delete(tokenIDs, index, index+1)
+	return true, saveJSON(filepath.Join(dataDir, frozenTokensFile), tokenIDs)
+}
+
+// checkTokenFrozen returns ErrTokenFrozen if tokenID is frozen
+func (w *Wallet) checkTokenFrozen(tokenID string) error {
+	tokenIDs, err := LoadFrozenTokens(w.dataDir)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(tokenIDs, tokenID) {
+		return fmt.Errorf("%w: %s (unfreeze it with token unfreeze)", ErrTokenFrozen, tokenID)
+	}
+	return nil
+}
+
+// markFrozenTokens sets Frozen on the balances of frozen tokens
+func (w *Wallet) markFrozenTokens(balances []*TokenBalance) {
+	tokenIDs, err := LoadFrozenTokens(w.dataDir)
+	if err != nil {
+		slog.Warn("Failed to load frozen tokens", "error", err)
+		return
+	}
+	for _, balance := range balances {
+		balance.Frozen = slices.Contains(tokenIDs, balance.TokenID)
+	}
+}
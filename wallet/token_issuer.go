@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// ErrMintingNotAuthorized is returned when minting or burning a token the
+// wallet is not the issuer of
+var ErrMintingNotAuthorized = errors.New("wallet is not the issuer of this token")
+
+// MintToken mints amount base units of a token issued by this wallet
+func (w *Wallet) MintToken(ctx context.Context, tokenID string, amount *big.Int) (*PaymentResponse, error) {
+	defer logCall("MintToken", time.Now())
+	issuer, err := w.issuerFor(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	request := breez_sdk_spark.MintIssuerTokenRequest{Amount: amount}
+	payment, err := issuer.MintIssuerToken(request)
+	traceSDK("MintIssuerToken", request, payment, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to mint token: %w", err)
+	}
+	return issuerPaymentResponse(payment), nil
+}
+
+// BurnToken burns amount base units of a token issued by this wallet
+func (w *Wallet) BurnToken(ctx context.Context, tokenID string, amount *big.Int) (*PaymentResponse, error) {
+	defer logCall("BurnToken", time.Now())
+	issuer, err := w.issuerFor(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	request := breez_sdk_spark.BurnIssuerTokenRequest{Amount: amount}
+	payment, err := issuer.BurnIssuerToken(request)
+	traceSDK("BurnIssuerToken", request, payment, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to burn token: %w", err)
+	}
+	return issuerPaymentResponse(payment), nil
+}
+
+// issuerFor returns the SDK token issuer if this wallet issued tokenID, and
+// ErrMintingNotAuthorized otherwise. A wallet can issue a single token.
+func (w *Wallet) issuerFor(tokenID string) (*breez_sdk_spark.TokenIssuer, error) {
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	issuer := w.sdk.GetTokenIssuer()
+	metadata, err := issuer.GetIssuerTokenMetadata()
+	traceSDK("GetIssuerTokenMetadata", nil, metadata, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("%w: %s (no issued token found: %v)", ErrMintingNotAuthorized, tokenID, err)
+	}
+	if metadata.Identifier != tokenID {
+		return nil, fmt.Errorf("%w: %s", ErrMintingNotAuthorized, tokenID)
+	}
+	return issuer, nil
+}
+
+func issuerPaymentResponse(payment breez_sdk_spark.Payment) *PaymentResponse {
+	return &PaymentResponse{
+		PaymentHash: payment.Id,
+		AmountSats:  payment.Amount.Int64(),
+		FeeSats:     payment.Fees.Int64(),
+		Status:      paymentStatusString(payment.Status),
+		CompletedAt: time.Unix(int64(payment.Timestamp), 0),
+	}
+}
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// KnownToken is a token balance with the time of the token's latest transfer,
+// zero if the wallet never transferred it
+type KnownToken struct {
+	TokenBalance
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// ListKnownTokens returns the tokens the wallet holds. With all it also returns
+// the tokens in the local metadata registry that the wallet no longer holds,
+// with a zero balance.
+func (w *Wallet) ListKnownTokens(ctx context.Context, all bool) ([]*KnownToken, error) {
+	defer logCall("ListKnownTokens", time.Now())
+	balances, err := w.GetTokenBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if all {
+		registry, err := LoadTokenMetadataRegistry(w.dataDir)
+		if err != nil {
+			return nil, err
+		}
+		held := make(map[string]bool, len(balances))
+		for _, balance := range balances {
+			held[balance.TokenID] = true
+		}
+		var missing []string
+		for tokenID := range registry {
+			if !held[tokenID] {
+				missing = append(missing, tokenID)
+			}
+		}
+		sort.Strings(missing)
+
+		if len(missing) > 0 {
+			unheld, err := w.zeroTokenBalances(ctx, missing)
+			if err != nil {
+				return nil, err
+			}
+			w.markFrozenTokens(unheld)
+			balances = append(balances, unheld...)
+		}
+	}
+
+	tokens := make([]*KnownToken, 0, len(balances))
+	for _, balance := range balances {
+		lastActivity, err := w.lastTokenActivity(balance.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, &KnownToken{TokenBalance: *balance, LastActivity: lastActivity})
+	}
+	return tokens, nil
+}
+
+// zeroTokenBalances returns zero balances for tokens with their SDK metadata
+// and the local registry applied. Tokens the SDK doesn't know keep the
+// registry metadata only.
+func (w *Wallet) zeroTokenBalances(ctx context.Context, tokenIDs []string) ([]*TokenBalance, error) {
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	request := breez_sdk_spark.GetTokensMetadataRequest{TokenIdentifiers: tokenIDs}
+	response, err := w.sdk.GetTokensMetadata(request)
+	traceSDK("GetTokensMetadata", request, response, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to get token metadata: %w", err)
+	}
+
+	metadata := make(map[string]breez_sdk_spark.TokenMetadata, len(response.TokensMetadata))
+	for _, m := range response.TokensMetadata {
+		metadata[m.Identifier] = m
+	}
+
+	balances := make([]*TokenBalance, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		balance := &TokenBalance{TokenID: tokenID, Balance: "0"}
+		if m, ok := metadata[tokenID]; ok {
+			balance.Name = m.Name
+			balance.Ticker = m.Ticker
+			balance.Decimals = int(m.Decimals)
+		}
+		balances = append(balances, balance)
+	}
+	w.enrichTokenMetadata(ctx, balances)
+	return balances, nil
+}
+
+// lastTokenActivity returns the time of the most recent transfer of a token
+func (w *Wallet) lastTokenActivity(tokenID string) (time.Time, error) {
+	if err := w.breaker.Allow(); err != nil {
+		return time.Time{}, err
+	}
+
+	limit := uint32(1)
+	sortAscending := false
+	var assetFilter breez_sdk_spark.AssetFilter = breez_sdk_spark.AssetFilterToken{
+		TokenIdentifier: &tokenID,
+	}
+	request := breez_sdk_spark.ListPaymentsRequest{
+		AssetFilter:   &assetFilter,
+		Limit:         &limit,
+		SortAscending: &sortAscending,
+	}
+	response, err := w.sdk.ListPayments(request)
+	traceSDK("ListPayments", request, response, err)
+	if w.failed(err) {
+		return time.Time{}, fmt.Errorf("failed to get token activity: %w", err)
+	}
+
+	if len(response.Payments) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(response.Payments[0].Timestamp), 0), nil
+}
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tokenMetadataFile is the file in the account data directory holding token metadata overrides
+const tokenMetadataFile = "token_metadata.json"
+
+// TokenMetadataOverride holds user provided metadata that replaces the SDK's
+// token metadata. Empty fields keep the SDK value.
+type TokenMetadataOverride struct {
+	Name     string `json:"name,omitempty"`
+	Ticker   string `json:"ticker,omitempty"`
+	Decimals *int   `json:"decimals,omitempty"`
+	LogoURL  string `json:"logo_url,omitempty"`
+}
+
+// LoadTokenMetadataRegistry returns the token metadata overrides keyed by token ID
+func LoadTokenMetadataRegistry(dataDir string) (map[string]TokenMetadataOverride, error) {
+	registry := make(map[string]TokenMetadataOverride)
+	if err := loadJSON(filepath.Join(dataDir, tokenMetadataFile), &registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// SetTokenMetadata merges the non-empty fields of override into the registry entry for a token
+func SetTokenMetadata(dataDir, tokenID string, override TokenMetadataOverride) error {
+	registry, err := LoadTokenMetadataRegistry(dataDir)
+	if err != nil {
+		return err
+	}
+
+	registry[tokenID] = mergeTokenMetadata(registry[tokenID], override)
+	return saveJSON(filepath.Join(dataDir, tokenMetadataFile), registry)
+}
+
+// mergeTokenMetadata returns base with the non-empty fields of override applied
+func mergeTokenMetadata(base, override TokenMetadataOverride) TokenMetadataOverride {
+	if override.Name != "" {
+		base.Name = override.Name
+	}
+	if override.Ticker != "" {
+		base.Ticker = override.Ticker
+	}
+	if override.Decimals != nil {
+		base.Decimals = override.Decimals
+	}
+	if override.LogoURL != "" {
+		base.LogoURL = override.LogoURL
+	}
+	return base
+}
+
+// enrichTokenMetadata applies the local registry to token balances. Tokens
+// without a name or ticker are looked up on the configured metadata endpoint
+// and the result is saved to the registry.
+func (w *Wallet) enrichTokenMetadata(ctx context.Context, balances []*TokenBalance) {
+	registry, err := LoadTokenMetadataRegistry(w.dataDir)
+	if err != nil {
+		slog.Warn("Failed to load token metadata registry", "error", err)
+		return
+	}
+
+	for _, balance := range balances {
+		override, ok := registry[balance.TokenID]
+		if !ok && w.config.BreezTokenMetadataURL != "" && (balance.Name == "" || balance.Ticker == "") {
+			fetched, err := fetchTokenMetadata(ctx, w.config.BreezTokenMetadataURL, balance.TokenID)
+			if err != nil {
+				slog.Debug("Failed to fetch token metadata", "token_id", balance.TokenID, "error", err)
+			} else {
+				override = *fetched
+				if err := SetTokenMetadata(w.dataDir, balance.TokenID, override); err != nil {
+					slog.Warn("Failed to save token metadata", "token_id", balance.TokenID, "error", err)
+				}
+			}
+		}
+
+		if override.Name != "" {
+			balance.Name = override.Name
+		}
+		if override.Ticker != "" {
+			balance.Ticker = override.Ticker
+		}
+		if override.Decimals != nil {
+			balance.Decimals = *override.Decimals
+		}
+		balance.LogoURL = override.LogoURL
+	}
+}
+
+// fetchTokenMetadata fetches token metadata as JSON from <baseURL>/<token_id>
+func fetchTokenMetadata(ctx context.Context, baseURL, tokenID string) (*TokenMetadataOverride, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	endpoint := strings.TrimRight(baseURL, "/") + "/" + url.PathEscape(tokenID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token metadata endpoint returned %s", resp.Status)
+	}
+
+	var metadata TokenMetadataOverride
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse token metadata: %w", err)
+	}
+	return &metadata, nil
+}
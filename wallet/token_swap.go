@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// SwapResult is the outcome, or with QuoteTokenSwap the estimate, of swapping
+// one token for another. Amounts are in the base units of their token.
+type SwapResult struct {
+	FromTokenID string   `json:"from_token_id"`
+	ToTokenID   string   `json:"to_token_id"`
+	FromAmount  *big.Int `json:"from_amount"`
+	ToAmount    *big.Int `json:"to_amount"`
+	// Rate is the destination base units received per source base unit
+	Rate    *big.Rat `json:"rate"`
+	FeeSats int64    `json:"fee_sats"`
+}
+
+// QuoteTokenSwap estimates swapping fromAmount base units of one token for
+// another without swapping. The SDK only converts between Bitcoin and a single
+// token, through a liquidity pool rather than an atomic swap, so after
+// validating the arguments this always returns ErrNotSupported.
+func (w *Wallet) QuoteTokenSwap(ctx context.Context, fromTokenID, toTokenID string, fromAmount *big.Int) (*SwapResult, error) {
+	defer logCall("QuoteTokenSwap", time.Now())
+	if err := validateTokenSwap(fromTokenID, toTokenID, fromAmount); err != nil {
+		return nil, err
+	}
+	return nil, ErrNotSupported
+}
+
+// SwapTokens swaps fromAmount base units of one token for another. Like
+// QuoteTokenSwap it always returns ErrNotSupported.
+func (w *Wallet) SwapTokens(ctx context.Context, fromTokenID, toTokenID string, fromAmount *big.Int) (*SwapResult, error) {
+	defer logCall("SwapTokens", time.Now())
+	if err := validateTokenSwap(fromTokenID, toTokenID, fromAmount); err != nil {
+		return nil, err
+	}
+	if err := w.checkTokenFrozen(fromTokenID); err != nil {
+		return nil, err
+	}
+	return nil, ErrNotSupported
+}
+
+func validateTokenSwap(fromTokenID, toTokenID string, fromAmount *big.Int) error {
+	if fromTokenID == "" || toTokenID == "" {
+		return fmt.Errorf("both token identifiers are required")
+	}
+	if fromTokenID == toTokenID {
+		return fmt.Errorf("can't swap token %s for itself", fromTokenID)
+	}
+	if fromAmount == nil || fromAmount.Sign() <= 0 {
+		return fmt.Errorf("swap amount must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package wallet
+
+import "testing"
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{"1.5", 6, "1500000", false},
+		{".5", 6, "500000", false},
+		{"1.", 6, "1000000", false},
+		{"0.000001", 6, "1", false},
+		{"1.0000001", 6, "", true},
+		{"42", 0, "42", false},
+		{"4.2", 0, "", true},
+		{"1.5e3", 6, "", true},
+		{"-1", 6, "", true},
+		{"abc", 6, "", true},
+		{".", 6, "", true},
+		{"", 6, "", true},
+		{"0", 6, "0", false},
+		{"0.0", 6, "0", false},
+		{"123456789012345678901234567890.123456789012345678", 18, "123456789012345678901234567890123456789012345678", false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTokenAmount(tt.amount, tt.decimals)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTokenAmount(%q, %d) = %s, want an error", tt.amount, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseTokenAmount(%q, %d) = %v, %v, want %s", tt.amount, tt.decimals, got, err, tt.want)
+		}
+	}
+}
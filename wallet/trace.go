@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+// LevelTrace is the log level below debug at which every SDK request and
+// response is logged
+const LevelTrace = slog.LevelDebug - 4
+
+// secretFieldPattern matches the JSON fields of SDK values that must never be logged
+var secretFieldPattern = regexp.MustCompile(`(?i)("(?:mnemonic|passphrase|api_?key|preimage|secret)"\s*:\s*)"[^"]*"`)
+
+// logCall logs a wallet method call with its duration at debug level. It is
+// deferred at the start of the method with the current time.
+func logCall(method string, start time.Time) {
+	slog.Debug("Wallet call", "method", method, "duration", time.Since(start))
+}
+
+// traceSDK logs an SDK request with its response or error at trace level
+func traceSDK(method string, request, response any, err error) {
+	ctx := context.Background()
+	if !slog.Default().Enabled(ctx, LevelTrace) {
+		return
+	}
+
+	attrs := []any{"method", method, "request", redact(request)}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	} else {
+		attrs = append(attrs, "response", redact(response))
+	}
+	slog.Log(ctx, LevelTrace, "SDK call", attrs...)
+}
+
+// redact formats an SDK value as JSON with secrets such as the mnemonic and
+// payment preimages replaced
+func redact(value any) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("<%T>", value)
+	}
+	return secretFieldPattern.ReplaceAllString(string(data), `$1"[REDACTED]"`)
+}
+
+// eventLogger logs SDK events: sync events at info level, the others at debug.
+// Syncs are also signalled on synced without blocking the SDK.
+type eventLogger struct {
+	synced chan<- struct{}
+}
+
+func (l eventLogger) OnEvent(event breez_sdk_spark.SdkEvent) {
+	switch e := event.(type) {
+	case breez_sdk_spark.SdkEventSynced:
+		slog.Info("Wallet synced")
+		select {
+		case l.synced <- struct{}{}:
+		default:
+		}
+	case breez_sdk_spark.SdkEventClaimedDeposits:
+		slog.Info("Claimed deposits", "count", len(e.ClaimedDeposits))
+	case breez_sdk_spark.SdkEventUnclaimedDeposits:
+		slog.Info("Deposits could not be claimed", "count", len(e.UnclaimedDeposits))
+	case breez_sdk_spark.SdkEventPaymentSucceeded:
+		slog.Debug("Payment succeeded", "payment_id", e.Payment.Id)
+	case breez_sdk_spark.SdkEventPaymentPending:
+		slog.Debug("Payment pending", "payment_id", e.Payment.Id)
+	case breez_sdk_spark.SdkEventPaymentFailed:
+		slog.Debug("Payment failed", "payment_id", e.Payment.Id)
+	default:
+		slog.Debug("SDK event", "type", fmt.Sprintf("%T", event))
+	}
+}
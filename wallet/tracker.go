@@ -0,0 +1,209 @@
+package wallet
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
+)
+
+const (
+	// PaymentTrackerInterval is how often TrackPayments polls pending payments
+	PaymentTrackerInterval = 15 * time.Second
+	// eventBufferSize is the number of events buffered for a slow reader
+	eventBufferSize = 64
+)
+
+// Event types of a PaymentEvent
+const (
+	EventPaymentReceived = "payment_received"
+	EventPaymentSent     = "payment_sent"
+	EventPaymentFailed   = "payment_failed"
+	EventBalanceUpdated  = "balance_updated"
+	EventSyncComplete    = "sync_complete"
+)
+
+// PaymentEvent reports a change in the wallet. Payment events carry the
+// payment, balance_updated carries the new balance and sync_complete nothing.
+type PaymentEvent struct {
+	Type           string       `json:"type"`
+	PaymentID      string       `json:"payment_id,omitempty"`
+	PreviousStatus string       `json:"previous_status,omitempty"`
+	Status         string       `json:"status,omitempty"`
+	Transaction    *Transaction `json:"transaction,omitempty"`
+	Balance        *Balance     `json:"balance,omitempty"`
+}
+
+// Events returns the channel TrackPayments emits payment events on. It is
+// closed when the tracker stops.
+func (w *Wallet) Events() <-chan PaymentEvent {
+	return w.events
+}
+
+// TrackPayments polls the pending outgoing payments and the payments received
+// since it started every interval until ctx is done. It emits an event when a
+// pending payment completes or fails, when a payment is received, after each
+// of those with the new balance, and when the SDK finishes a sync. It must be
+// called at most once per wallet.
+func (w *Wallet) TrackPayments(ctx context.Context, interval time.Duration) {
+	defer close(w.events)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	since := uint64(time.Now().Unix())
+	pending := make(map[string]bool)
+	received := make(map[string]bool)
+	for {
+		changed := false
+		pending, changed = w.pollPendingPayments(ctx, pending)
+		if w.pollReceivedPayments(ctx, since, received) {
+			changed = true
+		}
+		if changed {
+			w.emitBalance(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.syncs:
+			w.emit(PaymentEvent{Type: EventSyncComplete})
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollPendingPayments emits events for the payments in pending that are no
+// longer pending and returns the set of payments still pending, and whether
+// any event was emitted
+func (w *Wallet) pollPendingPayments(ctx context.Context, pending map[string]bool) (map[string]bool, bool) {
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeSend}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusPending}
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+		TypeFilter:   &typeFilter,
+		StatusFilter: &statusFilter,
+	})
+	if err != nil {
+		slog.Warn("Failed to list pending payments", "error", err)
+		return pending, false
+	}
+
+	stillPending := make(map[string]bool, len(payments))
+	for _, payment := range payments {
+		stillPending[payment.Id] = true
+	}
+
+	changed := false
+	for id := range pending {
+		if stillPending[id] {
+			continue
+		}
+
+		tx, err := w.GetPayment(ctx, id)
+		if err != nil {
+			// Try again on the next poll
+			slog.Warn("Failed to get payment status", "payment_id", id, "error", err)
+			stillPending[id] = true
+			continue
+		}
+		if tx.Status == paymentStatusString(breez_sdk_spark.PaymentStatusPending) {
+			stillPending[id] = true
+			continue
+		}
+
+		eventType := EventPaymentSent
+		if tx.Status == paymentStatusString(breez_sdk_spark.PaymentStatusFailed) {
+			eventType = EventPaymentFailed
+		}
+		w.emit(PaymentEvent{
+			Type:           eventType,
+			PaymentID:      id,
+			PreviousStatus: paymentStatusString(breez_sdk_spark.PaymentStatusPending),
+			Status:         tx.Status,
+			Transaction:    tx,
+		})
+		changed = true
+	}
+
+	return stillPending, changed
+}
+
+// ReceivedPaymentsSince returns the completed incoming payments created at or
+// after the Unix time since. Pollers keep the IDs they have seen, since every
+// call returns all of them again.
+func (w *Wallet) ReceivedPaymentsSince(ctx context.Context, since uint64) ([]*Transaction, error) {
+	typeFilter := []breez_sdk_spark.PaymentType{breez_sdk_spark.PaymentTypeReceive}
+	statusFilter := []breez_sdk_spark.PaymentStatus{breez_sdk_spark.PaymentStatusCompleted}
+	payments, err := w.listAllPayments(ctx, breez_sdk_spark.ListPaymentsRequest{
+		TypeFilter:    &typeFilter,
+		StatusFilter:  &statusFilter,
+		FromTimestamp: &since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*Transaction, 0, len(payments))
+	for _, payment := range payments {
+		tx := transactionFromPayment(payment)
+		w.annotate(tx)
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// pollReceivedPayments emits an event for each completed incoming payment
+// created since the tracker started that is not yet in seen, and reports
+// whether it emitted any
+func (w *Wallet) pollReceivedPayments(ctx context.Context, since uint64, seen map[string]bool) bool {
+	txs, err := w.ReceivedPaymentsSince(ctx, since)
+	if err != nil {
+		slog.Warn("Failed to list received payments", "error", err)
+		return false
+	}
+
+	changed := false
+	for _, tx := range txs {
+		if seen[tx.ID] {
+			continue
+		}
+		seen[tx.ID] = true
+
+		if tx.Type == probeType {
+			if w.autoAcceptProbing {
+				continue
+			}
+			slog.Info("Received a probing payment", "payment_id", tx.ID, "amount_sats", tx.AmountSats)
+		}
+		w.emit(PaymentEvent{
+			Type:        EventPaymentReceived,
+			PaymentID:   tx.ID,
+			Status:      tx.Status,
+			Transaction: tx,
+		})
+		changed = true
+	}
+	return changed
+}
+
+// emitBalance emits a balance_updated event with the current balance
+func (w *Wallet) emitBalance(ctx context.Context) {
+	balance, err := w.GetBalance(ctx)
+	if err != nil {
+		slog.Warn("Failed to get balance for balance event", "error", err)
+		return
+	}
+	w.emit(PaymentEvent{Type: EventBalanceUpdated, Balance: balance})
+}
+
+// emit sends an event without blocking the tracker, dropping it if the
+// buffer is full
+func (w *Wallet) emit(event PaymentEvent) {
+	select {
+	case w.events <- event:
+	default:
+		slog.Warn("Dropped payment event, no reader is keeping up", "type", event.Type, "payment_id", event.PaymentID)
+	}
+}
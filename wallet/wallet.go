@@ -4,63 +4,96 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	breez_sdk_spark "github.com/breez/breez-sdk-spark-go/breez_sdk_spark"
 	"github.com/breez/tiny-spark/config"
+	"github.com/breez/tiny-spark/uri"
 )
 
 type Wallet struct {
-	sdk    *breez_sdk_spark.BreezSdk
-	config *config.Config
+	sdk     *breez_sdk_spark.BreezSdk
+	config  *config.Config
+	breaker *CircuitBreaker
+
+	balanceMu        sync.Mutex
+	balance          *Balance
+	balanceFetchedAt time.Time
+
+	events chan PaymentEvent
+	// syncs has an element when the SDK finished a sync since TrackPayments
+	// last looked. It is never closed, since SDK events can arrive at any time.
+	syncs chan struct{}
+	// account is the account the wallet is connected to
+	account Account
+	// dataDir holds the account's SDK storage and local files, such as the
+	// invoices and the payment cache
+	dataDir string
+	// autoAcceptProbing accepts probes silently, see SetAutoAcceptProbing
+	autoAcceptProbing bool
+
+	identityMu sync.Mutex
+	// identity is the wallet's identity public key, once fetched
+	identity string
 }
 
 type Balance struct {
-	LightningBalanceSats int64
-	MaxPayableSats       int64
-	MaxReceivableSats    int64
+	LightningBalanceSats int64 `json:"lightning_balance_sats"`
+	MaxPayableSats       int64 `json:"max_payable_sats"`
+	MaxReceivableSats    int64 `json:"max_receivable_sats"`
 }
 
 type Transaction struct {
-	ID          string
-	AmountSats  int64
-	FeeSats     int64
-	Status      string
-	Type        string
-	Description string
-	Timestamp   time.Time
-	PaymentHash string
+	ID          string    `json:"id"`
+	AmountSats  int64     `json:"amount_sats"`
+	FeeSats     int64     `json:"fee_sats"`
+	Status      string    `json:"status"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Timestamp   time.Time `json:"timestamp"`
+	PaymentHash string    `json:"payment_hash"`
+	Memo        string    `json:"memo,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
 }
 
 type ReceivePaymentResponse struct {
-	PaymentRequest string
-	AmountSats     int64
-	FeeSats        int64
-	Description    string
-	ExpiresAt      time.Time
+	PaymentRequest string    `json:"payment_request"`
+	AmountSats     int64     `json:"amount_sats"`
+	FeeSats        int64     `json:"fee_sats"`
+	Description    string    `json:"description"`
+	ExpiresAt      time.Time `json:"expires_at"`
 }
 
 type PaymentResponse struct {
-	PaymentHash string
-	AmountSats  int64
-	FeeSats     int64
-	Status      string
-	Preimage    string
-	CompletedAt time.Time
+	PaymentHash string    `json:"payment_hash"`
+	AmountSats  int64     `json:"amount_sats"`
+	FeeSats     int64     `json:"fee_sats"`
+	Status      string    `json:"status"`
+	Preimage    string    `json:"preimage,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
 }
 
 type TokenBalance struct {
-	TokenID  string
-	Balance  string
-	Name     string
-	Ticker   string
-	Decimals int
+	TokenID  string `json:"token_id"`
+	Balance  string `json:"balance"`
+	Name     string `json:"name"`
+	Ticker   string `json:"ticker"`
+	Decimals int    `json:"decimals"`
+	LogoURL  string `json:"logo_url,omitempty"`
+	Frozen   bool   `json:"frozen"`
 }
 
 // NewWallet initializes a new Breez SDK wallet
 func NewWallet(cfg *config.Config) (*Wallet, error) {
+	if err := ValidateMnemonic(cfg.BreezMnemonic); err != nil {
+		return nil, fmt.Errorf("invalid BREEZ_MNEMONIC: %w", err)
+	}
+
 	// Create working directory if it doesn't exist
 	if err := createWorkingDir(cfg.BreezWorkingDir); err != nil {
 		return nil, fmt.Errorf("failed to create working directory: %w", err)
@@ -74,38 +107,84 @@ func NewWallet(cfg *config.Config) (*Wallet, error) {
 	} else {
 		sdkConfig.ApiKey = &cfg.BreezAPIKey
 	}
-	sdkConfig.SyncIntervalSecs = 60 // Use longer sync interval for better data
+	if cfg.BreezSyncIntervalSecs > 0 {
+		sdkConfig.SyncIntervalSecs = uint32(cfg.BreezSyncIntervalSecs)
+	}
 
 	// Create seed from mnemonic
 	seed := breez_sdk_spark.SeedMnemonic{
 		Mnemonic: cfg.BreezMnemonic,
 	}
 
-	// Connect to SDK
-	request := breez_sdk_spark.ConnectRequest{
-		Config:     sdkConfig,
-		Seed:       seed,
-		StorageDir: cfg.BreezWorkingDir,
+	account, err := currentAccount(cfg.BreezWorkingDir)
+	if err != nil {
+		return nil, err
 	}
 
-	sdk, err := breez_sdk_spark.Connect(request)
+	// Connect to SDK
+	var sdk *breez_sdk_spark.BreezSdk
+	if account.Number == nil {
+		request := breez_sdk_spark.ConnectRequest{
+			Config:     sdkConfig,
+			Seed:       seed,
+			StorageDir: cfg.BreezWorkingDir,
+		}
+		sdk, err = breez_sdk_spark.Connect(request)
+		traceSDK("Connect", request, nil, err)
+	} else {
+		sdk, err = connectAccount(sdkConfig, seed, account, account.storageDir(cfg.BreezWorkingDir))
+	}
 
 	// Handle error using official SDK pattern
 	if isSdkError(err) {
 		return nil, fmt.Errorf("failed to connect to Breez SDK: %w", err)
 	}
 
+	if err := storeNetwork(cfg.BreezWorkingDir, cfg.BreezNetwork); err != nil {
+		slog.Warn("Failed to record the wallet network", "error", err)
+	}
+	syncs := make(chan struct{}, 1)
+	sdk.AddEventListener(eventLogger{synced: syncs})
+
 	// Wait longer for initial sync
 	time.Sleep(10 * time.Second)
 
 	wallet := &Wallet{
-		sdk:    sdk,
-		config: cfg,
+		sdk:     sdk,
+		config:  cfg,
+		breaker: NewCircuitBreaker(cfg.BreezCircuitFailureThreshold, time.Duration(cfg.BreezCircuitResetSecs)*time.Second),
+		events:  make(chan PaymentEvent, eventBufferSize),
+		syncs:   syncs,
+		account: account,
+		dataDir: account.storageDir(cfg.BreezWorkingDir),
+
+		autoAcceptProbing: cfg.BreezAutoAcceptProbing,
 	}
 
 	return wallet, nil
 }
 
+// connectAccount connects to the SDK with the keys of a created account, which
+// are derived with its account number and stored in their own directory
+func connectAccount(sdkConfig breez_sdk_spark.Config, seed breez_sdk_spark.Seed, account Account, storageDir string) (*breez_sdk_spark.BreezSdk, error) {
+	if err := createWorkingDir(storageDir); err != nil {
+		return nil, fmt.Errorf("failed to create account directory: %w", err)
+	}
+
+	builder := breez_sdk_spark.NewSdkBuilder(sdkConfig, seed)
+	defer builder.Destroy()
+	builder.WithDefaultStorage(storageDir)
+	keySet := breez_sdk_spark.KeySetConfig{
+		KeySetType:    breez_sdk_spark.KeySetTypeDefault,
+		AccountNumber: account.Number,
+	}
+	builder.WithKeySet(keySet)
+
+	sdk, err := builder.Build()
+	traceSDK("Build", keySet, nil, err)
+	return sdk, err
+}
+
 // Close closes the SDK connection
 func (w *Wallet) Close() error {
 	if w.sdk != nil {
@@ -116,11 +195,17 @@ func (w *Wallet) Close() error {
 
 // GetBalance retrieves the wallet balance
 func (w *Wallet) GetBalance(ctx context.Context) (*Balance, error) {
+	defer logCall("GetBalance", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	req := breez_sdk_spark.GetInfoRequest{}
 	info, err := w.sdk.GetInfo(req)
+	traceSDK("GetInfo", req, info, err)
 
 	// Handle error using official SDK pattern
-	if isSdkError(err) {
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to get wallet info: %w", err)
 	}
 
@@ -138,15 +223,22 @@ func (w *Wallet) GetBalance(ctx context.Context) (*Balance, error) {
 		}
 	}
 
-	return &Balance{
+	balance := &Balance{
 		LightningBalanceSats: balanceSats,
 		MaxPayableSats:       balanceSats,
 		MaxReceivableSats:    balanceSats,
-	}, nil
+	}
+	w.storeBalance(balance)
+	return balance, nil
 }
 
 // GetTransactions retrieves transaction history
 func (w *Wallet) GetTransactions(ctx context.Context, limit int) ([]*Transaction, error) {
+	defer logCall("GetTransactions", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	offsetPtr := uint32(0)
 	limitPtr := uint32(limit)
 	if limitPtr < 10 {
@@ -160,104 +252,249 @@ func (w *Wallet) GetTransactions(ctx context.Context, limit int) ([]*Transaction
 	}
 
 	response, err := w.sdk.ListPayments(req)
+	traceSDK("ListPayments", req, response, err)
 
 	// Handle error using official SDK pattern
-	if isSdkError(err) {
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to get transaction history: %w", err)
 	}
 
 	transactions := make([]*Transaction, len(response.Payments))
 	for i, payment := range response.Payments {
-		var txType string
+		transactions[i] = transactionFromPayment(payment)
+	}
+	w.annotate(transactions...)
 
-		// Get raw amounts from SDK
-		rawAmount := payment.Amount.Int64()
-		fee := payment.Fees.Int64()
-		var amount int64
+	return transactions, nil
+}
 
-		// Use PaymentType enum for classification and amount sign correction
-		switch payment.PaymentType {
-		case breez_sdk_spark.PaymentTypeReceive:
+// transactionFromPayment converts an SDK payment to a Transaction
+func transactionFromPayment(payment breez_sdk_spark.Payment) *Transaction {
+	var txType string
+
+	// Get raw amounts from SDK
+	rawAmount := payment.Amount.Int64()
+	fee := payment.Fees.Int64()
+	var amount int64
+
+	// Use PaymentType enum for classification and amount sign correction
+	switch payment.PaymentType {
+	case breez_sdk_spark.PaymentTypeReceive:
+		txType = "receive"
+		// Keep amount positive for receive transactions
+		amount = rawAmount
+	case breez_sdk_spark.PaymentTypeSend:
+		txType = "send"
+		// Make amount negative for send transactions
+		amount = -rawAmount
+	default:
+		// Fallback to amount-based classification
+		if rawAmount > 0 {
 			txType = "receive"
-			// Keep amount positive for receive transactions
 			amount = rawAmount
-		case breez_sdk_spark.PaymentTypeSend:
+		} else {
 			txType = "send"
-			// Make amount negative for send transactions
-			amount = -rawAmount
-		default:
-			// Fallback to amount-based classification
-			if rawAmount > 0 {
-				txType = "receive"
-				amount = rawAmount
-			} else {
-				txType = "send"
-				amount = rawAmount
+			amount = rawAmount
+		}
+	}
+
+	// Convert payment status to readable format
+	var statusStr string
+	switch payment.Status {
+	case breez_sdk_spark.PaymentStatusPending:
+		statusStr = "Pending"
+	case breez_sdk_spark.PaymentStatusCompleted:
+		statusStr = "Complete"
+	case breez_sdk_spark.PaymentStatusFailed:
+		statusStr = "Failed"
+	default:
+		statusStr = paymentStatusString(payment.Status)
+	}
+
+	return &Transaction{
+		ID:          payment.Id,
+		AmountSats:  amount,
+		FeeSats:     fee,
+		Status:      statusStr,
+		Type:        txType,
+		Description: getPaymentDescription(payment),
+		Timestamp:   time.Unix(int64(payment.Timestamp), 0),
+		PaymentHash: paymentHash(payment),
+	}
+}
+
+// paymentHash returns the payment hash of a Lightning payment. Other
+// payments have none, so their payment ID is used instead.
+func paymentHash(payment breez_sdk_spark.Payment) string {
+	if payment.Details != nil {
+		if details, ok := (*payment.Details).(breez_sdk_spark.PaymentDetailsLightning); ok && details.HtlcDetails.PaymentHash != "" {
+			return details.HtlcDetails.PaymentHash
+		}
+	}
+	return payment.Id
+}
+
+// defaultDescription is the description of payments that have none
+const defaultDescription = "Payment"
+
+// getPaymentDescription returns the description of a payment: the one the SDK
+// reports, else the one in its BOLT11 invoice, which the SDK leaves out for
+// some sent payments. Without either it is "Payment"; annotate then uses the
+// payment's memo if it has one.
+func getPaymentDescription(payment breez_sdk_spark.Payment) string {
+	var description *string
+	var invoice string
+	if payment.Details != nil {
+		switch details := (*payment.Details).(type) {
+		case breez_sdk_spark.PaymentDetailsLightning:
+			description = details.Description
+			invoice = details.Invoice
+		case breez_sdk_spark.PaymentDetailsSpark:
+			if details.InvoiceDetails != nil {
+				description = details.InvoiceDetails.Description
+			}
+		case breez_sdk_spark.PaymentDetailsToken:
+			if details.InvoiceDetails != nil {
+				description = details.InvoiceDetails.Description
 			}
 		}
+	}
+
+	if description != nil && strings.TrimSpace(*description) != "" {
+		return *description
+	}
+	if decoded, ok := bolt11Description(invoice); ok && strings.TrimSpace(decoded) != "" {
+		return decoded
+	}
+	return defaultDescription
+}
 
-		// Convert payment status to readable format
-		var statusStr string
-		switch payment.Status {
-		case breez_sdk_spark.PaymentStatusPending:
-			statusStr = "Pending"
-		case breez_sdk_spark.PaymentStatusCompleted:
-			statusStr = "Complete"
-		case breez_sdk_spark.PaymentStatusFailed:
-			statusStr = "Failed"
-		default:
-			statusStr = paymentStatusString(payment.Status)
+// DeduplicateTransactions removes transactions with an already seen ID,
+// keeping the first occurrence and the original order
+func DeduplicateTransactions(txs []*Transaction) []*Transaction {
+	seen := make(map[string]bool, len(txs))
+	unique := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if seen[tx.ID] {
+			continue
 		}
+		seen[tx.ID] = true
+		unique = append(unique, tx)
+	}
+	return unique
+}
 
-		// Use generic description for now
-		description := "Payment"
+// WaitForIncomingPayment polls the payment history until a new completed incoming
+// payment of at least minAmountSats arrives or the context is done
+func (w *Wallet) WaitForIncomingPayment(ctx context.Context, minAmountSats int64, interval time.Duration) (*Transaction, error) {
+	transactions, err := w.GetTransactions(ctx, 100)
+	if err != nil {
+		return nil, err
+	}
 
-		transactions[i] = &Transaction{
-			ID:          payment.Id,
-			AmountSats:  amount,
-			FeeSats:     fee,
-			Status:      statusStr,
-			Type:        txType,
-			Description: description,
-			Timestamp:   time.Unix(int64(payment.Timestamp), 0),
-			PaymentHash: payment.Id,
+	// Pending payments are left out so they are reported once they complete
+	known := make(map[string]bool)
+	for _, tx := range transactions {
+		if tx.Status != "Pending" {
+			known[tx.ID] = true
 		}
 	}
 
-	return transactions, nil
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		transactions, err := w.GetTransactions(ctx, 100)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range transactions {
+			if known[tx.ID] || tx.Status == "Pending" {
+				continue
+			}
+			known[tx.ID] = true
+
+			if tx.Type == "receive" && tx.Status == "Complete" && tx.AmountSats >= minAmountSats {
+				return tx, nil
+			}
+		}
+	}
 }
 
-// ReceiveLightningInvoice creates a Lightning invoice for receiving payments
+// ReceiveLightningInvoice creates a Lightning invoice for receiving payments.
+// The invoice is recorded in the invoices file, like those of CreateInvoice,
+// so it can be recognized as the wallet's own.
 func (w *Wallet) ReceiveLightningInvoice(ctx context.Context, amountSats uint64, description string) (*ReceivePaymentResponse, error) {
+	defer logCall("ReceiveLightningInvoice", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	expirySecs := uint32(defaultInvoiceExpiry / time.Second)
 	request := breez_sdk_spark.ReceivePaymentRequest{
 		PaymentMethod: breez_sdk_spark.ReceivePaymentMethodBolt11Invoice{
 			Description: description,
 			AmountSats:  &amountSats,
+			ExpirySecs:  &expirySecs,
 		},
 	}
 
 	response, err := w.sdk.ReceivePayment(request)
-	if isSdkError(err) {
+	traceSDK("ReceivePayment", request, response, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to create lightning invoice: %w", err)
 	}
 
+	now := time.Now()
+	invoice := &Invoice{
+		Bolt11:      response.PaymentRequest,
+		AmountSats:  amountSats,
+		Description: description,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(defaultInvoiceExpiry),
+		Status:      InvoiceStatusUnpaid,
+	}
+	// The invoice is already usable, so failing to record it only costs
+	// recognizing it later
+	if hash, ok := bolt11PaymentHash(response.PaymentRequest); !ok {
+		slog.Warn("Failed to decode the payment hash of the created invoice")
+	} else {
+		invoice.ID = hash
+		if err := w.recordInvoice(invoice); err != nil {
+			slog.Warn("Failed to record the created invoice", "payment_hash", hash, "error", err)
+		}
+	}
+
 	return &ReceivePaymentResponse{
 		PaymentRequest: response.PaymentRequest,
 		FeeSats:        response.Fee.Int64(),
 		AmountSats:     int64(amountSats),
 		Description:    description,
-		ExpiresAt:      time.Now().Add(24 * time.Hour),
+		ExpiresAt:      invoice.ExpiresAt,
 	}, nil
 }
 
 // ReceiveBitcoinAddress creates a Bitcoin address for receiving on-chain payments
 func (w *Wallet) ReceiveBitcoinAddress(ctx context.Context) (*ReceivePaymentResponse, error) {
+	defer logCall("ReceiveBitcoinAddress", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	request := breez_sdk_spark.ReceivePaymentRequest{
 		PaymentMethod: breez_sdk_spark.ReceivePaymentMethodBitcoinAddress{},
 	}
 
 	response, err := w.sdk.ReceivePayment(request)
-	if isSdkError(err) {
+	traceSDK("ReceivePayment", request, response, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to create bitcoin address: %w", err)
 	}
 
@@ -270,20 +507,36 @@ func (w *Wallet) ReceiveBitcoinAddress(ctx context.Context) (*ReceivePaymentResp
 	}, nil
 }
 
-// ReceiveSparkAddress creates a Spark address for receiving payments
-func (w *Wallet) ReceiveSparkAddress(ctx context.Context) (*ReceivePaymentResponse, error) {
-	request := breez_sdk_spark.ReceivePaymentRequest{
-		PaymentMethod: breez_sdk_spark.ReceivePaymentMethodSparkAddress{},
+// ReceiveBitcoinAddressWithAmount creates a Bitcoin address and returns it as a
+// BIP21 URI requesting the given amount
+func (w *Wallet) ReceiveBitcoinAddressWithAmount(ctx context.Context, amountSats uint64, label string) (*ReceivePaymentResponse, error) {
+	defer logCall("ReceiveBitcoinAddressWithAmount", time.Now())
+	response, err := w.ReceiveBitcoinAddress(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := w.sdk.ReceivePayment(request)
-	if isSdkError(err) {
-		return nil, fmt.Errorf("failed to create spark address: %w", err)
+	response.PaymentRequest = uri.GenerateBIP21(response.PaymentRequest, amountSats, label)
+	response.AmountSats = int64(amountSats)
+	if label != "" {
+		response.Description = label
+	}
+	return response, nil
+}
+
+// ReceiveSparkAddress returns the Spark address for receiving payments. The
+// address is cached, see GetSparkAddress.
+func (w *Wallet) ReceiveSparkAddress(ctx context.Context) (*ReceivePaymentResponse, error) {
+	defer logCall("ReceiveSparkAddress", time.Now())
+	address, err := w.GetSparkAddress(ctx)
+	if err != nil {
+		return nil, err
 	}
 
+	// Receiving on a Spark address is free
 	return &ReceivePaymentResponse{
-		PaymentRequest: response.PaymentRequest,
-		FeeSats:        response.Fee.Int64(),
+		PaymentRequest: address,
+		FeeSats:        0,
 		AmountSats:     0,
 		Description:    "Spark address deposit",
 		ExpiresAt:      time.Now().Add(24 * time.Hour),
@@ -292,6 +545,19 @@ func (w *Wallet) ReceiveSparkAddress(ctx context.Context) (*ReceivePaymentRespon
 
 // SendLightningInvoice pays a Lightning invoice
 func (w *Wallet) SendLightningInvoice(ctx context.Context, bolt11 string) (*PaymentResponse, error) {
+	defer logCall("SendLightningInvoice", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	// The SDK has no option to exclude nodes from routing yet
+	if blacklist, err := LoadBlacklist(w.dataDir); err != nil {
+		slog.Warn("Failed to load node blacklist", "error", err)
+	} else if len(blacklist) > 0 {
+		slog.Warn("Node blacklist is stored but not enforced: the SDK does not support excluding nodes from routing",
+			"blacklisted_nodes", len(blacklist))
+	}
+
 	// Prepare the payment first
 	prepareReq := breez_sdk_spark.PrepareSendPaymentRequest{
 		PaymentRequest: bolt11,
@@ -299,9 +565,13 @@ func (w *Wallet) SendLightningInvoice(ctx context.Context, bolt11 string) (*Paym
 	}
 
 	prepareResp, err := w.sdk.PrepareSendPayment(prepareReq)
-	if isSdkError(err) {
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to prepare lightning payment: %w", err)
 	}
+	if err := w.checkBalance(ctx, prepareResp.Amount.Int64(), preparedFeeSats(prepareResp)); err != nil {
+		return nil, err
+	}
 
 	// Send the payment
 	sendReq := breez_sdk_spark.SendPaymentRequest{
@@ -309,7 +579,9 @@ func (w *Wallet) SendLightningInvoice(ctx context.Context, bolt11 string) (*Paym
 	}
 
 	response, err := w.sdk.SendPayment(sendReq)
-	if isSdkError(err) {
+	traceSDK("SendPayment", sendReq, response, err)
+	w.invalidateBalance()
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to send lightning payment: %w", err)
 	}
 
@@ -324,6 +596,11 @@ func (w *Wallet) SendLightningInvoice(ctx context.Context, bolt11 string) (*Paym
 
 // SendBitcoinAddress sends Bitcoin to an on-chain address
 func (w *Wallet) SendBitcoinAddress(ctx context.Context, address string, amountSats int64) (*PaymentResponse, error) {
+	defer logCall("SendBitcoinAddress", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	// Convert int64 to big.Int for SDK
 	amount := big.NewInt(amountSats)
 
@@ -334,9 +611,13 @@ func (w *Wallet) SendBitcoinAddress(ctx context.Context, address string, amountS
 	}
 
 	prepareResp, err := w.sdk.PrepareSendPayment(prepareReq)
-	if isSdkError(err) {
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to prepare onchain payment: %w", err)
 	}
+	if err := w.checkBalance(ctx, amountSats, preparedFeeSats(prepareResp)); err != nil {
+		return nil, err
+	}
 
 	// Send the payment with medium confirmation speed
 	var options breez_sdk_spark.SendPaymentOptions = breez_sdk_spark.SendPaymentOptionsBitcoinAddress{
@@ -349,7 +630,9 @@ func (w *Wallet) SendBitcoinAddress(ctx context.Context, address string, amountS
 	}
 
 	response, err := w.sdk.SendPayment(sendReq)
-	if isSdkError(err) {
+	traceSDK("SendPayment", sendReq, response, err)
+	w.invalidateBalance()
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to send onchain payment: %w", err)
 	}
 
@@ -364,6 +647,11 @@ func (w *Wallet) SendBitcoinAddress(ctx context.Context, address string, amountS
 
 // SendSparkAddress sends to a Spark address
 func (w *Wallet) SendSparkAddress(ctx context.Context, sparkAddress string, amountSats int64) (*PaymentResponse, error) {
+	defer logCall("SendSparkAddress", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	// Convert int64 to big.Int for SDK
 	amount := big.NewInt(amountSats)
 
@@ -374,9 +662,14 @@ func (w *Wallet) SendSparkAddress(ctx context.Context, sparkAddress string, amou
 	}
 
 	prepareResp, err := w.sdk.PrepareSendPayment(prepareReq)
-	if isSdkError(err) {
+	traceSDK("PrepareSendPayment", prepareReq, prepareResp, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to prepare spark payment: %w", err)
 	}
+	// Spark transfers are free, so only the quoted fee is added, if any
+	if err := w.checkBalance(ctx, amountSats, preparedFeeSats(prepareResp)); err != nil {
+		return nil, err
+	}
 
 	// Send the payment
 	sendReq := breez_sdk_spark.SendPaymentRequest{
@@ -384,7 +677,9 @@ func (w *Wallet) SendSparkAddress(ctx context.Context, sparkAddress string, amou
 	}
 
 	response, err := w.sdk.SendPayment(sendReq)
-	if isSdkError(err) {
+	traceSDK("SendPayment", sendReq, response, err)
+	w.invalidateBalance()
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to send spark payment: %w", err)
 	}
 
@@ -399,12 +694,18 @@ func (w *Wallet) SendSparkAddress(ctx context.Context, sparkAddress string, amou
 
 // GetPayment retrieves a specific payment by ID
 func (w *Wallet) GetPayment(ctx context.Context, paymentID string) (*Transaction, error) {
+	defer logCall("GetPayment", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	req := breez_sdk_spark.GetPaymentRequest{
 		PaymentId: paymentID,
 	}
 
 	response, err := w.sdk.GetPayment(req)
-	if isSdkError(err) {
+	traceSDK("GetPayment", req, response, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 
@@ -429,73 +730,158 @@ func (w *Wallet) GetPayment(ctx context.Context, paymentID string) (*Transaction
 		statusStr = paymentStatusString(payment.Status)
 	}
 
-	return &Transaction{
+	tx := &Transaction{
 		ID:          payment.Id,
 		AmountSats:  payment.Amount.Int64(),
 		FeeSats:     payment.Fees.Int64(),
 		Status:      statusStr,
 		Type:        txType,
-		Description: "Payment",
+		Description: getPaymentDescription(payment),
 		Timestamp:   time.Unix(int64(payment.Timestamp), 0),
-		PaymentHash: payment.Id,
-	}, nil
+		PaymentHash: paymentHash(payment),
+	}
+	w.annotate(tx)
+	return tx, nil
 }
 
-// LnUrlPay prepares and sends LNURL payments
+// LnUrlPay pays an LNURL or Lightning address. Other destinations the SDK
+// recognizes, such as invoices or Spark addresses pasted by mistake, are paid
+// with the matching send method.
 func (w *Wallet) LnUrlPay(ctx context.Context, lnurlAddress string, amountSats uint64, comment string) (*PaymentResponse, error) {
+	defer logCall("LnUrlPay", time.Now())
+	return w.LnUrlPayWithFeeLimit(ctx, lnurlAddress, amountSats, comment, FeeLimit{})
+}
+
+// LnUrlPayWithFeeLimit is LnUrlPay refusing with ErrFeeTooHigh, after the
+// payment is prepared and before it is sent, if the LNURL payment fee is above
+// limit. Other destinations are paid without checking the limit.
+func (w *Wallet) LnUrlPayWithFeeLimit(ctx context.Context, lnurlAddress string, amountSats uint64, comment string, limit FeeLimit) (*PaymentResponse, error) {
+	defer logCall("LnUrlPayWithFeeLimit", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	// Parse the LNURL address
 	input, err := w.sdk.Parse(lnurlAddress)
-	if isSdkError(err) {
+	traceSDK("Parse", lnurlAddress, input, err)
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to parse lnurl address: %w", err)
 	}
 
+	return w.payInput(ctx, input, amountSats, comment, limit)
+}
+
+// payInput pays a parsed payment destination
+func (w *Wallet) payInput(ctx context.Context, input breez_sdk_spark.InputType, amountSats uint64, comment string, limit FeeLimit) (*PaymentResponse, error) {
 	switch inputType := input.(type) {
 	case breez_sdk_spark.InputTypeLightningAddress:
-		validateSuccessActionUrl := true
-		amount := big.NewInt(int64(amountSats))
-
-		prepareReq := breez_sdk_spark.PrepareLnurlPayRequest{
-			Amount:                   amount,
-			PayRequest:               inputType.Field0.PayRequest,
-			Comment:                  &comment,
-			ValidateSuccessActionUrl: &validateSuccessActionUrl,
+		return w.payLnurlRequest(ctx, inputType.Field0.PayRequest, amountSats, comment, limit)
+	case breez_sdk_spark.InputTypeLnurlPay:
+		return w.payLnurlRequest(ctx, inputType.Field0, amountSats, comment, limit)
+	case breez_sdk_spark.InputTypeBolt11Invoice:
+		// The amount is taken from the invoice
+		return w.SendLightningInvoice(ctx, inputType.Field0.Invoice.Bolt11)
+	case breez_sdk_spark.InputTypeBitcoinAddress:
+		return w.SendBitcoinAddress(ctx, inputType.Field0.Address, int64(amountSats))
+	case breez_sdk_spark.InputTypeSparkAddress:
+		return w.SendSparkAddress(ctx, inputType.Field0.Address, int64(amountSats))
+	case breez_sdk_spark.InputTypeSparkInvoice:
+		return w.SendSparkAddress(ctx, inputType.Field0.Invoice, int64(amountSats))
+	case breez_sdk_spark.InputTypeBip21:
+		if amountSats == 0 && inputType.Field0.AmountSat != nil {
+			amountSats = *inputType.Field0.AmountSat
 		}
-
-		prepareResp, err := w.sdk.PrepareLnurlPay(prepareReq)
-		if isSdkError(err) {
-			return nil, fmt.Errorf("failed to prepare lnurl pay: %w", err)
+		// Prefer Lightning when the URI carries an invoice as well as an address
+		for _, method := range inputType.Field0.PaymentMethods {
+			if _, ok := method.(breez_sdk_spark.InputTypeBolt11Invoice); ok {
+				return w.payInput(ctx, method, amountSats, comment, limit)
+			}
 		}
-
-		// Send the LNURL payment
-		payReq := breez_sdk_spark.LnurlPayRequest{
-			PrepareResponse: prepareResp,
+		if len(inputType.Field0.PaymentMethods) > 0 {
+			return w.payInput(ctx, inputType.Field0.PaymentMethods[0], amountSats, comment, limit)
 		}
+		return nil, fmt.Errorf("BIP21 URI has no payment method")
+	case breez_sdk_spark.InputTypeBolt12Invoice, breez_sdk_spark.InputTypeBolt12Offer, breez_sdk_spark.InputTypeBolt12InvoiceRequest:
+		return nil, fmt.Errorf("BOLT12 payments are not supported")
+	case breez_sdk_spark.InputTypeSilentPaymentAddress:
+		return nil, fmt.Errorf("silent payment addresses are not supported")
+	case breez_sdk_spark.InputTypeLnurlAuth:
+		return nil, fmt.Errorf("LNURL-auth links are used to log in, not to pay")
+	case breez_sdk_spark.InputTypeLnurlWithdraw:
+		return nil, fmt.Errorf("LNURL-withdraw links send funds to the wallet and can't be paid")
+	case breez_sdk_spark.InputTypeUrl:
+		return nil, fmt.Errorf("URL %s is not a payment destination", inputType.Field0)
+	}
 
-		response, err := w.sdk.LnurlPay(payReq)
-		if isSdkError(err) {
-			return nil, fmt.Errorf("failed to send lnurl payment: %w", err)
-		}
+	return nil, fmt.Errorf("unsupported LNURL address type")
+}
 
-		return &PaymentResponse{
-			PaymentHash: response.Payment.Id,
-			AmountSats:  response.Payment.Amount.Int64(),
-			FeeSats:     response.Payment.Fees.Int64(),
-			Status:      paymentStatusString(response.Payment.Status),
-			CompletedAt: time.Unix(int64(response.Payment.Timestamp), 0),
-		}, nil
+// payLnurlRequest pays an LNURL-pay request
+func (w *Wallet) payLnurlRequest(ctx context.Context, payRequest breez_sdk_spark.LnurlPayRequestDetails, amountSats uint64, comment string, limit FeeLimit) (*PaymentResponse, error) {
+	// Fail before preparing with the limits rather than the service's error
+	if err := checkLnurlPayAmount(payRequest, amountSats); err != nil {
+		return nil, err
+	}
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("unsupported LNURL address type")
+	validateSuccessActionUrl := true
+	amount := big.NewInt(int64(amountSats))
+
+	prepareReq := breez_sdk_spark.PrepareLnurlPayRequest{
+		Amount:                   amount,
+		PayRequest:               payRequest,
+		Comment:                  &comment,
+		ValidateSuccessActionUrl: &validateSuccessActionUrl,
+	}
+
+	prepareResp, err := w.sdk.PrepareLnurlPay(prepareReq)
+	traceSDK("PrepareLnurlPay", prepareReq, prepareResp, err)
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to prepare lnurl pay: %w", err)
+	}
+	if err := limit.check(prepareResp.FeeSats, prepareResp.AmountSats); err != nil {
+		return nil, err
+	}
+
+	// Send the LNURL payment
+	payReq := breez_sdk_spark.LnurlPayRequest{
+		PrepareResponse: prepareResp,
+	}
+
+	response, err := w.sdk.LnurlPay(payReq)
+	traceSDK("LnurlPay", payReq, response, err)
+	w.invalidateBalance()
+	if w.failed(err) {
+		return nil, fmt.Errorf("failed to send lnurl payment: %w", err)
+	}
+
+	return &PaymentResponse{
+		PaymentHash: response.Payment.Id,
+		AmountSats:  response.Payment.Amount.Int64(),
+		FeeSats:     response.Payment.Fees.Int64(),
+		Status:      paymentStatusString(response.Payment.Status),
+		CompletedAt: time.Unix(int64(response.Payment.Timestamp), 0),
+	}, nil
 }
 
-// GetTokenBalances retrieves token balances
+// GetTokenBalances retrieves the balances of all tokens the SDK reports,
+// including zero balances; callers filter if they want to hide them
 func (w *Wallet) GetTokenBalances(ctx context.Context) ([]*TokenBalance, error) {
+	defer logCall("GetTokenBalances", time.Now())
+	if err := w.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	ensureSynced := false
-	info, err := w.sdk.GetInfo(breez_sdk_spark.GetInfoRequest{
+	request := breez_sdk_spark.GetInfoRequest{
 		EnsureSynced: &ensureSynced,
-	})
+	}
+	info, err := w.sdk.GetInfo(request)
+	traceSDK("GetInfo", request, info, err)
 
-	if isSdkError(err) {
+	if w.failed(err) {
 		return nil, fmt.Errorf("failed to get token balances: %w", err)
 	}
 
@@ -509,6 +895,9 @@ func (w *Wallet) GetTokenBalances(ctx context.Context) ([]*TokenBalance, error)
 			Decimals: int(tokenBalance.TokenMetadata.Decimals),
 		})
 	}
+	w.enrichTokenMetadata(ctx, balances)
+
+	w.markFrozenTokens(balances)
 
 	return balances, nil
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/breez/tiny-spark/wallet"
+)
+
+// balanceAlerts tracks which side of the alert thresholds the balance was on
+// so each crossing notifies once. A threshold below zero is disabled.
+type balanceAlerts struct {
+	above, below int64
+	previous     *int64
+}
+
+// check returns the alerts for a new balance. The first balance is compared
+// against the thresholds alone, so a balance already past one alerts at start.
+func (a *balanceAlerts) check(balance int64) []string {
+	wasAbove, wasBelow := false, false
+	if a.previous != nil {
+		wasAbove = a.above >= 0 && *a.previous > a.above
+		wasBelow = a.below >= 0 && *a.previous < a.below
+	}
+	a.previous = &balance
+
+	var alerts []string
+	if a.above >= 0 && balance > a.above && !wasAbove {
+		alerts = append(alerts, fmt.Sprintf("Balance is %d sats, above %d sats. Consider moving funds to cold storage.", balance, a.above))
+	}
+	if a.below >= 0 && balance < a.below && !wasBelow {
+		alerts = append(alerts, fmt.Sprintf("Balance is %d sats, below %d sats. Top up the wallet to keep paying.", balance, a.below))
+	}
+	return alerts
+}
+
+// watchBalance prints the balance whenever it changes until interrupted and
+// notifies when it crosses --alert-above or --alert-below
+func watchBalance(ctx context.Context, w *wallet.Wallet, args []string) {
+	fs := flag.NewFlagSet("watch-balance", flag.ExitOnError)
+	interval := fs.Int("interval", 30, "Seconds between balance checks")
+	alertAbove := fs.Int64("alert-above", -1, "Notify when the balance rises above this many sats")
+	alertBelow := fs.Int64("alert-below", -1, "Notify when the balance falls below this many sats")
+	parseArgs(fs, args)
+
+	if *interval <= 0 {
+		log.Fatalf("Invalid interval: %d", *interval)
+	}
+	if *alertAbove >= 0 && *alertBelow >= 0 && *alertBelow > *alertAbove {
+		log.Fatalf("--alert-below can't be higher than --alert-above")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	alerts := &balanceAlerts{above: *alertAbove, below: *alertBelow}
+	ticker := time.NewTicker(time.Duration(*interval) * time.Second)
+	defer ticker.Stop()
+
+	var last *int64
+	for {
+		balance, err := w.GetBalance(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Failed to get balance: %v\n", err)
+		} else {
+			sats := balance.LightningBalanceSats
+			if last == nil || *last != sats {
+				fmt.Printf("%s  %d sats\n", time.Now().Format("2006-01-02 15:04:05"), sats)
+				last = &sats
+			}
+			for _, alert := range alerts.check(sats) {
+				fmt.Printf("ALERT: %s\n", alert)
+				if err := notifyDesktop("tiny-spark balance alert", alert); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to send desktop notification: %v\n", err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyDesktop shows a desktop notification with notify-send, or osascript
+// on macOS
+func notifyDesktop(title, message string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	} else {
+		binary, err := exec.LookPath("notify-send")
+		if err != nil {
+			return fmt.Errorf("notify-send is not installed: %w", err)
+		}
+		cmd = exec.Command(binary, title, message)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return nil
+}